@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/iankencruz/threefive/internal/cli"
 	"github.com/iankencruz/threefive/internal/server"
 	"github.com/joho/godotenv"
 )
@@ -21,6 +22,14 @@ func main() {
 		log.Printf("No .env file found, proceeding with environment variables")
 	}
 
+	// Maintenance commands, e.g. `main media migrate-storage -to s3`
+	if len(os.Args) > 1 {
+		if err := cli.Run(ctx, os.Args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Create server instance
 	s := server.NewServer()
 
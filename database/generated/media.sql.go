@@ -7,6 +7,7 @@ package generated
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -20,6 +21,89 @@ type BatchCreateMediaRelationsParams struct {
 	SortOrder    pgtype.Int4
 }
 
+const addMediaTag = `-- name: AddMediaTag :exec
+INSERT INTO media_tags (media_id, tag_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING
+`
+
+type AddMediaTagParams struct {
+	MediaID pgtype.UUID
+	TagID   pgtype.UUID
+}
+
+func (q *Queries) AddMediaTag(ctx context.Context, arg AddMediaTagParams) error {
+	_, err := q.db.Exec(ctx, addMediaTag, arg.MediaID, arg.TagID)
+	return err
+}
+
+const clearMediaTags = `-- name: ClearMediaTags :exec
+DELETE FROM media_tags
+WHERE media_id = $1
+`
+
+func (q *Queries) ClearMediaTags(ctx context.Context, mediaID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearMediaTags, mediaID)
+	return err
+}
+
+const countDeletedMedia = `-- name: CountDeletedMedia :one
+SELECT COUNT(*) FROM media
+WHERE deleted_at IS NOT NULL
+`
+
+func (q *Queries) CountDeletedMedia(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countDeletedMedia)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFilteredMedia = `-- name: CountFilteredMedia :one
+SELECT COUNT(*) FROM media
+WHERE deleted_at IS NULL
+  AND ($1::uuid IS NULL OR collection_id IN (
+        WITH RECURSIVE descendants AS (
+            SELECT mc.id FROM media_collections mc WHERE mc.id = $1
+            UNION ALL
+            SELECT mc.id FROM media_collections mc
+            JOIN descendants d ON mc.parent_id = d.id
+        )
+        SELECT id FROM descendants
+      ))
+  AND ($2::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM media_tags mt
+        WHERE mt.media_id = media.id AND mt.tag_id = $2
+      ))
+  AND ($3::text IS NULL OR mime_type LIKE $3)
+  AND ($4::uuid IS NULL OR uploaded_by = $4)
+  AND ($5::timestamptz IS NULL OR created_at >= $5)
+  AND ($6::timestamptz IS NULL OR created_at < $6)
+`
+
+type CountFilteredMediaParams struct {
+	CollectionID    pgtype.UUID
+	TagID           pgtype.UUID
+	MimeTypePattern pgtype.Text
+	UploadedBy      pgtype.UUID
+	CreatedFrom     *time.Time
+	CreatedBefore   *time.Time
+}
+
+func (q *Queries) CountFilteredMedia(ctx context.Context, arg CountFilteredMediaParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countFilteredMedia,
+		arg.CollectionID,
+		arg.TagID,
+		arg.MimeTypePattern,
+		arg.UploadedBy,
+		arg.CreatedFrom,
+		arg.CreatedBefore,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const countMedia = `-- name: CountMedia :one
 SELECT COUNT(*) FROM media
 WHERE deleted_at IS NULL
@@ -65,6 +149,10 @@ INSERT INTO media (
     thumbnail_key,
     alt_text,
     uploaded_by,
+    processing_status,
+    copyright,
+    artist,
+    content_hash,
     created_at,
     updated_at
 ) VALUES (
@@ -85,10 +173,14 @@ INSERT INTO media (
     $15,
     $16,
     $17,
+    $18,
+    $19,
+    $20,
+    $21,
     NOW(),
     NOW()
 )
-RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
 `
 
 type CreateMediaParams struct {
@@ -109,6 +201,10 @@ type CreateMediaParams struct {
 	ThumbnailKey     pgtype.Text
 	AltText          pgtype.Text
 	UploadedBy       pgtype.UUID
+	ProcessingStatus string
+	Copyright        pgtype.Text
+	Artist           pgtype.Text
+	ContentHash      pgtype.Text
 }
 
 // sql/queries/media.sql
@@ -131,6 +227,10 @@ func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (Media
 		arg.ThumbnailKey,
 		arg.AltText,
 		arg.UploadedBy,
+		arg.ProcessingStatus,
+		arg.Copyright,
+		arg.Artist,
+		arg.ContentHash,
 	)
 	var i Media
 	err := row.Scan(
@@ -154,6 +254,24 @@ func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (Media
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
@@ -244,8 +362,137 @@ func (q *Queries) DeleteMediaRelation(ctx context.Context, arg DeleteMediaRelati
 	return err
 }
 
+const deleteMediaRelationsSharedWith = `-- name: DeleteMediaRelationsSharedWith :exec
+
+DELETE FROM media_relations mr
+WHERE mr.media_id = $1
+  AND EXISTS (
+    SELECT 1 FROM media_relations x
+    WHERE x.media_id = $2
+      AND x.entity_type = mr.entity_type
+      AND x.entity_id = mr.entity_id
+      AND x.relation_type = mr.relation_type
+  )
+`
+
+type DeleteMediaRelationsSharedWithParams struct {
+	OldMediaID pgtype.UUID
+	NewMediaID pgtype.UUID
+}
+
+// Relations the replacement already has would violate the unique constraint
+func (q *Queries) DeleteMediaRelationsSharedWith(ctx context.Context, arg DeleteMediaRelationsSharedWithParams) error {
+	_, err := q.db.Exec(ctx, deleteMediaRelationsSharedWith, arg.OldMediaID, arg.NewMediaID)
+	return err
+}
+
+const filterMedia = `-- name: FilterMedia :many
+
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE deleted_at IS NULL
+  AND ($1::uuid IS NULL OR collection_id IN (
+        WITH RECURSIVE descendants AS (
+            SELECT mc.id FROM media_collections mc WHERE mc.id = $1
+            UNION ALL
+            SELECT mc.id FROM media_collections mc
+            JOIN descendants d ON mc.parent_id = d.id
+        )
+        SELECT id FROM descendants
+      ))
+  AND ($2::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM media_tags mt
+        WHERE mt.media_id = media.id AND mt.tag_id = $2
+      ))
+  AND ($3::text IS NULL OR mime_type LIKE $3)
+  AND ($4::uuid IS NULL OR uploaded_by = $4)
+  AND ($5::timestamptz IS NULL OR created_at >= $5)
+  AND ($6::timestamptz IS NULL OR created_at < $6)
+ORDER BY created_at DESC
+LIMIT $7
+OFFSET $8
+`
+
+type FilterMediaParams struct {
+	CollectionID    pgtype.UUID
+	TagID           pgtype.UUID
+	MimeTypePattern pgtype.Text
+	UploadedBy      pgtype.UUID
+	CreatedFrom     *time.Time
+	CreatedBefore   *time.Time
+	LimitVal        int32
+	OffsetVal       int32
+}
+
+// Every filter is optional; a NULL argument matches all media. A collection
+// matches its nested collections too.
+func (q *Queries) FilterMedia(ctx context.Context, arg FilterMediaParams) ([]Media, error) {
+	rows, err := q.db.Query(ctx, filterMedia,
+		arg.CollectionID,
+		arg.TagID,
+		arg.MimeTypePattern,
+		arg.UploadedBy,
+		arg.CreatedFrom,
+		arg.CreatedBefore,
+		arg.LimitVal,
+		arg.OffsetVal,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.OriginalFilename,
+			&i.MimeType,
+			&i.FileSize,
+			&i.Width,
+			&i.Height,
+			&i.Duration,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.S3Region,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.AltText,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getDeletedMedia = `-- name: GetDeletedMedia :many
-SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at FROM media
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
 WHERE deleted_at IS NOT NULL
 ORDER BY deleted_at DESC
 LIMIT $2
@@ -287,6 +534,22 @@ func (q *Queries) GetDeletedMedia(ctx context.Context, arg GetDeletedMediaParams
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -299,7 +562,7 @@ func (q *Queries) GetDeletedMedia(ctx context.Context, arg GetDeletedMediaParams
 }
 
 const getFeaturedMediaForEntity = `-- name: GetFeaturedMediaForEntity :one
-SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at FROM media m
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
 JOIN media_relations mr ON m.id = mr.media_id
 WHERE mr.entity_type = $1
   AND mr.entity_id = $2
@@ -337,12 +600,30 @@ func (q *Queries) GetFeaturedMediaForEntity(ctx context.Context, arg GetFeatured
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
 
 const getGalleryMediaForEntity = `-- name: GetGalleryMediaForEntity :many
-SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at FROM media m
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
 JOIN media_relations mr ON m.id = mr.media_id
 WHERE mr.entity_type = $1
   AND mr.entity_id = $2
@@ -386,6 +667,22 @@ func (q *Queries) GetGalleryMediaForEntity(ctx context.Context, arg GetGalleryMe
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -397,8 +694,64 @@ func (q *Queries) GetGalleryMediaForEntity(ctx context.Context, arg GetGalleryMe
 	return items, nil
 }
 
+const getMediaByContentHash = `-- name: GetMediaByContentHash :one
+
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE content_hash = $1
+  AND deleted_at IS NULL
+ORDER BY created_at ASC
+LIMIT 1
+`
+
+// The oldest live copy of a file, offered instead of storing it again
+func (q *Queries) GetMediaByContentHash(ctx context.Context, contentHash pgtype.Text) (Media, error) {
+	row := q.db.QueryRow(ctx, getMediaByContentHash, contentHash)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
 const getMediaByFilename = `-- name: GetMediaByFilename :one
-SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at FROM media
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
 WHERE filename = $1
   AND deleted_at IS NULL
 LIMIT 1
@@ -428,12 +781,30 @@ func (q *Queries) GetMediaByFilename(ctx context.Context, filename string) (Medi
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
 
 const getMediaByID = `-- name: GetMediaByID :one
-SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at FROM media
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
 WHERE id = $1
   AND deleted_at IS NULL
 LIMIT 1
@@ -463,12 +834,146 @@ func (q *Queries) GetMediaByID(ctx context.Context, id pgtype.UUID) (Media, erro
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
+const getMediaByIDIncludingDeleted = `-- name: GetMediaByIDIncludingDeleted :one
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE id = $1
+LIMIT 1
+`
+
+func (q *Queries) GetMediaByIDIncludingDeleted(ctx context.Context, id pgtype.UUID) (Media, error) {
+	row := q.db.QueryRow(ctx, getMediaByIDIncludingDeleted, id)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
 
+const getMediaByIDs = `-- name: GetMediaByIDs :many
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE id = ANY($1::uuid[])
+  AND deleted_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetMediaByIDs(ctx context.Context, ids []pgtype.UUID) ([]Media, error) {
+	rows, err := q.db.Query(ctx, getMediaByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.OriginalFilename,
+			&i.MimeType,
+			&i.FileSize,
+			&i.Width,
+			&i.Height,
+			&i.Duration,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.S3Region,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.AltText,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMediaForEntity = `-- name: GetMediaForEntity :many
-SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at FROM media m
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
 JOIN media_relations mr ON m.id = mr.media_id
 WHERE mr.entity_type = $1
   AND mr.entity_id = $2
@@ -511,6 +1016,22 @@ func (q *Queries) GetMediaForEntity(ctx context.Context, arg GetMediaForEntityPa
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -522,6 +1043,58 @@ func (q *Queries) GetMediaForEntity(ctx context.Context, arg GetMediaForEntityPa
 	return items, nil
 }
 
+const getMediaForUpdate = `-- name: GetMediaForUpdate :one
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetMediaForUpdate(ctx context.Context, id pgtype.UUID) (Media, error) {
+	row := q.db.QueryRow(ctx, getMediaForUpdate, id)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
 const getMediaStats = `-- name: GetMediaStats :one
 
 SELECT
@@ -556,6 +1129,39 @@ func (q *Queries) GetMediaStats(ctx context.Context) (GetMediaStatsRow, error) {
 	return i, err
 }
 
+const getMediaTags = `-- name: GetMediaTags :many
+SELECT t.id, t.name, t.slug, t.created_at, t.updated_at FROM tags t
+JOIN media_tags mt ON t.id = mt.tag_id
+WHERE mt.media_id = $1
+ORDER BY t.name ASC
+`
+
+func (q *Queries) GetMediaTags(ctx context.Context, mediaID pgtype.UUID) ([]Tag, error) {
+	rows, err := q.db.Query(ctx, getMediaTags, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getMediaUsageByEntity = `-- name: GetMediaUsageByEntity :many
 SELECT
     mr.entity_type,
@@ -596,14 +1202,20 @@ func (q *Queries) GetMediaUsageByEntity(ctx context.Context, limitVal int32) ([]
 }
 
 const getOrphanedMedia = `-- name: GetOrphanedMedia :many
-SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at FROM media m
-LEFT JOIN media_relations mr ON m.id = mr.media_id
-WHERE mr.id IS NULL
-  AND m.deleted_at IS NULL
+
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
+WHERE m.deleted_at IS NULL
   AND m.created_at < NOW() - INTERVAL '1 day'
+  AND NOT EXISTS (SELECT 1 FROM media_relations mr WHERE mr.media_id = m.id)
+  AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.featured_image_id = m.id OR p.body ~* ('media:' || m.id::TEXT))
+  AND NOT EXISTS (SELECT 1 FROM blogs b WHERE b.featured_image_id = m.id OR b.body ~* ('media:' || m.id::TEXT))
+  AND NOT EXISTS (SELECT 1 FROM pages pg WHERE pg.hero_media_id = m.id OR pg.content_image_id = m.id)
+  AND NOT EXISTS (SELECT 1 FROM seo WHERE seo.og_image_id = m.id)
 ORDER BY m.created_at DESC
 `
 
+// Media no content refers to, whether through media_relations, an image column
+// or a media:<uuid> embedded in a markdown body
 func (q *Queries) GetOrphanedMedia(ctx context.Context) ([]Media, error) {
 	rows, err := q.db.Query(ctx, getOrphanedMedia)
 	if err != nil {
@@ -634,6 +1246,22 @@ func (q *Queries) GetOrphanedMedia(ctx context.Context) ([]Media, error) {
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -656,7 +1284,7 @@ func (q *Queries) HardDeleteMedia(ctx context.Context, id pgtype.UUID) error {
 }
 
 const listMedia = `-- name: ListMedia :many
-SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at FROM media
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
 WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2
@@ -698,6 +1326,22 @@ func (q *Queries) ListMedia(ctx context.Context, arg ListMediaParams) ([]Media,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -710,7 +1354,7 @@ func (q *Queries) ListMedia(ctx context.Context, arg ListMediaParams) ([]Media,
 }
 
 const listMediaByType = `-- name: ListMediaByType :many
-SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at FROM media
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
 WHERE mime_type LIKE $1
   AND deleted_at IS NULL
 ORDER BY created_at DESC
@@ -754,6 +1398,22 @@ func (q *Queries) ListMediaByType(ctx context.Context, arg ListMediaByTypeParams
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -766,7 +1426,7 @@ func (q *Queries) ListMediaByType(ctx context.Context, arg ListMediaByTypeParams
 }
 
 const listMediaByUploader = `-- name: ListMediaByUploader :many
-SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at FROM media
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
 WHERE uploaded_by = $1
   AND deleted_at IS NULL
 ORDER BY created_at DESC
@@ -810,6 +1470,22 @@ func (q *Queries) ListMediaByUploader(ctx context.Context, arg ListMediaByUpload
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
 		); err != nil {
 			return nil, err
 		}
@@ -821,19 +1497,575 @@ func (q *Queries) ListMediaByUploader(ctx context.Context, arg ListMediaByUpload
 	return items, nil
 }
 
-const purgeOldDeletedMedia = `-- name: PurgeOldDeletedMedia :exec
-DELETE FROM media
-WHERE deleted_at IS NOT NULL
-  AND deleted_at < NOW() - INTERVAL '30 days'
+const listMediaDeletedBefore = `-- name: ListMediaDeletedBefore :many
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE deleted_at < $1
+ORDER BY deleted_at ASC
+LIMIT $2
 `
 
-func (q *Queries) PurgeOldDeletedMedia(ctx context.Context) error {
-	_, err := q.db.Exec(ctx, purgeOldDeletedMedia)
-	return err
+type ListMediaDeletedBeforeParams struct {
+	DeletedBefore *time.Time
+	LimitVal      int32
 }
 
-const reorderGalleryMedia = `-- name: ReorderGalleryMedia :exec
-UPDATE media_relations
+func (q *Queries) ListMediaDeletedBefore(ctx context.Context, arg ListMediaDeletedBeforeParams) ([]Media, error) {
+	rows, err := q.db.Query(ctx, listMediaDeletedBefore, arg.DeletedBefore, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.OriginalFilename,
+			&i.MimeType,
+			&i.FileSize,
+			&i.Width,
+			&i.Height,
+			&i.Duration,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.S3Region,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.AltText,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMediaForStorageMigration = `-- name: ListMediaForStorageMigration :many
+
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListMediaForStorageMigrationParams struct {
+	AfterID  pgtype.UUID
+	LimitVal int32
+}
+
+// Includes soft-deleted media, whose files are kept until it is purged
+func (q *Queries) ListMediaForStorageMigration(ctx context.Context, arg ListMediaForStorageMigrationParams) ([]Media, error) {
+	rows, err := q.db.Query(ctx, listMediaForStorageMigration, arg.AfterID, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.OriginalFilename,
+			&i.MimeType,
+			&i.FileSize,
+			&i.Width,
+			&i.Height,
+			&i.Duration,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.S3Region,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.AltText,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMediaReferences = `-- name: ListMediaReferences :many
+
+SELECT
+    r.entity_type,
+    r.entity_id,
+    r.field,
+    COALESCE(p.title, b.title, pg.title, '')::TEXT AS title,
+    COALESCE(p.slug, b.slug, pg.slug, '')::TEXT AS slug
+FROM (
+    SELECT mr.entity_type, mr.entity_id, mr.relation_type AS field
+    FROM media_relations mr WHERE mr.media_id = $1
+    UNION
+    SELECT 'project', id, 'featured' FROM projects WHERE featured_image_id = $1
+    UNION
+    SELECT 'blog', id, 'featured' FROM blogs WHERE featured_image_id = $1
+    UNION
+    SELECT 'page', id, 'hero' FROM pages WHERE hero_media_id = $1
+    UNION
+    SELECT 'page', id, 'content_image' FROM pages WHERE content_image_id = $1
+    UNION
+    SELECT seo.entity_type, seo.entity_id, 'og_image' FROM seo WHERE og_image_id = $1
+    UNION
+    SELECT 'project', id, 'body' FROM projects WHERE body ~* ('media:' || $1::uuid::TEXT)
+    UNION
+    SELECT 'blog', id, 'body' FROM blogs WHERE body ~* ('media:' || $1::uuid::TEXT)
+) r
+LEFT JOIN projects p ON r.entity_type = 'project' AND p.id = r.entity_id
+LEFT JOIN blogs b ON r.entity_type = 'blog' AND b.id = r.entity_id
+LEFT JOIN pages pg ON r.entity_type = 'page' AND pg.id = r.entity_id
+WHERE COALESCE(p.deleted_at, b.deleted_at, pg.deleted_at) IS NULL
+ORDER BY r.entity_type, title, r.field
+`
+
+type ListMediaReferencesRow struct {
+	EntityType string
+	EntityID   pgtype.UUID
+	Field      string
+	Title      string
+	Slug       string
+}
+
+// Every live project, blog or page that uses the media, and in which field.
+// Markdown bodies embed media as media:<uuid>.
+func (q *Queries) ListMediaReferences(ctx context.Context, mediaID pgtype.UUID) ([]ListMediaReferencesRow, error) {
+	rows, err := q.db.Query(ctx, listMediaReferences, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMediaReferencesRow
+	for rows.Next() {
+		var i ListMediaReferencesRow
+		if err := rows.Scan(
+			&i.EntityType,
+			&i.EntityID,
+			&i.Field,
+			&i.Title,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMediaStorageKeys = `-- name: ListMediaStorageKeys :many
+
+SELECT
+    id,
+    storage_type,
+    s3_bucket,
+    original_key,
+    large_key,
+    medium_key,
+    thumbnail_key,
+    web_key,
+    hls_key,
+    hero_key,
+    card_key,
+    og_key
+FROM media
+ORDER BY id
+`
+
+type ListMediaStorageKeysRow struct {
+	ID           pgtype.UUID
+	StorageType  string
+	S3Bucket     pgtype.Text
+	OriginalKey  pgtype.Text
+	LargeKey     pgtype.Text
+	MediumKey    pgtype.Text
+	ThumbnailKey pgtype.Text
+	WebKey       pgtype.Text
+	HlsKey       pgtype.Text
+	HeroKey      pgtype.Text
+	CardKey      pgtype.Text
+	OgKey        pgtype.Text
+}
+
+// Every row, including soft-deleted media whose files are kept until purged
+func (q *Queries) ListMediaStorageKeys(ctx context.Context) ([]ListMediaStorageKeysRow, error) {
+	rows, err := q.db.Query(ctx, listMediaStorageKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMediaStorageKeysRow
+	for rows.Next() {
+		var i ListMediaStorageKeysRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMediaTagsInUse = `-- name: ListMediaTagsInUse :many
+
+SELECT DISTINCT t.* FROM tags t
+JOIN media_tags mt ON t.id = mt.tag_id
+JOIN media m ON m.id = mt.media_id
+WHERE m.deleted_at IS NULL
+ORDER BY t.name ASC
+`
+
+// Tags on live media, offered as library filters
+func (q *Queries) ListMediaTagsInUse(ctx context.Context) ([]Tag, error) {
+	rows, err := q.db.Query(ctx, listMediaTagsInUse)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Tag
+	for rows.Next() {
+		var i Tag
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Slug,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMediaUploaders = `-- name: ListMediaUploaders :many
+SELECT DISTINCT u.id, u.first_name, u.last_name
+FROM users u
+JOIN media m ON m.uploaded_by = u.id
+WHERE m.deleted_at IS NULL
+ORDER BY u.first_name, u.last_name
+`
+
+type ListMediaUploadersRow struct {
+	ID        pgtype.UUID
+	FirstName string
+	LastName  string
+}
+
+func (q *Queries) ListMediaUploaders(ctx context.Context) ([]ListMediaUploadersRow, error) {
+	rows, err := q.db.Query(ctx, listMediaUploaders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMediaUploadersRow
+	for rows.Next() {
+		var i ListMediaUploadersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FirstName,
+			&i.LastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMediaWithoutContentHash = `-- name: ListMediaWithoutContentHash :many
+
+SELECT id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title FROM media
+WHERE content_hash IS NULL
+  AND original_key IS NOT NULL
+  AND id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListMediaWithoutContentHashParams struct {
+	AfterID  pgtype.UUID
+	LimitVal int32
+}
+
+// Includes soft-deleted media so it can be matched once restored
+func (q *Queries) ListMediaWithoutContentHash(ctx context.Context, arg ListMediaWithoutContentHashParams) ([]Media, error) {
+	rows, err := q.db.Query(ctx, listMediaWithoutContentHash, arg.AfterID, arg.LimitVal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.OriginalFilename,
+			&i.MimeType,
+			&i.FileSize,
+			&i.Width,
+			&i.Height,
+			&i.Duration,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.S3Region,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.AltText,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPublishedImageReferences = `-- name: ListPublishedImageReferences :many
+
+SELECT
+    r.media_id,
+    r.entity_type,
+    r.entity_id,
+    r.field,
+    r.title,
+    r.slug
+FROM (
+    SELECT p.featured_image_id AS media_id, 'project'::TEXT AS entity_type, p.id AS entity_id, 'featured'::TEXT AS field, p.title, p.slug
+    FROM projects p
+    WHERE p.status = 'published' AND p.deleted_at IS NULL
+    UNION
+    SELECT mr.media_id, 'project', p.id, 'gallery', p.title, p.slug
+    FROM media_relations mr
+    JOIN projects p ON mr.entity_type = 'project' AND p.id = mr.entity_id
+    WHERE mr.relation_type = 'gallery' AND p.status = 'published' AND p.deleted_at IS NULL
+    UNION
+    SELECT bm.id, 'project', p.id, 'body', p.title, p.slug
+    FROM projects p
+    JOIN media bm ON p.body ~* ('media:' || bm.id::TEXT)
+    WHERE p.status = 'published' AND p.deleted_at IS NULL
+    UNION
+    SELECT bm.id, 'blog', b.id, 'body', b.title, b.slug
+    FROM blogs b
+    JOIN media bm ON b.body ~* ('media:' || bm.id::TEXT)
+    WHERE b.status = 'published' AND b.deleted_at IS NULL
+    UNION
+    SELECT pg.hero_media_id, 'page', pg.id, 'hero', pg.title, pg.slug
+    FROM pages pg WHERE pg.deleted_at IS NULL
+    UNION
+    SELECT pg.content_image_id, 'page', pg.id, 'content_image', pg.title, pg.slug
+    FROM pages pg WHERE pg.deleted_at IS NULL
+    UNION
+    SELECT seo.og_image_id, seo.entity_type, seo.entity_id, 'og_image',
+        COALESCE(p.title, b.title, pg.title)::TEXT, COALESCE(p.slug, b.slug, pg.slug)::TEXT
+    FROM seo
+    LEFT JOIN projects p ON seo.entity_type = 'project' AND p.id = seo.entity_id
+        AND p.status = 'published' AND p.deleted_at IS NULL
+    LEFT JOIN blogs b ON seo.entity_type = 'blog' AND b.id = seo.entity_id
+        AND b.status = 'published' AND b.deleted_at IS NULL
+    LEFT JOIN pages pg ON seo.entity_type = 'page' AND pg.id = seo.entity_id
+        AND pg.deleted_at IS NULL
+    WHERE COALESCE(p.id, b.id, pg.id) IS NOT NULL
+) r
+JOIN media m ON m.id = r.media_id
+WHERE m.deleted_at IS NULL
+  AND m.mime_type LIKE 'image/%'
+  AND ($1::uuid IS NULL OR m.id = $1::uuid)
+ORDER BY r.entity_type, r.title, r.field
+`
+
+type ListPublishedImageReferencesRow struct {
+	MediaID    pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+	Field      string
+	Title      string
+	Slug       string
+}
+
+// Images shown by published projects, blog posts and pages, including those
+// embedded in markdown bodies, and as their social share images, optionally
+// for one media item
+func (q *Queries) ListPublishedImageReferences(ctx context.Context, mediaID pgtype.UUID) ([]ListPublishedImageReferencesRow, error) {
+	rows, err := q.db.Query(ctx, listPublishedImageReferences, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPublishedImageReferencesRow
+	for rows.Next() {
+		var i ListPublishedImageReferencesRow
+		if err := rows.Scan(
+			&i.MediaID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Field,
+			&i.Title,
+			&i.Slug,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveMediaToCollection = `-- name: MoveMediaToCollection :many
+UPDATE media
+SET
+    collection_id = $1,
+    updated_at = NOW()
+WHERE id = ANY($2::uuid[])
+  AND deleted_at IS NULL
+RETURNING id
+`
+
+type MoveMediaToCollectionParams struct {
+	CollectionID pgtype.UUID
+	Ids          []pgtype.UUID
+}
+
+func (q *Queries) MoveMediaToCollection(ctx context.Context, arg MoveMediaToCollectionParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, moveMediaToCollection, arg.CollectionID, arg.Ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removeMediaTagBatch = `-- name: RemoveMediaTagBatch :exec
+DELETE FROM media_tags
+WHERE tag_id = $1
+  AND media_id = ANY($2::uuid[])
+`
+
+type RemoveMediaTagBatchParams struct {
+	TagID pgtype.UUID
+	Ids   []pgtype.UUID
+}
+
+func (q *Queries) RemoveMediaTagBatch(ctx context.Context, arg RemoveMediaTagBatchParams) error {
+	_, err := q.db.Exec(ctx, removeMediaTagBatch, arg.TagID, arg.Ids)
+	return err
+}
+
+const reorderGalleryMedia = `-- name: ReorderGalleryMedia :exec
+UPDATE media_relations
 SET sort_order = $1
 WHERE media_id = $2
   AND entity_type = $3
@@ -841,47 +2073,366 @@ WHERE media_id = $2
   AND relation_type = 'gallery'
 `
 
-type ReorderGalleryMediaParams struct {
-	SortOrder  pgtype.Int4
-	MediaID    pgtype.UUID
-	EntityType string
-	EntityID   pgtype.UUID
+type ReorderGalleryMediaParams struct {
+	SortOrder  pgtype.Int4
+	MediaID    pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+}
+
+func (q *Queries) ReorderGalleryMedia(ctx context.Context, arg ReorderGalleryMediaParams) error {
+	_, err := q.db.Exec(ctx, reorderGalleryMedia,
+		arg.SortOrder,
+		arg.MediaID,
+		arg.EntityType,
+		arg.EntityID,
+	)
+	return err
+}
+
+const replaceBlogBodyMedia = `-- name: ReplaceBlogBodyMedia :exec
+
+UPDATE blogs
+SET body = regexp_replace(body, 'media:' || $1::uuid::TEXT, 'media:' || $2::uuid::TEXT, 'gi'), updated_at = NOW()
+WHERE body ~* ('media:' || $1::uuid::TEXT)
+`
+
+type ReplaceBlogBodyMediaParams struct {
+	OldMediaID pgtype.UUID
+	NewMediaID pgtype.UUID
+}
+
+// Rewrites media:<uuid> embedded in markdown bodies
+func (q *Queries) ReplaceBlogBodyMedia(ctx context.Context, arg ReplaceBlogBodyMediaParams) error {
+	_, err := q.db.Exec(ctx, replaceBlogBodyMedia, arg.OldMediaID, arg.NewMediaID)
+	return err
+}
+
+const replaceBlogFeaturedImage = `-- name: ReplaceBlogFeaturedImage :exec
+UPDATE blogs
+SET featured_image_id = $1, updated_at = NOW()
+WHERE featured_image_id = $2
+`
+
+type ReplaceBlogFeaturedImageParams struct {
+	NewMediaID pgtype.UUID
+	OldMediaID pgtype.UUID
+}
+
+func (q *Queries) ReplaceBlogFeaturedImage(ctx context.Context, arg ReplaceBlogFeaturedImageParams) error {
+	_, err := q.db.Exec(ctx, replaceBlogFeaturedImage, arg.NewMediaID, arg.OldMediaID)
+	return err
+}
+
+const replaceMediaInRelations = `-- name: ReplaceMediaInRelations :exec
+UPDATE media_relations
+SET media_id = $1
+WHERE media_id = $2
+`
+
+type ReplaceMediaInRelationsParams struct {
+	NewMediaID pgtype.UUID
+	OldMediaID pgtype.UUID
+}
+
+func (q *Queries) ReplaceMediaInRelations(ctx context.Context, arg ReplaceMediaInRelationsParams) error {
+	_, err := q.db.Exec(ctx, replaceMediaInRelations, arg.NewMediaID, arg.OldMediaID)
+	return err
+}
+
+const replacePageContentImage = `-- name: ReplacePageContentImage :exec
+UPDATE pages
+SET content_image_id = $1, updated_at = NOW()
+WHERE content_image_id = $2
+`
+
+type ReplacePageContentImageParams struct {
+	NewMediaID pgtype.UUID
+	OldMediaID pgtype.UUID
+}
+
+func (q *Queries) ReplacePageContentImage(ctx context.Context, arg ReplacePageContentImageParams) error {
+	_, err := q.db.Exec(ctx, replacePageContentImage, arg.NewMediaID, arg.OldMediaID)
+	return err
+}
+
+const replacePageHeroMedia = `-- name: ReplacePageHeroMedia :exec
+UPDATE pages
+SET hero_media_id = $1, updated_at = NOW()
+WHERE hero_media_id = $2
+`
+
+type ReplacePageHeroMediaParams struct {
+	NewMediaID pgtype.UUID
+	OldMediaID pgtype.UUID
+}
+
+func (q *Queries) ReplacePageHeroMedia(ctx context.Context, arg ReplacePageHeroMediaParams) error {
+	_, err := q.db.Exec(ctx, replacePageHeroMedia, arg.NewMediaID, arg.OldMediaID)
+	return err
+}
+
+const replaceProjectBodyMedia = `-- name: ReplaceProjectBodyMedia :exec
+
+UPDATE projects
+SET body = regexp_replace(body, 'media:' || $1::uuid::TEXT, 'media:' || $2::uuid::TEXT, 'gi'), updated_at = NOW()
+WHERE body ~* ('media:' || $1::uuid::TEXT)
+`
+
+type ReplaceProjectBodyMediaParams struct {
+	OldMediaID pgtype.UUID
+	NewMediaID pgtype.UUID
+}
+
+// Rewrites media:<uuid> embedded in markdown bodies
+func (q *Queries) ReplaceProjectBodyMedia(ctx context.Context, arg ReplaceProjectBodyMediaParams) error {
+	_, err := q.db.Exec(ctx, replaceProjectBodyMedia, arg.OldMediaID, arg.NewMediaID)
+	return err
+}
+
+const replaceProjectFeaturedImage = `-- name: ReplaceProjectFeaturedImage :exec
+UPDATE projects
+SET featured_image_id = $1, updated_at = NOW()
+WHERE featured_image_id = $2
+`
+
+type ReplaceProjectFeaturedImageParams struct {
+	NewMediaID pgtype.UUID
+	OldMediaID pgtype.UUID
+}
+
+func (q *Queries) ReplaceProjectFeaturedImage(ctx context.Context, arg ReplaceProjectFeaturedImageParams) error {
+	_, err := q.db.Exec(ctx, replaceProjectFeaturedImage, arg.NewMediaID, arg.OldMediaID)
+	return err
+}
+
+const replaceSeoOgImage = `-- name: ReplaceSeoOgImage :exec
+UPDATE seo
+SET og_image_id = $1, updated_at = NOW()
+WHERE og_image_id = $2
+`
+
+type ReplaceSeoOgImageParams struct {
+	NewMediaID pgtype.UUID
+	OldMediaID pgtype.UUID
+}
+
+func (q *Queries) ReplaceSeoOgImage(ctx context.Context, arg ReplaceSeoOgImageParams) error {
+	_, err := q.db.Exec(ctx, replaceSeoOgImage, arg.NewMediaID, arg.OldMediaID)
+	return err
+}
+
+const restoreMedia = `-- name: RestoreMedia :exec
+UPDATE media
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) RestoreMedia(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, restoreMedia, id)
+	return err
+}
+
+const restoreMediaBatch = `-- name: RestoreMediaBatch :many
+UPDATE media
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = ANY($1::uuid[])
+  AND deleted_at IS NOT NULL
+RETURNING id
+`
+
+func (q *Queries) RestoreMediaBatch(ctx context.Context, ids []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, restoreMediaBatch, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMediaCollection = `-- name: SetMediaCollection :one
+UPDATE media
+SET
+    collection_id = $1,
+    updated_at = NOW()
+WHERE id = $2
+  AND deleted_at IS NULL
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
+`
+
+type SetMediaCollectionParams struct {
+	CollectionID pgtype.UUID
+	ID           pgtype.UUID
+}
+
+func (q *Queries) SetMediaCollection(ctx context.Context, arg SetMediaCollectionParams) (Media, error) {
+	row := q.db.QueryRow(ctx, setMediaCollection, arg.CollectionID, arg.ID)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
+const setMediaFocalPoint = `-- name: SetMediaFocalPoint :one
+
+UPDATE media
+SET
+    focal_x = $1,
+    focal_y = $2,
+    processing_status = 'pending',
+    processing_error = NULL,
+    updated_at = NOW()
+WHERE id = $3 AND deleted_at IS NULL
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
+`
+
+type SetMediaFocalPointParams struct {
+	FocalX pgtype.Float4
+	FocalY pgtype.Float4
+	ID     pgtype.UUID
 }
 
-func (q *Queries) ReorderGalleryMedia(ctx context.Context, arg ReorderGalleryMediaParams) error {
-	_, err := q.db.Exec(ctx, reorderGalleryMedia,
-		arg.SortOrder,
-		arg.MediaID,
-		arg.EntityType,
-		arg.EntityID,
+// The crops are regenerated around the new point by a background job
+func (q *Queries) SetMediaFocalPoint(ctx context.Context, arg SetMediaFocalPointParams) (Media, error) {
+	row := q.db.QueryRow(ctx, setMediaFocalPoint, arg.FocalX, arg.FocalY, arg.ID)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
-	return err
+	return i, err
 }
 
-const restoreMedia = `-- name: RestoreMedia :exec
+const softDeleteMedia = `-- name: SoftDeleteMedia :exec
 UPDATE media
 SET
-    deleted_at = NULL,
+    deleted_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
 `
 
-func (q *Queries) RestoreMedia(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, restoreMedia, id)
+func (q *Queries) SoftDeleteMedia(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, softDeleteMedia, id)
 	return err
 }
 
-const softDeleteMedia = `-- name: SoftDeleteMedia :exec
+const softDeleteMediaBatch = `-- name: SoftDeleteMediaBatch :many
 UPDATE media
 SET
     deleted_at = NOW(),
     updated_at = NOW()
-WHERE id = $1
+WHERE id = ANY($1::uuid[])
+  AND deleted_at IS NULL
+RETURNING id
 `
 
-func (q *Queries) SoftDeleteMedia(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, softDeleteMedia, id)
-	return err
+func (q *Queries) SoftDeleteMediaBatch(ctx context.Context, ids []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, softDeleteMediaBatch, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const updateMedia = `-- name: UpdateMedia :one
@@ -891,7 +2442,7 @@ SET
     updated_at = NOW()
 WHERE id = $2
   AND deleted_at IS NULL
-RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
 `
 
 type UpdateMediaParams struct {
@@ -923,6 +2474,24 @@ func (q *Queries) UpdateMedia(ctx context.Context, arg UpdateMediaParams) (Media
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
@@ -934,7 +2503,7 @@ SET
     updated_at = NOW()
 WHERE id = $2
   AND deleted_at IS NULL
-RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
 `
 
 type UpdateMediaAltTextParams struct {
@@ -966,6 +2535,393 @@ func (q *Queries) UpdateMediaAltText(ctx context.Context, arg UpdateMediaAltText
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
+const updateMediaContentHash = `-- name: UpdateMediaContentHash :exec
+UPDATE media
+SET content_hash = $1
+WHERE id = $2
+`
+
+type UpdateMediaContentHashParams struct {
+	ContentHash pgtype.Text
+	ID          pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaContentHash(ctx context.Context, arg UpdateMediaContentHashParams) error {
+	_, err := q.db.Exec(ctx, updateMediaContentHash, arg.ContentHash, arg.ID)
+	return err
+}
+
+const updateMediaCrops = `-- name: UpdateMediaCrops :one
+UPDATE media
+SET
+    hero_key = $1,
+    card_key = $2,
+    og_key = $3,
+    updated_at = NOW()
+WHERE id = $4
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
+`
+
+type UpdateMediaCropsParams struct {
+	HeroKey pgtype.Text
+	CardKey pgtype.Text
+	OgKey   pgtype.Text
+	ID      pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaCrops(ctx context.Context, arg UpdateMediaCropsParams) (Media, error) {
+	row := q.db.QueryRow(ctx, updateMediaCrops,
+		arg.HeroKey,
+		arg.CardKey,
+		arg.OgKey,
+		arg.ID,
+	)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
+const updateMediaDocumentMetadata = `-- name: UpdateMediaDocumentMetadata :exec
+UPDATE media
+SET
+    page_count = $1,
+    document_title = $2,
+    updated_at = NOW()
+WHERE id = $3
+`
+
+type UpdateMediaDocumentMetadataParams struct {
+	PageCount     pgtype.Int4
+	DocumentTitle pgtype.Text
+	ID            pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaDocumentMetadata(ctx context.Context, arg UpdateMediaDocumentMetadataParams) error {
+	_, err := q.db.Exec(ctx, updateMediaDocumentMetadata, arg.PageCount, arg.DocumentTitle, arg.ID)
+	return err
+}
+
+const updateMediaMetadata = `-- name: UpdateMediaMetadata :exec
+UPDATE media
+SET
+    width = COALESCE($1, width),
+    height = COALESCE($2, height),
+    duration = COALESCE($3, duration),
+    video_codec = COALESCE($4, video_codec),
+    bitrate = COALESCE($5, bitrate),
+    updated_at = NOW()
+WHERE id = $6
+`
+
+type UpdateMediaMetadataParams struct {
+	Width      pgtype.Int4
+	Height     pgtype.Int4
+	Duration   pgtype.Int4
+	VideoCodec pgtype.Text
+	Bitrate    pgtype.Int8
+	ID         pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaMetadata(ctx context.Context, arg UpdateMediaMetadataParams) error {
+	_, err := q.db.Exec(ctx, updateMediaMetadata,
+		arg.Width,
+		arg.Height,
+		arg.Duration,
+		arg.VideoCodec,
+		arg.Bitrate,
+		arg.ID,
+	)
+	return err
+}
+
+const updateMediaProcessingStatus = `-- name: UpdateMediaProcessingStatus :exec
+UPDATE media
+SET
+    processing_status = $1,
+    processing_error = $2,
+    updated_at = NOW()
+WHERE id = $3
+`
+
+type UpdateMediaProcessingStatusParams struct {
+	ProcessingStatus string
+	ProcessingError  pgtype.Text
+	ID               pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaProcessingStatus(ctx context.Context, arg UpdateMediaProcessingStatusParams) error {
+	_, err := q.db.Exec(ctx, updateMediaProcessingStatus, arg.ProcessingStatus, arg.ProcessingError, arg.ID)
+	return err
+}
+
+const updateMediaRenditions = `-- name: UpdateMediaRenditions :one
+UPDATE media
+SET
+    width = COALESCE($1, width),
+    height = COALESCE($2, height),
+    large_key = COALESCE($3, large_key),
+    medium_key = COALESCE($4, medium_key),
+    thumbnail_key = COALESCE($5, thumbnail_key),
+    web_key = COALESCE($6, web_key),
+    hls_key = COALESCE($7, hls_key),
+    hero_key = COALESCE($8, hero_key),
+    card_key = COALESCE($9, card_key),
+    og_key = COALESCE($10, og_key),
+    processing_status = 'ready',
+    processing_error = NULL,
+    updated_at = NOW()
+WHERE id = $11
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
+`
+
+type UpdateMediaRenditionsParams struct {
+	Width        pgtype.Int4
+	Height       pgtype.Int4
+	LargeKey     pgtype.Text
+	MediumKey    pgtype.Text
+	ThumbnailKey pgtype.Text
+	WebKey       pgtype.Text
+	HlsKey       pgtype.Text
+	HeroKey      pgtype.Text
+	CardKey      pgtype.Text
+	OgKey        pgtype.Text
+	ID           pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaRenditions(ctx context.Context, arg UpdateMediaRenditionsParams) (Media, error) {
+	row := q.db.QueryRow(ctx, updateMediaRenditions,
+		arg.Width,
+		arg.Height,
+		arg.LargeKey,
+		arg.MediumKey,
+		arg.ThumbnailKey,
+		arg.WebKey,
+		arg.HlsKey,
+		arg.HeroKey,
+		arg.CardKey,
+		arg.OgKey,
+		arg.ID,
+	)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
+	)
+	return i, err
+}
+
+const updateMediaStorageLocation = `-- name: UpdateMediaStorageLocation :exec
+UPDATE media
+SET
+    storage_type = $1,
+    s3_bucket = $2,
+    s3_region = $3,
+    updated_at = NOW()
+WHERE id = $4
+`
+
+type UpdateMediaStorageLocationParams struct {
+	StorageType string
+	S3Bucket    pgtype.Text
+	S3Region    pgtype.Text
+	ID          pgtype.UUID
+}
+
+func (q *Queries) UpdateMediaStorageLocation(ctx context.Context, arg UpdateMediaStorageLocationParams) error {
+	_, err := q.db.Exec(ctx, updateMediaStorageLocation,
+		arg.StorageType,
+		arg.S3Bucket,
+		arg.S3Region,
+		arg.ID,
+	)
+	return err
+}
+
+const updateMediaVisibility = `-- name: UpdateMediaVisibility :one
+
+UPDATE media
+SET
+    is_private = $1,
+    original_key = $2,
+    large_key = $3,
+    medium_key = $4,
+    thumbnail_key = $5,
+    web_key = $6,
+    hls_key = $7,
+    hero_key = $8,
+    card_key = $9,
+    og_key = $10,
+    updated_at = NOW()
+WHERE id = $11
+RETURNING id, filename, original_filename, mime_type, file_size, width, height, duration, storage_type, s3_bucket, s3_region, original_key, large_key, medium_key, thumbnail_key, alt_text, uploaded_by, created_at, updated_at, deleted_at, processing_status, processing_error, video_codec, bitrate, web_key, hls_key, copyright, artist, is_private, content_hash, collection_id, focal_x, focal_y, hero_key, card_key, og_key, page_count, document_title
+`
+
+type UpdateMediaVisibilityParams struct {
+	IsPrivate    bool
+	OriginalKey  pgtype.Text
+	LargeKey     pgtype.Text
+	MediumKey    pgtype.Text
+	ThumbnailKey pgtype.Text
+	WebKey       pgtype.Text
+	HlsKey       pgtype.Text
+	HeroKey      pgtype.Text
+	CardKey      pgtype.Text
+	OgKey        pgtype.Text
+	ID           pgtype.UUID
+}
+
+// Keys move with visibility: private objects live under a separate prefix
+func (q *Queries) UpdateMediaVisibility(ctx context.Context, arg UpdateMediaVisibilityParams) (Media, error) {
+	row := q.db.QueryRow(ctx, updateMediaVisibility,
+		arg.IsPrivate,
+		arg.OriginalKey,
+		arg.LargeKey,
+		arg.MediumKey,
+		arg.ThumbnailKey,
+		arg.WebKey,
+		arg.HlsKey,
+		arg.HeroKey,
+		arg.CardKey,
+		arg.OgKey,
+		arg.ID,
+	)
+	var i Media
+	err := row.Scan(
+		&i.ID,
+		&i.Filename,
+		&i.OriginalFilename,
+		&i.MimeType,
+		&i.FileSize,
+		&i.Width,
+		&i.Height,
+		&i.Duration,
+		&i.StorageType,
+		&i.S3Bucket,
+		&i.S3Region,
+		&i.OriginalKey,
+		&i.LargeKey,
+		&i.MediumKey,
+		&i.ThumbnailKey,
+		&i.AltText,
+		&i.UploadedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
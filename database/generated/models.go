@@ -25,6 +25,8 @@ type Blog struct {
 	UpdatedAt       time.Time
 	PublishedAt     *time.Time
 	DeletedAt       *time.Time
+	Body            pgtype.Text
+	WordCount       int32
 }
 
 type BlogTag struct {
@@ -77,6 +79,64 @@ type Media struct {
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	DeletedAt    *time.Time
+	// pending, processing, ready or failed
+	ProcessingStatus string
+	ProcessingError  pgtype.Text
+	// Video stream codec reported by ffprobe (e.g. h264)
+	VideoCodec pgtype.Text
+	// Overall bitrate in bits per second (videos)
+	Bitrate pgtype.Int8
+	// Key for the normalized H.264/AAC MP4 (videos)
+	WebKey pgtype.Text
+	// Key for the HLS master playlist (videos)
+	HlsKey pgtype.Text
+	// Copyright notice from the original EXIF (images)
+	Copyright pgtype.Text
+	// Artist/photographer from the original EXIF (images)
+	Artist pgtype.Text
+	// Served only through signed, expiring URLs when true
+	IsPrivate bool
+	// Hex SHA-256 of the stored original file
+	ContentHash pgtype.Text
+	// Collection (folder) holding the media; NULL at the library root
+	CollectionID pgtype.UUID
+	// Horizontal focal point as a fraction of the width (0 = left); NULL for the centre
+	FocalX pgtype.Float4
+	// Vertical focal point as a fraction of the height (0 = top); NULL for the centre
+	FocalY pgtype.Float4
+	// Key for the 16:9 hero crop around the focal point (images)
+	HeroKey pgtype.Text
+	// Key for the 1:1 card crop around the focal point (images)
+	CardKey pgtype.Text
+	// Key for the 1200x630 Open Graph crop around the focal point (images)
+	OgKey pgtype.Text
+	// Number of pages (PDFs)
+	PageCount pgtype.Int4
+	// Title from the document properties, if it has one (PDFs)
+	DocumentTitle pgtype.Text
+}
+
+type MediaCollection struct {
+	ID        pgtype.UUID
+	ParentID  pgtype.UUID
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Background media processing jobs, claimed with FOR UPDATE SKIP LOCKED
+type MediaJob struct {
+	ID          pgtype.UUID
+	MediaID     pgtype.UUID
+	JobType     string
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       time.Time
+	LockedAt    *time.Time
+	LastError   pgtype.Text
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 // Polymorphic relationship between media and entities (projects, blogs, pages)
@@ -92,6 +152,29 @@ type MediaRelation struct {
 	CreatedAt time.Time
 }
 
+type MediaTag struct {
+	MediaID   pgtype.UUID
+	TagID     pgtype.UUID
+	CreatedAt time.Time
+}
+
+// Pending presigned uploads; expired rows and their objects are purged
+type MediaUpload struct {
+	ID         pgtype.UUID
+	StorageKey string
+	// S3 multipart upload ID; NULL for a single presigned PUT
+	MultipartUploadID pgtype.Text
+	OriginalFilename  string
+	// Type declared by the browser, verified against the content on finalize
+	MimeType   string
+	FileSize   int64
+	UploadedBy pgtype.UUID
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	// When a request claimed the upload to finalize or cancel it; NULL while waiting for the browser
+	ClaimedAt pgtype.Timestamptz
+}
+
 type Page struct {
 	ID             pgtype.UUID
 	Title          string
@@ -135,6 +218,9 @@ type Project struct {
 	UpdatedAt       time.Time
 	PublishedAt     *time.Time
 	DeletedAt       *time.Time
+	Body            pgtype.Text
+	WordCount       int32
+	ReadingTime     pgtype.Int4
 }
 
 type ProjectTag struct {
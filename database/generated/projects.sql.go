@@ -11,6 +11,30 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addDocumentMedia = `-- name: AddDocumentMedia :exec
+INSERT INTO media_relations (media_id, entity_type, entity_id, relation_type, sort_order)
+VALUES ($1, $2, $3, 'document', $4)
+ON CONFLICT (media_id, entity_type, entity_id, relation_type) DO UPDATE
+    SET sort_order = EXCLUDED.sort_order
+`
+
+type AddDocumentMediaParams struct {
+	MediaID    pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+	SortOrder  pgtype.Int4
+}
+
+func (q *Queries) AddDocumentMedia(ctx context.Context, arg AddDocumentMediaParams) error {
+	_, err := q.db.Exec(ctx, addDocumentMedia,
+		arg.MediaID,
+		arg.EntityType,
+		arg.EntityID,
+		arg.SortOrder,
+	)
+	return err
+}
+
 const addGalleryMedia = `-- name: AddGalleryMedia :exec
 INSERT INTO media_relations (media_id, entity_type, entity_id, relation_type, sort_order)
 VALUES ($1, $2, $3, 'gallery', $4)
@@ -60,7 +84,7 @@ const checkProjectSlugExists = `-- name: CheckProjectSlugExists :one
 SELECT EXISTS(
     SELECT 1 FROM projects 
     WHERE slug = $1 
-      AND id != $2
+      AND id IS DISTINCT FROM $2
       AND deleted_at IS NULL
 )
 `
@@ -146,6 +170,9 @@ INSERT INTO projects (
     title,
     slug,
     description,
+    body,
+    word_count,
+    reading_time,
     project_date,
     status,
     client_name,
@@ -169,10 +196,13 @@ INSERT INTO projects (
     $10,
     $11,
     $12,
+    $13,
+    $14,
+    $15,
     NOW(),
     NOW()
 )
-RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at
+RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time
 `
 
 type CreateProjectParams struct {
@@ -180,6 +210,9 @@ type CreateProjectParams struct {
 	Title           string
 	Slug            string
 	Description     pgtype.Text
+	Body            pgtype.Text
+	WordCount       int32
+	ReadingTime     pgtype.Int4
 	ProjectDate     pgtype.Date
 	Status          pgtype.Text
 	ClientName      pgtype.Text
@@ -197,6 +230,9 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		arg.Title,
 		arg.Slug,
 		arg.Description,
+		arg.Body,
+		arg.WordCount,
+		arg.ReadingTime,
 		arg.ProjectDate,
 		arg.Status,
 		arg.ClientName,
@@ -224,10 +260,30 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.UpdatedAt,
 		&i.PublishedAt,
 		&i.DeletedAt,
+		&i.Body,
+		&i.WordCount,
+		&i.ReadingTime,
 	)
 	return i, err
 }
 
+const deleteDocumentMediaForEntity = `-- name: DeleteDocumentMediaForEntity :exec
+DELETE FROM media_relations
+WHERE entity_type   = $1
+  AND entity_id     = $2
+  AND relation_type = 'document'
+`
+
+type DeleteDocumentMediaForEntityParams struct {
+	EntityType string
+	EntityID   pgtype.UUID
+}
+
+func (q *Queries) DeleteDocumentMediaForEntity(ctx context.Context, arg DeleteDocumentMediaForEntityParams) error {
+	_, err := q.db.Exec(ctx, deleteDocumentMediaForEntity, arg.EntityType, arg.EntityID)
+	return err
+}
+
 const deleteGalleryMediaForEntity = `-- name: DeleteGalleryMediaForEntity :exec
 DELETE FROM media_relations
 WHERE entity_type   = $1
@@ -246,7 +302,7 @@ func (q *Queries) DeleteGalleryMediaForEntity(ctx context.Context, arg DeleteGal
 }
 
 const getProjectByID = `-- name: GetProjectByID :one
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE id = $1 AND deleted_at IS NULL
 LIMIT 1
 `
@@ -271,12 +327,15 @@ func (q *Queries) GetProjectByID(ctx context.Context, id pgtype.UUID) (Project,
 		&i.UpdatedAt,
 		&i.PublishedAt,
 		&i.DeletedAt,
+		&i.Body,
+		&i.WordCount,
+		&i.ReadingTime,
 	)
 	return i, err
 }
 
 const getProjectBySlug = `-- name: GetProjectBySlug :one
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE slug = $1 AND deleted_at IS NULL
 LIMIT 1
 `
@@ -301,12 +360,84 @@ func (q *Queries) GetProjectBySlug(ctx context.Context, slug string) (Project, e
 		&i.UpdatedAt,
 		&i.PublishedAt,
 		&i.DeletedAt,
+		&i.Body,
+		&i.WordCount,
+		&i.ReadingTime,
 	)
 	return i, err
 }
 
+const getProjectDocuments = `-- name: GetProjectDocuments :many
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
+JOIN media_relations mr ON m.id = mr.media_id
+WHERE mr.entity_type = 'project'
+  AND mr.entity_id = $1
+  AND mr.relation_type = 'document'
+  AND m.deleted_at IS NULL
+ORDER BY mr.sort_order ASC, m.created_at DESC
+`
+
+func (q *Queries) GetProjectDocuments(ctx context.Context, projectID pgtype.UUID) ([]Media, error) {
+	rows, err := q.db.Query(ctx, getProjectDocuments, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Media
+	for rows.Next() {
+		var i Media
+		if err := rows.Scan(
+			&i.ID,
+			&i.Filename,
+			&i.OriginalFilename,
+			&i.MimeType,
+			&i.FileSize,
+			&i.Width,
+			&i.Height,
+			&i.Duration,
+			&i.StorageType,
+			&i.S3Bucket,
+			&i.S3Region,
+			&i.OriginalKey,
+			&i.LargeKey,
+			&i.MediumKey,
+			&i.ThumbnailKey,
+			&i.AltText,
+			&i.UploadedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+			&i.PageCount,
+			&i.DocumentTitle,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getProjectFeaturedImage = `-- name: GetProjectFeaturedImage :one
-SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at FROM media m
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
 WHERE m.id = $1
   AND m.deleted_at IS NULL
 LIMIT 1
@@ -336,13 +467,31 @@ func (q *Queries) GetProjectFeaturedImage(ctx context.Context, featuredImageID p
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.DeletedAt,
+		&i.ProcessingStatus,
+		&i.ProcessingError,
+		&i.VideoCodec,
+		&i.Bitrate,
+		&i.WebKey,
+		&i.HlsKey,
+		&i.Copyright,
+		&i.Artist,
+		&i.IsPrivate,
+		&i.ContentHash,
+		&i.CollectionID,
+		&i.FocalX,
+		&i.FocalY,
+		&i.HeroKey,
+		&i.CardKey,
+		&i.OgKey,
+		&i.PageCount,
+		&i.DocumentTitle,
 	)
 	return i, err
 }
 
 const getProjectGallery = `-- name: GetProjectGallery :many
 
-SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at FROM media m
+SELECT m.id, m.filename, m.original_filename, m.mime_type, m.file_size, m.width, m.height, m.duration, m.storage_type, m.s3_bucket, m.s3_region, m.original_key, m.large_key, m.medium_key, m.thumbnail_key, m.alt_text, m.uploaded_by, m.created_at, m.updated_at, m.deleted_at, m.processing_status, m.processing_error, m.video_codec, m.bitrate, m.web_key, m.hls_key, m.copyright, m.artist, m.is_private, m.content_hash, m.collection_id, m.focal_x, m.focal_y, m.hero_key, m.card_key, m.og_key, m.page_count, m.document_title FROM media m
 JOIN media_relations mr ON m.id = mr.media_id
 WHERE mr.entity_type = 'project'
   AND mr.entity_id = $1
@@ -382,6 +531,24 @@ func (q *Queries) GetProjectGallery(ctx context.Context, projectID pgtype.UUID)
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.DeletedAt,
+			&i.ProcessingStatus,
+			&i.ProcessingError,
+			&i.VideoCodec,
+			&i.Bitrate,
+			&i.WebKey,
+			&i.HlsKey,
+			&i.Copyright,
+			&i.Artist,
+			&i.IsPrivate,
+			&i.ContentHash,
+			&i.CollectionID,
+			&i.FocalX,
+			&i.FocalY,
+			&i.HeroKey,
+			&i.CardKey,
+			&i.OgKey,
+			&i.PageCount,
+			&i.DocumentTitle,
 		); err != nil {
 			return nil, err
 		}
@@ -440,7 +607,7 @@ func (q *Queries) GetProjectTags(ctx context.Context, projectID pgtype.UUID) ([]
 }
 
 const getProjectsByStatus = `-- name: GetProjectsByStatus :many
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE status = $1
   AND deleted_at IS NULL
 ORDER BY created_at DESC
@@ -480,6 +647,9 @@ func (q *Queries) GetProjectsByStatus(ctx context.Context, arg GetProjectsByStat
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -492,7 +662,7 @@ func (q *Queries) GetProjectsByStatus(ctx context.Context, arg GetProjectsByStat
 }
 
 const getProjectsByTag = `-- name: GetProjectsByTag :many
-SELECT p.id, p.title, p.slug, p.description, p.project_date, p.status, p.client_name, p.project_year, p.project_url, p.project_status, p.featured_image_id, p.author_id, p.created_at, p.updated_at, p.published_at, p.deleted_at FROM projects p
+SELECT p.id, p.title, p.slug, p.description, p.project_date, p.status, p.client_name, p.project_year, p.project_url, p.project_status, p.featured_image_id, p.author_id, p.created_at, p.updated_at, p.published_at, p.deleted_at, p.body, p.word_count, p.reading_time FROM projects p
 JOIN project_tags pt ON p.id = pt.project_id
 WHERE pt.tag_id = $1
   AND p.deleted_at IS NULL
@@ -533,6 +703,9 @@ func (q *Queries) GetProjectsByTag(ctx context.Context, arg GetProjectsByTagPara
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -545,7 +718,7 @@ func (q *Queries) GetProjectsByTag(ctx context.Context, arg GetProjectsByTagPara
 }
 
 const getProjectsByYear = `-- name: GetProjectsByYear :many
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE project_year = $1
   AND deleted_at IS NULL
 ORDER BY created_at DESC
@@ -577,6 +750,9 @@ func (q *Queries) GetProjectsByYear(ctx context.Context, projectYear pgtype.Int4
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -589,7 +765,7 @@ func (q *Queries) GetProjectsByYear(ctx context.Context, projectYear pgtype.Int4
 }
 
 const getRecentProjects = `-- name: GetRecentProjects :many
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $1
@@ -621,6 +797,9 @@ func (q *Queries) GetRecentProjects(ctx context.Context, limitVal int32) ([]Proj
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -643,7 +822,7 @@ func (q *Queries) HardDeleteProject(ctx context.Context, id pgtype.UUID) error {
 }
 
 const listProjects = `-- name: ListProjects :many
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2
@@ -681,6 +860,9 @@ func (q *Queries) ListProjects(ctx context.Context, arg ListProjectsParams) ([]P
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -693,7 +875,7 @@ func (q *Queries) ListProjects(ctx context.Context, arg ListProjectsParams) ([]P
 }
 
 const listPublishedProjects = `-- name: ListPublishedProjects :many
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE status = 'published' 
   AND deleted_at IS NULL
 ORDER BY published_at DESC
@@ -732,6 +914,9 @@ func (q *Queries) ListPublishedProjects(ctx context.Context, arg ListPublishedPr
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -749,7 +934,7 @@ UPDATE projects SET
     published_at = NOW(),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at
+RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time
 `
 
 func (q *Queries) PublishProject(ctx context.Context, id pgtype.UUID) (Project, error) {
@@ -772,6 +957,9 @@ func (q *Queries) PublishProject(ctx context.Context, id pgtype.UUID) (Project,
 		&i.UpdatedAt,
 		&i.PublishedAt,
 		&i.DeletedAt,
+		&i.Body,
+		&i.WordCount,
+		&i.ReadingTime,
 	)
 	return i, err
 }
@@ -806,7 +994,7 @@ func (q *Queries) RestoreProject(ctx context.Context, id pgtype.UUID) error {
 
 const searchProjects = `-- name: SearchProjects :many
 
-SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at FROM projects
+SELECT id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time FROM projects
 WHERE deleted_at IS NULL
   AND (
     title ILIKE $1
@@ -851,6 +1039,9 @@ func (q *Queries) SearchProjects(ctx context.Context, arg SearchProjectsParams)
 			&i.UpdatedAt,
 			&i.PublishedAt,
 			&i.DeletedAt,
+			&i.Body,
+			&i.WordCount,
+			&i.ReadingTime,
 		); err != nil {
 			return nil, err
 		}
@@ -881,7 +1072,7 @@ UPDATE projects SET
     published_at = NULL,
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at
+RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time
 `
 
 func (q *Queries) UnpublishProject(ctx context.Context, id pgtype.UUID) (Project, error) {
@@ -904,6 +1095,9 @@ func (q *Queries) UnpublishProject(ctx context.Context, id pgtype.UUID) (Project
 		&i.UpdatedAt,
 		&i.PublishedAt,
 		&i.DeletedAt,
+		&i.Body,
+		&i.WordCount,
+		&i.ReadingTime,
 	)
 	return i, err
 }
@@ -912,24 +1106,30 @@ const updateProject = `-- name: UpdateProject :one
 UPDATE projects SET
     title             = COALESCE($1, title),
     slug              = COALESCE($2, slug),
-    description       = COALESCE($3, description),
-    project_date      = COALESCE($4, project_date),
-    status            = COALESCE($5, status),
-    client_name       = COALESCE($6, client_name),
-    project_year      = COALESCE($7, project_year),
-    project_url       = COALESCE($8, project_url),
-    project_status    = COALESCE($9, project_status),
-    featured_image_id = $10,
+    description       = $3,
+    body              = COALESCE($4, body),
+    word_count        = COALESCE($5, word_count),
+    reading_time      = COALESCE($6, reading_time),
+    project_date      = COALESCE($7, project_date),
+    status            = COALESCE($8, status),
+    client_name       = COALESCE($9, client_name),
+    project_year      = COALESCE($10, project_year),
+    project_url       = COALESCE($11, project_url),
+    project_status    = COALESCE($12, project_status),
+    featured_image_id = $13,
     updated_at        = NOW()
-WHERE id = $11
+WHERE id = $14
   AND deleted_at IS NULL
-RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at
+RETURNING id, title, slug, description, project_date, status, client_name, project_year, project_url, project_status, featured_image_id, author_id, created_at, updated_at, published_at, deleted_at, body, word_count, reading_time
 `
 
 type UpdateProjectParams struct {
 	Title           pgtype.Text
 	Slug            pgtype.Text
 	Description     pgtype.Text
+	Body            pgtype.Text
+	WordCount       pgtype.Int4
+	ReadingTime     pgtype.Int4
 	ProjectDate     pgtype.Date
 	Status          pgtype.Text
 	ClientName      pgtype.Text
@@ -945,6 +1145,9 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		arg.Title,
 		arg.Slug,
 		arg.Description,
+		arg.Body,
+		arg.WordCount,
+		arg.ReadingTime,
 		arg.ProjectDate,
 		arg.Status,
 		arg.ClientName,
@@ -972,6 +1175,9 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.UpdatedAt,
 		&i.PublishedAt,
 		&i.DeletedAt,
+		&i.Body,
+		&i.WordCount,
+		&i.ReadingTime,
 	)
 	return i, err
 }
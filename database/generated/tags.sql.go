@@ -16,7 +16,7 @@ const checkTagSlugExists = `-- name: CheckTagSlugExists :one
 SELECT EXISTS(
     SELECT 1 FROM tags 
     WHERE slug = $1 
-      AND id != $2
+      AND id IS DISTINCT FROM $2
 )
 `
 
@@ -120,10 +120,11 @@ func (q *Queries) FindOrCreateTag(ctx context.Context, arg FindOrCreateTagParams
 const getMostUsedTags = `-- name: GetMostUsedTags :many
 SELECT 
     t.id, t.name, t.slug, t.created_at, t.updated_at,
-    COUNT(pt.project_id) as usage_count
+    (
+        (SELECT COUNT(*) FROM project_tags pt WHERE pt.tag_id = t.id)
+        + (SELECT COUNT(*) FROM blog_tags bt WHERE bt.tag_id = t.id)
+    )::BIGINT as usage_count
 FROM tags t
-LEFT JOIN project_tags pt ON t.id = pt.tag_id
-GROUP BY t.id
 ORDER BY usage_count DESC, t.name ASC
 LIMIT $1
 `
@@ -217,16 +218,17 @@ func (q *Queries) GetTagIDBySlug(ctx context.Context, slug string) (pgtype.UUID,
 
 const getTagUsageCount = `-- name: GetTagUsageCount :one
 
-SELECT COUNT(*) FROM project_tags
-WHERE tag_id = $1
+SELECT
+    (SELECT COUNT(*) FROM project_tags pt WHERE pt.tag_id = $1)
+    + (SELECT COUNT(*) FROM blog_tags bt WHERE bt.tag_id = $1) AS usage_count
 `
 
 // Tag Usage Statistics
 func (q *Queries) GetTagUsageCount(ctx context.Context, tagID pgtype.UUID) (int64, error) {
 	row := q.db.QueryRow(ctx, getTagUsageCount, tagID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+	var usage_count int64
+	err := row.Scan(&usage_count)
+	return usage_count, err
 }
 
 const getTagsByIDs = `-- name: GetTagsByIDs :many
@@ -267,6 +269,8 @@ const getUnusedTags = `-- name: GetUnusedTags :many
 SELECT t.id, t.name, t.slug, t.created_at, t.updated_at FROM tags t
 LEFT JOIN project_tags pt ON t.id = pt.tag_id
 WHERE pt.tag_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM blog_tags bt WHERE bt.tag_id = t.id)
+  AND NOT EXISTS (SELECT 1 FROM media_tags mt WHERE mt.tag_id = t.id)
 ORDER BY t.name ASC
 `
 
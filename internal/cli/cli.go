@@ -0,0 +1,117 @@
+// internal/cli/cli.go
+
+// Package cli implements the maintenance commands run through the server
+// binary, e.g. `./bin/main media migrate-storage -to s3`
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/iankencruz/threefive/database"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/iankencruz/threefive/internal/server"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/logger"
+)
+
+// command is a maintenance task; args are the flags after its name
+type command struct {
+	summary string
+	run     func(ctx context.Context, log *slog.Logger, args []string) error
+}
+
+var commands = map[string]command{
+	"media backfill-hashes": {
+		summary: "hash existing media files so duplicate uploads are detected",
+		run:     backfillHashes,
+	},
+	"media check-storage": {
+		summary: "report missing files and unreferenced objects, optionally deleting orphans",
+		run:     checkStorage,
+	},
+	"media migrate-storage": {
+		summary: "copy all media to another storage backend and repoint the rows",
+		run:     migrateStorage,
+	},
+}
+
+// ErrUsage is returned when the command line names no known command
+var ErrUsage = errors.New("unknown command")
+
+// Run executes the command named by the leading args
+func Run(ctx context.Context, args []string) error {
+	log := slog.New(logger.NewPrettyHandler(os.Stderr, logger.PrettyHandlerOptions{
+		SlogOpts: slog.HandlerOptions{Level: slog.LevelInfo},
+	}))
+
+	// Command names are one or two words
+	for n := min(2, len(args)); n > 0; n-- {
+		if cmd, ok := commands[strings.Join(args[:n], " ")]; ok {
+			return cmd.run(ctx, log, args[n:])
+		}
+	}
+
+	usage()
+	return fmt.Errorf("%w: %s", ErrUsage, strings.Join(args, " "))
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: main <command> [flags]\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-24s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun a command with -h for its flags. Without a command the web server starts.")
+}
+
+// newFlagSet returns a flag set for the named command that reports errors
+// instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: main %s [flags]\n\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// mediaEnv is the database and storage configured for the web server
+type mediaEnv struct {
+	db      database.Service
+	queries *generated.Queries
+	storage services.StorageProvider
+	media   *services.MediaService
+}
+
+func newMediaEnv(ctx context.Context, log *slog.Logger) (*mediaEnv, error) {
+	storage, err := server.NewStorage(ctx, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	db := database.New(log)
+	queries := generated.New(db.Pool())
+
+	return &mediaEnv{
+		db:      db,
+		queries: queries,
+		storage: storage,
+		media:   services.NewMediaService(db.Pool(), queries, storage, services.MediaConfig{}),
+	}, nil
+}
+
+func (e *mediaEnv) Close() {
+	e.db.Close()
+}
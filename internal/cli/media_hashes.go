@@ -0,0 +1,45 @@
+// internal/cli/media_hashes.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+)
+
+// backfillHashes records the content hash of media uploaded before
+// duplicate detection existed
+func backfillHashes(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := newFlagSet("media backfill-hashes")
+	batchSize := fs.Int("batch-size", 100, "media rows read per query")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	env, err := newMediaEnv(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	report, err := env.media.BackfillContentHashes(ctx, int32(*batchSize), log)
+	if report != nil {
+		log.Info("content hash backfill finished",
+			"hashed", report.Hashed,
+			"skipped", report.Skipped,
+			"failed", report.Failed,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d media could not be hashed; run the command again to retry", report.Failed)
+	}
+	return nil
+}
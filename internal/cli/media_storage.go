@@ -0,0 +1,151 @@
+// internal/cli/media_storage.go
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/iankencruz/threefive/internal/services"
+)
+
+// migrateStorage copies the media library from the configured storage
+// (STORAGE_PROVIDER) to the one described by the flags
+func migrateStorage(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := newFlagSet("media migrate-storage")
+	to := fs.String("to", "", `target backend: "s3" or "local" (required)`)
+	bucket := fs.String("bucket", os.Getenv("TARGET_S3_BUCKET"), "target S3 bucket")
+	region := fs.String("region", os.Getenv("TARGET_S3_REGION"), "target S3 region")
+	endpoint := fs.String("endpoint", os.Getenv("TARGET_S3_ENDPOINT"), "target S3-compatible endpoint (Vultr, MinIO)")
+	baseURL := fs.String("base-url", "", "public URL of the target files (CDN URL for S3, URL path for local)")
+	dir := fs.String("dir", "./uploads", "target directory for local storage")
+	batchSize := fs.Int("batch-size", 100, "media rows read per query")
+	dryRun := fs.Bool("dry-run", false, "list the media that would be migrated without copying")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	var target services.StorageProvider
+	switch *to {
+	case "s3":
+		s3Storage, err := services.NewS3Storage(ctx, services.S3Config{
+			Bucket:          *bucket,
+			Region:          *region,
+			Endpoint:        *endpoint,
+			AccessKeyID:     envOr("TARGET_S3_ACCESS_KEY_ID", "S3_ACCESS_KEY_ID"),
+			SecretAccessKey: envOr("TARGET_S3_SECRET_ACCESS_KEY", "S3_SECRET_ACCESS_KEY"),
+			BaseURL:         *baseURL,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize target storage: %w", err)
+		}
+		target = s3Storage
+	case "local":
+		if *baseURL == "" {
+			*baseURL = "/uploads"
+		}
+		// Nothing is signed during a migration
+		target = services.NewLocalStorage(*dir, *baseURL, nil)
+	default:
+		fs.Usage()
+		return fmt.Errorf(`-to must be "s3" or "local"`)
+	}
+
+	env, err := newMediaEnv(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	log.Info("migrating media storage",
+		"from", services.LocationOf(env.storage).String(),
+		"to", services.LocationOf(target).String(),
+		"dry_run", *dryRun,
+	)
+
+	report, err := env.media.MigrateStorage(ctx, target, services.StorageMigrationOptions{
+		BatchSize: int32(*batchSize),
+		DryRun:    *dryRun,
+	}, log)
+	if report != nil {
+		log.Info("storage migration finished",
+			"migrated", report.Migrated,
+			"already_migrated", report.Already,
+			"skipped", report.Skipped,
+			"failed", report.Failed,
+			"objects", report.Objects,
+			"bytes", report.Bytes,
+		)
+	}
+	if err != nil {
+		return err
+	}
+	if report.Failed > 0 || report.Skipped > 0 {
+		return fmt.Errorf("%d media failed and %d were skipped; run the command again to retry", report.Failed, report.Skipped)
+	}
+
+	if !*dryRun {
+		log.Info("all media is on the target; point STORAGE_PROVIDER and its settings at it and restart the server. Files on the old storage were left in place.")
+	}
+	return nil
+}
+
+// checkStorage reconciles the configured storage with the media table
+func checkStorage(ctx context.Context, log *slog.Logger, args []string) error {
+	fs := newFlagSet("media check-storage")
+	deleteOrphans := fs.Bool("delete-orphans", false, "delete unreferenced objects older than the grace period")
+	grace := fs.Duration("grace", services.DefaultOrphanGracePeriod, "minimum age of orphans to delete")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	env, err := newMediaEnv(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer env.Close()
+
+	report, err := env.media.CheckStorage(ctx, services.StorageCheckOptions{
+		DeleteOrphans: *deleteOrphans,
+		GracePeriod:   *grace,
+	})
+	if report != nil {
+		for _, missing := range report.Missing {
+			fmt.Printf("missing\t%s\t%s\n", missing.MediaID.String(), missing.Key)
+		}
+		for _, orphan := range report.Orphans {
+			fmt.Printf("orphan\t%s\t%d\t%s\n", orphan.Key, orphan.Size, orphan.LastModified.Format(time.RFC3339))
+		}
+		for _, media := range report.Unused {
+			fmt.Printf("unused\t%s\t%s\n", media.ID.String(), media.OriginalFilename)
+		}
+		log.Info("storage check finished",
+			"objects", report.Objects,
+			"missing", len(report.Missing),
+			"orphans", len(report.Orphans),
+			"reaped", report.Reaped,
+			"unused_media", len(report.Unused),
+		)
+	}
+	return err
+}
+
+// envOr returns the first non-empty environment variable
+func envOr(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,402 @@
+// internal/handler/blogs.go
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/iankencruz/threefive/internal/middleware"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/pkg/validation"
+	"github.com/iankencruz/threefive/templates/components/toast"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/iankencruz/threefive/templates/pages"
+	"github.com/iankencruz/threefive/templates/pages/admin"
+	"github.com/labstack/echo/v5"
+)
+
+type BlogHandler struct {
+	logger       *slog.Logger
+	blogService  *services.BlogService
+	tagService   *services.TagService
+	mediaService *services.MediaService
+	seoService   *services.SEOService
+}
+
+func NewBlogHandler(logger *slog.Logger, blogService *services.BlogService, tagService *services.TagService, mediaService *services.MediaService, seoService *services.SEOService) *BlogHandler {
+	return &BlogHandler{
+		logger:       logger,
+		blogService:  blogService,
+		tagService:   tagService,
+		mediaService: mediaService,
+		seoService:   seoService,
+	}
+}
+
+// ShowBlogsList renders the admin blogs list page
+func (h *BlogHandler) ShowBlogsList(c *echo.Context) error {
+	h.logger.Debug("Loading blogs list")
+
+	// Get pagination parameters
+	page := 1
+	if p := c.QueryParam("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+
+	limit := int32(20)
+	offset := int32((page - 1) * int(limit))
+
+	blogs, err := h.blogService.ListBlogs(c.Request().Context(), limit, offset)
+	if err != nil {
+		h.logger.Error("failed to list blogs", "error", err)
+		return c.String(500, "Failed to load blogs")
+	}
+
+	totalCount, err := h.blogService.CountBlogs(c.Request().Context())
+	if err != nil {
+		h.logger.Error("failed to count blogs", "error", err)
+		totalCount = 0
+	}
+
+	totalPages := int((totalCount + int64(limit) - 1) / int64(limit))
+
+	ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
+	currentPath := c.Request().URL.Path
+
+	component := admin.BlogsList(admin.BlogsListProps{
+		Blogs:       blogs,
+		CurrentPage: page,
+		TotalPages:  totalPages,
+	}, currentPath)
+
+	return responses.Render(ctx, c, component)
+}
+
+// ShowCreateModal loads the create blog modal
+func (h *BlogHandler) ShowCreateModal(c *echo.Context) error {
+	h.logger.Debug("Loading create blog modal")
+
+	ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
+
+	component := lib.BlogCreateModal(nil)
+	return responses.Render(ctx, c, component)
+}
+
+// CreateBlog handles blog creation from modal
+func (h *BlogHandler) CreateBlog(c *echo.Context) error {
+	h.logger.Debug("Create blog request")
+
+	if err := c.Request().ParseForm(); err != nil {
+		h.logger.Error("failed to parse form", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to parse form data")
+	}
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		return responses.ErrorToast(c.Request().Context(), c, "User not authenticated")
+	}
+
+	var userID uuid.UUID
+	if err := userID.Scan(user.ID.Bytes[:]); err != nil {
+		h.logger.Error("failed to parse user ID", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to get user ID")
+	}
+
+	// Parse tags (comma-separated)
+	var tagNames []string
+	if tagsStr := c.FormValue("tags"); tagsStr != "" {
+		tagNames = strings.Split(tagsStr, ",")
+		for i, tag := range tagNames {
+			tagNames[i] = strings.TrimSpace(tag)
+		}
+	}
+
+	req := &services.CreateBlogRequest{
+		Title:      c.FormValue("title"),
+		Slug:       c.FormValue("slug"),
+		Excerpt:    c.FormValue("excerpt"),
+		Body:       c.FormValue("body"),
+		Status:     c.FormValue("status"),
+		IsFeatured: c.FormValue("is_featured") == "true",
+		AuthorID:   userID,
+		TagNames:   tagNames,
+	}
+	if imgIDStr := c.FormValue("featured_image_id"); imgIDStr != "" {
+		if imgUUID, err := uuid.Parse(imgIDStr); err == nil {
+			req.FeaturedImageID = &imgUUID
+		}
+	}
+
+	fieldErrors, err := req.Validate()
+	if err != nil {
+		h.logger.Warn("validation failed", "errors", fieldErrors)
+		component := lib.BlogCreateModal(fieldErrors)
+		return responses.RenderError(c.Request().Context(), c, component, "Please fix the validation errors")
+	}
+
+	blog, err := h.blogService.CreateBlog(c.Request().Context(), req)
+	if err != nil {
+		h.logger.Error("failed to create blog", "error", err)
+		component := lib.BlogCreateModal(map[string]string{
+			"general": err.Error(),
+		})
+		return responses.RenderError(c.Request().Context(), c, component, err.Error())
+	}
+
+	// Success - redirect to edit page, warning about media the public page hides
+	message, variant := "Blog post created successfully", toast.VariantSuccess
+	if warning := privateEmbedWarning(c.Request().Context(), h.mediaService, req.Body); warning != "" {
+		message, variant = warning, toast.VariantWarning
+	}
+	return responses.RedirectWithToast(
+		c.Request().Context(),
+		c,
+		"/admin/blogs/"+blog.Blog.Slug,
+		message,
+		variant,
+	)
+}
+
+// ShowEditPage renders the blog edit page
+func (h *BlogHandler) ShowEditPage(c *echo.Context) error {
+	slug := c.Param("slug")
+
+	h.logger.Debug("Loading blog edit page", "slug", slug)
+
+	blog, err := h.blogService.GetBlogBySlug(c.Request().Context(), slug)
+	if err != nil {
+		h.logger.Error("failed to get blog", "error", err, "slug", slug)
+		return c.String(404, "Blog post not found")
+	}
+
+	tags, err := h.tagService.ListAllTags(c.Request().Context())
+	if err != nil {
+		h.logger.Error("failed to list tags", "error", err)
+		tags = []generated.Tag{}
+	}
+
+	seoResp, _ := h.seoService.GetSEOResponse(c.Request().Context(), "blog", blog.Blog.ID.Bytes, h.mediaService)
+
+	ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
+	currentPath := c.Request().URL.Path
+
+	component := admin.BlogEditPage(blog, tags, seoResp, currentPath, nil)
+	return responses.Render(ctx, c, component)
+}
+
+// UpdateBlog handles blog update
+func (h *BlogHandler) UpdateBlog(c *echo.Context) error {
+	slug := c.Param("slug")
+	h.logger.Debug("Update blog request", "slug", slug)
+
+	existing, err := h.blogService.GetBlogBySlug(c.Request().Context(), slug)
+	if err != nil {
+		h.logger.Error("failed to get blog", "error", err, "slug", slug)
+		return responses.ErrorToast(c.Request().Context(), c, "Blog post not found")
+	}
+
+	// Fetch SEO once — reused in all error paths
+	existingSEO, _ := h.seoService.GetSEOResponse(c.Request().Context(), "blog", existing.Blog.ID.Bytes, h.mediaService)
+
+	if err := c.Request().ParseForm(); err != nil {
+		h.logger.Error("failed to parse form", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to parse form data")
+	}
+
+	req := &services.UpdateBlogRequest{
+		Title:           c.FormValue("title"),
+		Slug:            c.FormValue("slug"),
+		Excerpt:         c.FormValue("excerpt"),
+		Body:            c.FormValue("body"),
+		Status:          c.FormValue("status"),
+		IsFeatured:      c.FormValue("is_featured") == "true",
+		FeaturedImageID: c.FormValue("featured_image_id"),
+		Tags:            c.FormValue("tags"),
+	}
+
+	fieldErrors, err := req.Validate()
+	if err != nil {
+		tags, _ := h.tagService.ListAllTags(c.Request().Context())
+		ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
+
+		toastMessage := fmt.Sprintf("Please fix %d validation error(s)", len(fieldErrors))
+
+		component := admin.BlogEditForm(existing, tags, existingSEO, fieldErrors)
+		return responses.RenderError(ctx, c, component, toastMessage)
+	}
+
+	updated, err := h.blogService.UpdateBlogBySlug(c.Request().Context(), slug, req)
+	if err != nil {
+		h.logger.Error("failed to update blog", "error", err)
+
+		tags, _ := h.tagService.ListAllTags(c.Request().Context())
+		ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
+
+		if strings.Contains(err.Error(), "slug already exists") {
+			fieldErrors := validation.FieldErrors{"slug": err.Error()}
+			component := admin.BlogEditForm(existing, tags, existingSEO, fieldErrors)
+			return responses.RenderError(ctx, c, component, err.Error())
+		}
+
+		dbErrors := validation.FieldErrors{"general": err.Error()}
+		component := admin.BlogEditForm(existing, tags, existingSEO, dbErrors)
+		return responses.RenderError(ctx, c, component, err.Error())
+	}
+
+	// Upsert SEO — non-fatal if it fails
+	seoReq := services.UpsertSEORequest{
+		EntityType:   "blog",
+		EntityID:     updated.Blog.ID.Bytes,
+		SEOTitle:     c.FormValue("seo_title"),
+		SEODesc:      c.FormValue("seo_description"),
+		OGTitle:      c.FormValue("og_title"),
+		OGDesc:       c.FormValue("og_description"),
+		CanonicalURL: c.FormValue("canonical_url"),
+		RobotsIndex:  c.FormValue("robots_index") == "true",
+		RobotsFollow: c.FormValue("robots_follow") == "true",
+	}
+	if ogImageID := c.FormValue("og_image_id"); ogImageID != "" {
+		if parsed, err := uuid.Parse(ogImageID); err == nil {
+			seoReq.OGImageID = &parsed
+		}
+	}
+	if _, err := h.seoService.UpsertSEO(c.Request().Context(), seoReq); err != nil {
+		h.logger.Error("failed to upsert blog SEO", "error", err)
+	}
+
+	warning := privateEmbedWarning(c.Request().Context(), h.mediaService, req.Body)
+
+	if updated.Blog.Slug != slug {
+		// Slug changed - MUST redirect to new URL
+		message, variant := "Blog post updated successfully (URL changed)", toast.VariantSuccess
+		if warning != "" {
+			message, variant = warning, toast.VariantWarning
+		}
+		return responses.RedirectWithToast(
+			c.Request().Context(),
+			c,
+			"/admin/blogs/"+updated.Blog.Slug,
+			message,
+			variant,
+		)
+	}
+
+	tags, _ := h.tagService.ListAllTags(c.Request().Context())
+	freshSEO, _ := h.seoService.GetSEOResponse(c.Request().Context(), "blog", updated.Blog.ID.Bytes, h.mediaService)
+	ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
+
+	component := admin.BlogEditForm(updated, tags, freshSEO, nil)
+	if warning != "" {
+		return responses.RenderWithToast(ctx, c, component, warning, toast.VariantWarning)
+	}
+	return responses.RenderSuccess(ctx, c, component, "Blog post updated successfully")
+}
+
+// DeleteBlog soft-deletes a blog post
+func (h *BlogHandler) DeleteBlog(c *echo.Context) error {
+	slug := c.Param("slug")
+
+	h.logger.Debug("Delete blog request", "slug", slug)
+
+	if err := h.blogService.DeleteBlogBySlug(c.Request().Context(), slug); err != nil {
+		h.logger.Error("failed to delete blog", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to delete blog post")
+	}
+
+	return responses.RedirectWithToast(
+		c.Request().Context(),
+		c,
+		"/admin/blogs",
+		"Blog post deleted successfully",
+		"success",
+	)
+}
+
+// PublishBlog publishes a blog post
+func (h *BlogHandler) PublishBlog(c *echo.Context) error {
+	slug := c.Param("slug")
+
+	h.logger.Debug("Publish blog request", "slug", slug)
+
+	blog, err := h.blogService.GetBlogBySlug(c.Request().Context(), slug)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, "Blog post not found")
+	}
+
+	if _, err := h.blogService.PublishBlog(c.Request().Context(), blog.Blog.ID.Bytes); err != nil {
+		h.logger.Error("failed to publish blog", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to publish blog post")
+	}
+
+	return responses.SuccessToast(c.Request().Context(), c, "Blog post published successfully")
+}
+
+// UnpublishBlog reverts a blog post to draft
+func (h *BlogHandler) UnpublishBlog(c *echo.Context) error {
+	slug := c.Param("slug")
+
+	h.logger.Debug("Unpublish blog request", "slug", slug)
+
+	blog, err := h.blogService.GetBlogBySlug(c.Request().Context(), slug)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, "Blog post not found")
+	}
+
+	if _, err := h.blogService.UnpublishBlog(c.Request().Context(), blog.Blog.ID.Bytes); err != nil {
+		h.logger.Error("failed to unpublish blog", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to unpublish blog post")
+	}
+
+	return responses.SuccessToast(c.Request().Context(), c, "Blog post unpublished successfully")
+}
+
+// Public Handlers
+
+// ShowPublicBlogList renders the public blog listing
+func (h *BlogHandler) ShowPublicBlogList(c *echo.Context) error {
+	blogs, err := h.blogService.ListPublishedBlogs(c.Request().Context(), 100, 0)
+	if err != nil {
+		h.logger.Error("failed to list published blogs", "error", err)
+		return c.String(500, "Failed to load blog")
+	}
+
+	component := pages.Blog(blogs, nil)
+	return responses.Render(c.Request().Context(), c, component)
+}
+
+// ShowPublicBlog renders a single public blog post
+func (h *BlogHandler) ShowPublicBlog(c *echo.Context) error {
+	slug := c.Param("slug")
+
+	blog, err := h.blogService.GetBlogBySlug(c.Request().Context(), slug)
+	if err != nil {
+		h.logger.Error("failed to get blog", "error", err, "slug", slug)
+		return c.String(404, "Blog post not found")
+	}
+
+	if blog.Blog.Status.Valid && blog.Blog.Status.String != "published" {
+		return c.String(404, "Blog post not found")
+	}
+
+	if err := h.blogService.RenderBody(c.Request().Context(), blog); err != nil {
+		h.logger.Error("failed to render blog body", "error", err, "slug", slug)
+	}
+
+	var ogImage string
+	if blog.FeaturedImage != nil && services.IsImage(blog.FeaturedImage.MimeType) {
+		ogImage = blog.FeaturedImage.OGURL
+	}
+
+	seo, _ := h.seoService.GetSEO(c.Request().Context(), "blog", blog.Blog.ID.Bytes)
+	seoData := services.ToSEOData(seo, blog.Blog.Title, fullURL(c, "/blog/"+blog.Blog.Slug), "ThreeFive", ogImage)
+
+	component := pages.BlogDetails(blog, seoData)
+	return responses.Render(c.Request().Context(), c, component)
+}
@@ -2,10 +2,15 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strconv"
 
+	"github.com/a-h/templ"
 	"github.com/google/uuid"
 	"github.com/iankencruz/threefive/database/generated"
 	"github.com/iankencruz/threefive/internal/middleware"
@@ -47,47 +52,12 @@ func (h *MediaHandler) ShowMediaList(c *echo.Context) error {
 	limit := int32(20)
 	offset := int32((page - 1) * int(limit))
 
-	// Get filter parameters
-	mediaType := c.QueryParam("type") // "image", "video", "document"
-
-	var mediaList []generated.Media
-	var err error
-
-	if mediaType != "" {
-		// Filter by type
-		var mimePattern string
-		switch mediaType {
-		case "image":
-			mimePattern = "image/%"
-		case "video":
-			mimePattern = "video/%"
-		case "document":
-			mimePattern = "application/%"
-		default:
-			mimePattern = "%"
-		}
-
-		h.logger.Debug("Filtering media by type", "type", mediaType, "pattern", mimePattern)
-
-		mediaList, err = h.mediaService.ListMediaByType(c.Request().Context(), mimePattern, limit, offset)
-		if err != nil {
-			h.logger.Error("failed to list media by type", "error", err)
-			return c.String(500, "Failed to load media")
-		}
-	} else {
-		// List all media
-		mediaList, err = h.mediaService.ListMedia(c.Request().Context(), limit, offset)
-		if err != nil {
-			h.logger.Error("failed to list media", "error", err)
-			return c.String(500, "Failed to load media")
-		}
-	}
+	filters := h.mediaFilterOptions(c)
 
-	// Get total count for pagination
-	totalCount, err := h.mediaService.CountMedia(c.Request().Context())
+	mediaList, totalCount, err := h.mediaService.FilterMedia(c.Request().Context(), filters.Filter, limit, offset)
 	if err != nil {
-		h.logger.Error("failed to count media", "error", err)
-		totalCount = 0
+		h.logger.Error("failed to list media", "error", err)
+		return c.String(500, "Failed to load media")
 	}
 
 	// Calculate total pages
@@ -99,7 +69,7 @@ func (h *MediaHandler) ShowMediaList(c *echo.Context) error {
 		"total_media", len(mediaList),
 		"page", page,
 		"total_pages", totalPages,
-		"filter", mediaType,
+		"filter", filters.Filter.Query().Encode(),
 	)
 
 	// Add user to context for template
@@ -107,10 +77,12 @@ func (h *MediaHandler) ShowMediaList(c *echo.Context) error {
 
 	// Render the media library page
 	component := admin.MediaLibrary(admin.MediaLibraryProps{
-		Media:       mediaResponses,
-		CurrentPage: page,
-		TotalPages:  totalPages,
-		MediaType:   mediaType,
+		Media:        mediaResponses,
+		CurrentPage:  page,
+		TotalPages:   totalPages,
+		TotalCount:   totalCount,
+		Filters:      filters,
+		DirectUpload: h.mediaService.SupportsDirectUpload(),
 	}, currentPath)
 
 	return responses.Render(ctx, c, component)
@@ -152,25 +124,27 @@ func (h *MediaHandler) GetMediaDetail(c *echo.Context) error {
 	}
 
 	// Convert to response
-	mediaResponse := services.MediaResponse{
-		ID:               media.ID,
-		Filename:         media.Filename,
-		OriginalFilename: media.OriginalFilename,
-		MimeType:         media.MimeType,
-		FileSize:         media.FileSize,
-		Width:            media.Width,
-		Height:           media.Height,
-		URL:              h.mediaService.GetMediaURL(media),
-		AltText:          media.AltText.String,
-		StorageType:      media.StorageType,
-		CreatedAt:        media.CreatedAt,
-		UpdatedAt:        media.UpdatedAt,
+	mediaResponse := h.mediaService.ToMediaResponse(media)
+
+	references, err := h.mediaService.GetMediaReferences(c.Request().Context(), mediaID)
+	if err != nil {
+		h.logger.Error("failed to get media references", "error", err, "media_id", mediaUUID)
+	}
+
+	tags, err := h.mediaService.GetMediaTags(c.Request().Context(), mediaID)
+	if err != nil {
+		h.logger.Error("failed to get media tags", "error", err, "media_id", mediaUUID)
+	}
+
+	collections, err := h.mediaService.ListMediaCollections(c.Request().Context())
+	if err != nil {
+		h.logger.Error("failed to list media collections", "error", err)
 	}
 
 	h.logger.Info("Media details retrieved successfully", "media_id", mediaUUID)
 
 	// Render the detail modal
-	component := lib.MediaDetailModal(mediaResponse)
+	component := lib.MediaDetailModal(mediaResponse, references, tags, collections)
 	return responses.Render(c.Request().Context(), c, component)
 }
 
@@ -192,6 +166,8 @@ func (h *MediaHandler) UploadMedia(c *echo.Context) error {
 	// Get optional alt text
 	altText := c.FormValue("alt_text")
 
+	opts := uploadOptions(c)
+
 	// Convert user ID to pgtype.UUID
 	var uploadedBy pgtype.UUID
 	if err := uploadedBy.Scan(user.ID.String()); err != nil {
@@ -200,8 +176,12 @@ func (h *MediaHandler) UploadMedia(c *echo.Context) error {
 	}
 
 	// Upload media (this now handles video thumbnail generation)
-	media, err := h.mediaService.UploadMedia(c.Request().Context(), file, altText, uploadedBy)
+	media, err := h.mediaService.UploadMedia(c.Request().Context(), file, altText, uploadedBy, opts)
 	if err != nil {
+		var dup *services.DuplicateMediaError
+		if errors.As(err, &dup) {
+			return h.renderDuplicateMedia(c, dup)
+		}
 		h.logger.Error("failed to upload media", "error", err)
 		return responses.ErrorToast(c.Request().Context(), c, err.Error())
 	}
@@ -217,6 +197,42 @@ func (h *MediaHandler) UploadMedia(c *echo.Context) error {
 		"user_id", user.ID,
 	)
 
+	return h.renderUploadedMedia(c, media)
+}
+
+// uploadOptions reads the options shared by every upload form. Image
+// metadata (EXIF, GPS) is stripped unless explicitly kept.
+func uploadOptions(c *echo.Context) services.UploadOptions {
+	return services.UploadOptions{
+		PreserveMetadata: c.FormValue("preserve_metadata") == "true",
+		AllowDuplicate:   c.FormValue("allow_duplicate") == "true",
+	}
+}
+
+// renderDuplicateMedia swaps nothing and fires a media-duplicate event so
+// the upload form can offer the existing media or upload anyway
+func (h *MediaHandler) renderDuplicateMedia(c *echo.Context, dup *services.DuplicateMediaError) error {
+	existing := h.mediaService.ToMediaResponse(dup.Existing)
+	trigger, err := json.Marshal(map[string]any{
+		"media-duplicate": map[string]string{
+			"id":            existing.ID.String(),
+			"filename":      existing.OriginalFilename,
+			"thumbnail_url": existing.ThumbnailURL,
+		},
+	})
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, dup.Error())
+	}
+
+	header := c.Response().Header()
+	header.Set("HX-Trigger", string(trigger))
+	header.Set("HX-Reswap", "none")
+	return c.NoContent(http.StatusOK)
+}
+
+// renderUploadedMedia returns the card for a new upload, or the grid itself
+// when it replaces the library's empty state
+func (h *MediaHandler) renderUploadedMedia(c *echo.Context, media *generated.Media) error {
 	// Get total count BEFORE this upload to determine if grid exists
 	totalCount, err := h.mediaService.CountMedia(c.Request().Context())
 	if err != nil {
@@ -233,17 +249,26 @@ func (h *MediaHandler) UploadMedia(c *echo.Context) error {
 		"thumbnail_url", mediaResponse.ThumbnailURL,
 	)
 
+	// Files uploaded in a batch report progress in the upload dialog instead
+	// of a toast each
+	render := responses.RenderSuccess
+	if c.FormValue("batch") == "true" {
+		render = func(ctx context.Context, c *echo.Context, component templ.Component, _ string) error {
+			return responses.Render(ctx, c, component)
+		}
+	}
+
 	if totalCount == 1 {
 		// First upload - replace empty state with grid container + first card
 		h.logger.Debug("first media upload, creating grid")
 		component := lib.MediaGridStart([]services.MediaResponse{mediaResponse})
 		c.Response().Header().Set("HX-Reswap", "outerHTML") // Override to replace empty state
-		return responses.RenderSuccess(c.Request().Context(), c, component, "File uploaded successfully")
+		return render(c.Request().Context(), c, component, "File uploaded successfully")
 	} else {
 		// Subsequent uploads - just return the card (will be appended via afterbegin)
 		h.logger.Debug("appending new media card", "total_count", totalCount)
 		component := lib.MediaCard(mediaResponse)
-		return responses.RenderSuccess(c.Request().Context(), c, component, "File uploaded successfully")
+		return render(c.Request().Context(), c, component, "File uploaded successfully")
 	}
 }
 
@@ -277,8 +302,16 @@ func (h *MediaHandler) DeleteMedia(c *echo.Context) error {
 
 	h.logger.Info("Attempting to delete media", "media_id", mediaUUID)
 
-	// Soft delete media
-	err = h.mediaService.DeleteMedia(c.Request().Context(), mediaID)
+	// Soft delete media; media still in use needs confirming with force=true
+	err = h.mediaService.DeleteMedia(c.Request().Context(), mediaID, c.QueryParam("force") == "true")
+	var inUse *services.MediaInUseError
+	if errors.As(err, &inUse) {
+		// Keep the card and ask in a dialog instead
+		c.Response().Header().Set("HX-Retarget", "#modal-container")
+		c.Response().Header().Set("HX-Reswap", "innerHTML")
+		component := lib.MediaInUseDialog(mediaUUID.String(), inUse.References)
+		return responses.Render(c.Request().Context(), c, component)
+	}
 	if err != nil {
 		h.logger.Error("failed to delete media", "error", err, "media_id", mediaUUID)
 		component := lib.ErrorMessage("Failed to delete media")
@@ -291,6 +324,45 @@ func (h *MediaHandler) DeleteMedia(c *echo.Context) error {
 	return responses.SuccessToast(c.Request().Context(), c, "Media deleted successfully")
 }
 
+// GetMediaCard re-renders a single media card (polled while processing)
+func (h *MediaHandler) GetMediaCard(c *echo.Context) error {
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, "Invalid media ID format")
+	}
+
+	media, err := h.mediaService.GetMediaByID(c.Request().Context(), mediaID)
+	if err != nil {
+		h.logger.Error("failed to get media", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(c.Request().Context(), c, "Media not found")
+	}
+
+	component := lib.MediaCard(h.mediaService.ToMediaResponse(media))
+	return responses.Render(c.Request().Context(), c, component)
+}
+
+// ReprocessMedia queues a media file for processing again
+func (h *MediaHandler) ReprocessMedia(c *echo.Context) error {
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, "Invalid media ID format")
+	}
+
+	if err := h.mediaService.ReprocessMedia(c.Request().Context(), mediaID); err != nil {
+		h.logger.Error("failed to queue media processing", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to queue media processing")
+	}
+
+	media, err := h.mediaService.GetMediaByID(c.Request().Context(), mediaID)
+	if err != nil {
+		h.logger.Error("failed to get media", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(c.Request().Context(), c, "Media not found")
+	}
+
+	component := lib.MediaCard(h.mediaService.ToMediaResponse(media))
+	return responses.RenderSuccess(c.Request().Context(), c, component, "Media queued for processing")
+}
+
 // UpdateMedia handles updating media metadata (alt text, etc.)
 func (h *MediaHandler) UpdateMedia(c *echo.Context) error {
 	// Get media ID from URL parameter
@@ -333,23 +405,24 @@ func (h *MediaHandler) UpdateMedia(c *echo.Context) error {
 		return responses.ErrorToast(c.Request().Context(), c, "Failed to update media")
 	}
 
+	// The detail modal also files the media into a collection and tags it
+	if _, ok := c.Request().Form["tags"]; ok {
+		var collectionID pgtype.UUID
+		if id, err := uuid.Parse(c.FormValue("collection_id")); err == nil {
+			collectionID = pgtype.UUID{Bytes: id, Valid: true}
+		}
+
+		updatedMedia, err = h.mediaService.OrganizeMedia(c.Request().Context(), mediaID, collectionID, c.FormValue("tags"))
+		if err != nil {
+			h.logger.Error("failed to organize media", "error", err, "media_id", mediaUUID)
+			return responses.ErrorToast(c.Request().Context(), c, "Failed to update collection and tags")
+		}
+	}
+
 	h.logger.Info("Media updated successfully", "media_id", mediaUUID)
 
 	// Convert to response
-	mediaResponse := services.MediaResponse{
-		ID:               updatedMedia.ID,
-		Filename:         updatedMedia.Filename,
-		OriginalFilename: updatedMedia.OriginalFilename,
-		MimeType:         updatedMedia.MimeType,
-		FileSize:         updatedMedia.FileSize,
-		Width:            updatedMedia.Width,
-		Height:           updatedMedia.Height,
-		URL:              h.mediaService.GetMediaURL(updatedMedia),
-		AltText:          updatedMedia.AltText.String,
-		StorageType:      updatedMedia.StorageType,
-		CreatedAt:        updatedMedia.CreatedAt,
-		UpdatedAt:        updatedMedia.UpdatedAt,
-	}
+	mediaResponse := h.mediaService.ToMediaResponse(updatedMedia)
 
 	// Return updated media card
 	component := lib.MediaCard(mediaResponse)
@@ -360,8 +433,9 @@ func (h *MediaHandler) UpdateMedia(c *echo.Context) error {
 func (h *MediaHandler) ShowMediaSelector(c *echo.Context) error {
 	h.logger.Debug("Loading media selector")
 
-	// Fetch all media (you can add pagination/filtering later)
-	mediaList, err := h.mediaService.ListMedia(c.Request().Context(), 100, 0)
+	filters := h.mediaFilterOptions(c)
+
+	mediaList, _, err := h.mediaService.FilterMedia(c.Request().Context(), filters.Filter, 100, 0)
 	if err != nil {
 		h.logger.Error("failed to list media for selector", "error", err)
 		return c.String(500, "Failed to load media")
@@ -383,6 +457,15 @@ func (h *MediaHandler) ShowMediaSelector(c *echo.Context) error {
 	}
 
 	// Render the media grid
-	component := lib.MediaSelectorGrid(mediaResponses, dialogID, targetInputID)
+	component := lib.MediaSelectorGrid(mediaResponses, dialogID, targetInputID, filters)
 	return responses.Render(c.Request().Context(), c, component)
 }
+
+// parseMediaID reads the :id route parameter as a pgtype.UUID
+func parseMediaID(c *echo.Context) (pgtype.UUID, error) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("invalid media ID: %w", err)
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
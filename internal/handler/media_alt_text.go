@@ -0,0 +1,62 @@
+// internal/handler/media_alt_text.go
+package handler
+
+import (
+	"github.com/iankencruz/threefive/internal/middleware"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/iankencruz/threefive/templates/pages/admin"
+	"github.com/labstack/echo/v5"
+)
+
+// ShowAltTextAudit renders the published images whose alt text is missing or poor
+func (h *MediaHandler) ShowAltTextAudit(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	items, err := h.mediaService.AuditAltText(ctx)
+	if err != nil {
+		h.logger.Error("failed to audit alt text", "error", err)
+		return c.String(500, "Failed to load alt text audit")
+	}
+
+	component := admin.MediaAltTextAudit(items, c.Request().URL.Path)
+	return responses.Render(lib.WithUser(ctx, middleware.GetUser(c)), c, component)
+}
+
+// UpdateAuditedAltText saves alt text from the audit. The row is removed once
+// the alt text passes, and shown again while it still fails.
+func (h *MediaHandler) UpdateAuditedAltText(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	// Keep the row, and what was typed, when saving fails
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		c.Response().Header().Set("HX-Reswap", "none")
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+
+	item, err := h.mediaService.UpdateAuditedAltText(ctx, mediaID, c.FormValue("alt_text"))
+	if err != nil {
+		h.logger.Error("failed to update alt text", "error", err, "media_id", c.Param("id"))
+		c.Response().Header().Set("HX-Reswap", "none")
+		return responses.ErrorToast(ctx, c, "Failed to save alt text")
+	}
+
+	h.logger.Info("media alt text updated from audit", "media_id", c.Param("id"))
+	if item != nil {
+		return responses.RenderWarning(ctx, c, admin.AltTextAuditRow(*item), altTextWarning(item.Issue))
+	}
+	return responses.SuccessToast(ctx, c, "Alt text fixed")
+}
+
+// altTextWarning explains why saved alt text still fails the audit
+func altTextWarning(issue services.AltTextIssue) string {
+	switch issue {
+	case services.AltTextMissing:
+		return "Alt text cleared; the image still needs a description"
+	case services.AltTextFilename:
+		return "Alt text saved, but it still looks like a filename"
+	}
+	return "Alt text saved, but it is still too short to describe the image"
+}
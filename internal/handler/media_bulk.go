@@ -0,0 +1,193 @@
+// internal/handler/media_bulk.go
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/components/toast"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v5"
+)
+
+// selectedMediaIDs reads the media selected for a bulk operation from the
+// repeated ids field of a form or query string
+func selectedMediaIDs(c *echo.Context) ([]pgtype.UUID, error) {
+	if err := c.Request().ParseForm(); err != nil {
+		return nil, fmt.Errorf("failed to parse form: %w", err)
+	}
+
+	values := c.Request().Form["ids"]
+	ids := make([]pgtype.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid media ID %q", value)
+		}
+		ids = append(ids, pgtype.UUID{Bytes: id, Valid: true})
+	}
+	return ids, nil
+}
+
+// bulkErrorToast explains a rejected selection, or reports a failure
+func (h *MediaHandler) bulkErrorToast(c *echo.Context, err error, message string) error {
+	if errors.Is(err, services.ErrNoMediaSelected) || errors.Is(err, services.ErrTooManyMediaSelected) || errors.Is(err, services.ErrNoTagsGiven) {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+	h.logger.Error(message, "error", err)
+	return responses.ErrorToast(c.Request().Context(), c, message)
+}
+
+// elementIDs prefixes media IDs to match the elements showing them
+func elementIDs(prefix string, ids []pgtype.UUID) []string {
+	elements := make([]string, len(ids))
+	for i, id := range ids {
+		elements[i] = prefix + id.String()
+	}
+	return elements
+}
+
+// BulkDeleteMedia moves the selected media to the trash and removes their
+// cards. Media still in use is kept unless force is set.
+func (h *MediaHandler) BulkDeleteMedia(c *echo.Context) error {
+	ids, err := selectedMediaIDs(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+
+	result, err := h.mediaService.BulkDeleteMedia(c.Request().Context(), ids, c.FormValue("force") == "true")
+	if err != nil {
+		return h.bulkErrorToast(c, err, "Failed to delete media")
+	}
+
+	h.logger.Info("media deleted in bulk", "deleted", len(result.Deleted), "in_use", len(result.InUse))
+
+	component := lib.RemovedElements(elementIDs("media-", result.Deleted))
+	message := fmt.Sprintf("Moved %d items to the trash", len(result.Deleted))
+	if len(result.InUse) > 0 {
+		message += fmt.Sprintf("; kept %d that are still in use", len(result.InUse))
+		return responses.RenderWithToast(c.Request().Context(), c, component, message, toast.VariantWarning)
+	}
+	return responses.RenderSuccess(c.Request().Context(), c, component, message)
+}
+
+// BulkRestoreMedia restores the selected media from the trash and removes
+// their rows from it
+func (h *MediaHandler) BulkRestoreMedia(c *echo.Context) error {
+	ids, err := selectedMediaIDs(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+
+	restored, err := h.mediaService.BulkRestoreMedia(c.Request().Context(), ids)
+	if err != nil {
+		return h.bulkErrorToast(c, err, "Failed to restore media")
+	}
+
+	h.logger.Info("media restored in bulk", "restored", len(restored))
+
+	component := lib.RemovedElements(elementIDs("trash-", restored))
+	return responses.RenderSuccess(c.Request().Context(), c, component, fmt.Sprintf("Restored %d items to the library", len(restored)))
+}
+
+// BulkMoveMedia files the selected media into a collection. Cards leave the
+// page when they move out of the collection it shows, nested ones included.
+func (h *MediaHandler) BulkMoveMedia(c *echo.Context) error {
+	ids, err := selectedMediaIDs(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+
+	var collectionID pgtype.UUID
+	if id, err := uuid.Parse(c.FormValue("collection_id")); err == nil {
+		collectionID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	moved, err := h.mediaService.BulkMoveMedia(c.Request().Context(), ids, collectionID)
+	if err != nil {
+		return h.bulkErrorToast(c, err, "Failed to move media")
+	}
+
+	h.logger.Info("media moved in bulk", "moved", len(moved), "collection_id", collectionID.String())
+
+	var removed []string
+	if viewing, err := uuid.Parse(c.FormValue("current_collection")); err == nil {
+		collections, err := h.mediaService.ListMediaCollections(c.Request().Context())
+		if err != nil {
+			h.logger.Warn("failed to list media collections", "error", err)
+		}
+		if !services.WithinCollection(collections, pgtype.UUID{Bytes: viewing, Valid: true}, collectionID) {
+			removed = elementIDs("media-", moved)
+		}
+	}
+	return responses.RenderSuccess(c.Request().Context(), c, lib.RemovedElements(removed), fmt.Sprintf("Moved %d items", len(moved)))
+}
+
+// BulkTagMedia adds the comma-separated tags to the selected media, or
+// removes them when action is "remove"
+func (h *MediaHandler) BulkTagMedia(c *echo.Context) error {
+	ids, err := selectedMediaIDs(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+
+	remove := c.FormValue("action") == "remove"
+	tagged, err := h.mediaService.BulkTagMedia(c.Request().Context(), ids, c.FormValue("tags"), remove)
+	if err != nil {
+		return h.bulkErrorToast(c, err, "Failed to update tags")
+	}
+
+	h.logger.Info("media tagged in bulk", "tagged", tagged, "remove", remove)
+
+	if remove {
+		return responses.SuccessToast(c.Request().Context(), c, fmt.Sprintf("Removed tags from %d items", tagged))
+	}
+	return responses.SuccessToast(c.Request().Context(), c, fmt.Sprintf("Tagged %d items", tagged))
+}
+
+// ShowBulkAltText returns a dialog editing the alt text of the selected media
+func (h *MediaHandler) ShowBulkAltText(c *echo.Context) error {
+	ids, err := selectedMediaIDs(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+
+	media, err := h.mediaService.GetMediaByIDs(c.Request().Context(), ids)
+	if err != nil {
+		return h.bulkErrorToast(c, err, "Failed to load media")
+	}
+
+	component := lib.MediaBulkAltTextDialog(h.mediaService.ToMediaResponses(media))
+	return responses.Render(c.Request().Context(), c, component)
+}
+
+// BulkUpdateAltText saves the alt text dialog. The form repeats ids and
+// alt_text in the same order.
+func (h *MediaHandler) BulkUpdateAltText(c *echo.Context) error {
+	ids, err := selectedMediaIDs(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, err.Error())
+	}
+
+	altTexts := c.Request().Form["alt_text"]
+	if len(altTexts) != len(ids) {
+		return responses.ErrorToast(c.Request().Context(), c, "Alt text does not match the selected media")
+	}
+
+	updates := make([]services.AltTextUpdate, len(ids))
+	for i, id := range ids {
+		updates[i] = services.AltTextUpdate{ID: id, AltText: altTexts[i]}
+	}
+
+	updated, err := h.mediaService.BulkUpdateAltText(c.Request().Context(), updates)
+	if err != nil {
+		return h.bulkErrorToast(c, err, "Failed to save alt text")
+	}
+
+	h.logger.Info("media alt text updated in bulk", "updated", updated)
+	return responses.SuccessToast(c.Request().Context(), c, fmt.Sprintf("Saved alt text for %d items", updated))
+}
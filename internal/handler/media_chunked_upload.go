@@ -0,0 +1,185 @@
+// internal/handler/media_chunked_upload.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/internal/middleware"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/labstack/echo/v5"
+)
+
+// Resumable uploads follow the shape of tus: create the upload, PATCH chunks
+// with the Upload-Offset they start at, and GET the upload after a failure to
+// learn where to resume. A final POST turns the received file into media.
+const chunkContentType = "application/offset+octet-stream"
+
+// BeginChunkedUpload reserves a resumable upload. It is called with fetch, so
+// it answers in JSON.
+func (h *MediaHandler) BeginChunkedUpload(c *echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}
+
+	var req services.UploadRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid upload request"})
+	}
+
+	upload, err := h.mediaService.BeginChunkedUpload(c.Request().Context(), req, user.ID)
+	if err != nil {
+		h.logger.Error("failed to begin chunked upload", "error", err, "filename", req.Filename)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	h.logger.Info("chunked upload started",
+		"upload_id", upload.ID,
+		"filename", req.Filename,
+		"size", req.Size,
+		"user_id", user.ID,
+	)
+
+	setUploadOffset(c, upload)
+	return c.JSON(http.StatusCreated, upload)
+}
+
+// GetChunkedUpload reports how many bytes of an upload have been received
+func (h *MediaHandler) GetChunkedUpload(c *echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": services.ErrUploadNotFound.Error()})
+	}
+
+	upload, err := h.mediaService.GetChunkedUpload(c.Request().Context(), id, user.ID)
+	if err != nil {
+		return h.chunkedUploadError(c, err, upload)
+	}
+
+	setUploadOffset(c, upload)
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, upload)
+}
+
+// UploadChunk appends the request body to an upload. The body must start at
+// the Upload-Offset the client sends, which must match what has been received.
+func (h *MediaHandler) UploadChunk(c *echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": services.ErrUploadNotFound.Error()})
+	}
+
+	if c.Request().Header.Get("Content-Type") != chunkContentType {
+		return c.JSON(http.StatusUnsupportedMediaType, map[string]string{"error": "Chunks must be sent as " + chunkContentType})
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid Upload-Offset header"})
+	}
+
+	upload, err := h.mediaService.WriteUploadChunk(c.Request().Context(), id, user.ID, offset, c.Request().Body)
+	if err != nil {
+		return h.chunkedUploadError(c, err, upload)
+	}
+
+	setUploadOffset(c, upload)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CancelChunkedUpload discards an upload and the data received so far
+func (h *MediaHandler) CancelChunkedUpload(c *echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": services.ErrUploadNotFound.Error()})
+	}
+
+	if err := h.mediaService.CancelChunkedUpload(c.Request().Context(), id, user.ID); err != nil {
+		return h.chunkedUploadError(c, err, nil)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CompleteChunkedUpload adds a fully received upload to the library. It is
+// called through htmx and responds like UploadMedia.
+func (h *MediaHandler) CompleteChunkedUpload(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		return responses.ErrorToast(ctx, c, "Authentication required")
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid upload ID")
+	}
+
+	media, err := h.mediaService.CompleteChunkedUpload(ctx, id, user.ID, c.FormValue("alt_text"), uploadOptions(c))
+	if err != nil {
+		var dup *services.DuplicateMediaError
+		if errors.As(err, &dup) {
+			return h.renderDuplicateMedia(c, dup)
+		}
+		if errors.Is(err, services.ErrUploadNotFound) {
+			return responses.ErrorToast(ctx, c, "Upload not found or expired, please try again")
+		}
+		h.logger.Error("failed to complete chunked upload", "error", err, "upload_id", id)
+		return responses.ErrorToast(ctx, c, err.Error())
+	}
+
+	h.logger.Info("media uploaded successfully",
+		"media_id", media.ID,
+		"filename", media.Filename,
+		"user_id", user.ID,
+		"chunked", true,
+	)
+
+	return h.renderUploadedMedia(c, media)
+}
+
+// chunkedUploadError maps service errors to statuses the upload script acts
+// on: 409 means resume from the returned offset, 404 means start over
+func (h *MediaHandler) chunkedUploadError(c *echo.Context, err error, upload *services.ChunkedUpload) error {
+	if upload != nil {
+		setUploadOffset(c, upload)
+	}
+
+	switch {
+	case errors.Is(err, services.ErrUploadNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadOffsetMismatch):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadBusy):
+		return c.JSON(http.StatusLocked, map[string]string{"error": err.Error()})
+	}
+
+	h.logger.Error("chunked upload failed", "error", err, "upload_id", c.Param("id"))
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save upload"})
+}
+
+func setUploadOffset(c *echo.Context, upload *services.ChunkedUpload) {
+	header := c.Response().Header()
+	header.Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	header.Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+}
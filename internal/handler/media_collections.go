@@ -0,0 +1,90 @@
+// internal/handler/media_collections.go
+package handler
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v5"
+)
+
+// mediaFilterOptions reads the library filter from the query string and
+// loads the collections, tags and uploaders to choose from. Lookups that
+// fail leave their choices empty.
+func (h *MediaHandler) mediaFilterOptions(c *echo.Context) lib.MediaFilterOptions {
+	ctx := c.Request().Context()
+	opts := lib.MediaFilterOptions{Filter: services.ParseMediaFilter(c.QueryParams())}
+
+	var err error
+	if opts.Collections, err = h.mediaService.ListMediaCollections(ctx); err != nil {
+		h.logger.Error("failed to list media collections", "error", err)
+	}
+	if opts.Tags, err = h.mediaService.ListMediaTagsInUse(ctx); err != nil {
+		h.logger.Error("failed to list media tags", "error", err)
+	}
+	if opts.Uploaders, err = h.mediaService.ListMediaUploaders(ctx); err != nil {
+		h.logger.Error("failed to list media uploaders", "error", err)
+	}
+	return opts
+}
+
+// CreateMediaCollection adds a collection and opens it
+func (h *MediaHandler) CreateMediaCollection(c *echo.Context) error {
+	var parentID pgtype.UUID
+	if id, err := uuid.Parse(c.FormValue("parent_id")); err == nil {
+		parentID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	collection, err := h.mediaService.CreateMediaCollection(c.Request().Context(), c.FormValue("name"), parentID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaCollectionExists), errors.Is(err, services.ErrMediaCollectionNotFound):
+			return responses.ErrorToast(c.Request().Context(), c, err.Error())
+		}
+		h.logger.Error("failed to create media collection", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to create collection")
+	}
+
+	h.logger.Info("Media collection created", "collection_id", collection.ID.String(), "name", collection.Name)
+	return responses.HTMXRedirect(c, "/admin/media?collection="+collection.ID.String())
+}
+
+// RenameMediaCollection renames the collection being viewed
+func (h *MediaHandler) RenameMediaCollection(c *echo.Context) error {
+	id, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, "Invalid collection ID")
+	}
+
+	collection, err := h.mediaService.RenameMediaCollection(c.Request().Context(), id, c.FormValue("name"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaCollectionExists), errors.Is(err, services.ErrMediaCollectionNotFound):
+			return responses.ErrorToast(c.Request().Context(), c, err.Error())
+		}
+		h.logger.Error("failed to rename media collection", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to rename collection")
+	}
+
+	return responses.HTMXRedirect(c, "/admin/media?collection="+collection.ID.String())
+}
+
+// DeleteMediaCollection deletes a collection and returns to the library root
+func (h *MediaHandler) DeleteMediaCollection(c *echo.Context) error {
+	id, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(c.Request().Context(), c, "Invalid collection ID")
+	}
+
+	if err := h.mediaService.DeleteMediaCollection(c.Request().Context(), id); err != nil {
+		h.logger.Error("failed to delete media collection", "error", err)
+		return responses.ErrorToast(c.Request().Context(), c, "Failed to delete collection")
+	}
+
+	h.logger.Info("Media collection deleted", "collection_id", id.String())
+	return responses.HTMXRedirect(c, "/admin/media")
+}
@@ -0,0 +1,50 @@
+// internal/handler/media_crops.go
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/labstack/echo/v5"
+)
+
+// SetMediaFocalPoint saves where the subject of an image is, or resets it to
+// the centre, and returns its card while the crops are cut again
+func (h *MediaHandler) SetMediaFocalPoint(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+
+	var focal *services.FocalPoint
+	if c.FormValue("reset") != "true" {
+		x, errX := strconv.ParseFloat(c.FormValue("focal_x"), 64)
+		y, errY := strconv.ParseFloat(c.FormValue("focal_y"), 64)
+		if errX != nil || errY != nil {
+			return responses.ErrorToast(ctx, c, "Click the image to choose a focal point")
+		}
+		focal = &services.FocalPoint{X: x, Y: y}
+	}
+
+	media, err := h.mediaService.SetFocalPoint(ctx, mediaID, focal)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrMediaProcessing):
+			return responses.ErrorToast(ctx, c, "Wait for processing to finish before changing the focal point")
+		case errors.Is(err, services.ErrInvalidFocalPoint), errors.Is(err, services.ErrNotCroppable):
+			return responses.ErrorToast(ctx, c, err.Error())
+		}
+		h.logger.Error("failed to set focal point", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(ctx, c, "Failed to save focal point")
+	}
+
+	h.logger.Info("Media focal point set", "media_id", mediaID.String(), "reset", focal == nil)
+
+	component := lib.MediaCard(h.mediaService.ToMediaResponse(media))
+	return responses.RenderSuccess(ctx, c, component, "Focal point saved; the crops will update shortly")
+}
@@ -0,0 +1,133 @@
+// internal/handler/media_direct_upload.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/internal/middleware"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v5"
+)
+
+// BeginDirectUpload returns presigned requests the browser uses to upload a
+// file straight to S3. It is called with fetch, so it answers in JSON.
+func (h *MediaHandler) BeginDirectUpload(c *echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}
+
+	if !h.mediaService.SupportsDirectUpload() {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Direct uploads are not available"})
+	}
+
+	var req services.UploadRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid upload request"})
+	}
+
+	upload, err := h.mediaService.BeginDirectUpload(c.Request().Context(), req, user.ID)
+	if err != nil {
+		h.logger.Error("failed to begin direct upload", "error", err, "filename", req.Filename)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	h.logger.Info("direct upload started",
+		"upload_id", upload.ID,
+		"filename", req.Filename,
+		"size", req.Size,
+		"parts", len(upload.Parts),
+		"user_id", user.ID,
+	)
+
+	return c.JSON(http.StatusOK, upload)
+}
+
+// CompleteDirectUpload verifies a file the browser uploaded to S3 and adds it
+// to the library. It is called through htmx and responds like UploadMedia.
+func (h *MediaHandler) CompleteDirectUpload(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	user := middleware.GetUser(c)
+	if user == nil {
+		h.logger.Error("user not found in context")
+		return responses.ErrorToast(ctx, c, "Authentication required")
+	}
+
+	uploadUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid upload ID")
+	}
+
+	// ETags the browser collected for each part; empty for a single PUT
+	var parts []services.CompletedPart
+	if raw := c.FormValue("parts"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &parts); err != nil {
+			return responses.ErrorToast(ctx, c, "Invalid upload parts")
+		}
+	}
+
+	media, err := h.mediaService.FinalizeDirectUpload(ctx,
+		pgtype.UUID{Bytes: uploadUUID, Valid: true},
+		parts,
+		c.FormValue("alt_text"),
+		user.ID,
+		uploadOptions(c),
+	)
+	if err != nil {
+		var dup *services.DuplicateMediaError
+		if errors.As(err, &dup) {
+			return h.renderDuplicateMedia(c, dup)
+		}
+		if errors.Is(err, services.ErrUploadNotFound) {
+			return responses.ErrorToast(ctx, c, "Upload not found or expired, please try again")
+		}
+		if errors.Is(err, services.ErrUploadInProgress) {
+			return responses.ErrorToast(ctx, c, "This upload is already being saved")
+		}
+		h.logger.Error("failed to finalize direct upload", "error", err, "upload_id", uploadUUID)
+		return responses.ErrorToast(ctx, c, err.Error())
+	}
+
+	h.logger.Info("media uploaded successfully",
+		"media_id", media.ID,
+		"filename", media.Filename,
+		"user_id", user.ID,
+		"direct", true,
+	)
+
+	return h.renderUploadedMedia(c, media)
+}
+
+// CancelDirectUpload discards an upload that will not be finalized, e.g. a
+// duplicate the uploader chose not to keep. It is called with fetch.
+func (h *MediaHandler) CancelDirectUpload(c *echo.Context) error {
+	user := middleware.GetUser(c)
+	if user == nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authentication required"})
+	}
+
+	uploadUUID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid upload ID"})
+	}
+
+	err = h.mediaService.CancelDirectUpload(c.Request().Context(), pgtype.UUID{Bytes: uploadUUID, Valid: true}, user.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrUploadNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, services.ErrUploadInProgress) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		h.logger.Error("failed to cancel direct upload", "error", err, "upload_id", uploadUUID)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to cancel upload"})
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,140 @@
+// internal/handler/media_files.go
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/labstack/echo/v5"
+)
+
+// mediaFileCSP stops a stored file (e.g. an SVG opened directly) from running
+// scripts or loading anything in the site's origin
+const mediaFileCSP = "default-src 'none'; img-src data:; style-src 'unsafe-inline'; sandbox"
+
+// MediaFileHandler serves files from local storage. S3 media is served by the
+// bucket or its CDN and never reaches this handler.
+type MediaFileHandler struct {
+	storage *services.LocalStorage
+	logger  *slog.Logger
+}
+
+func NewMediaFileHandler(storage *services.LocalStorage, logger *slog.Logger) *MediaFileHandler {
+	return &MediaFileHandler{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// ServeFile streams a stored file with Range (video seeking), conditional
+// request and caching support
+func (h *MediaFileHandler) ServeFile(c *echo.Context) error {
+	key := c.Param("*")
+	if !isServableKey(key) {
+		return c.String(http.StatusNotFound, "File not found")
+	}
+
+	// Private media needs a signed URL; a bad or expired one looks like a
+	// missing file rather than confirming the key exists
+	var expiresAt time.Time
+	if services.IsPrivateKey(key) {
+		var ok bool
+		query := c.Request().URL.Query()
+		expiresAt, ok = h.storage.VerifySignature(key, query.Get("expires"), query.Get("signature"))
+		if !ok {
+			return c.String(http.StatusNotFound, "File not found")
+		}
+	}
+
+	ctx := c.Request().Context()
+	info, err := h.storage.Stat(ctx, key)
+	if err != nil {
+		if errors.Is(err, services.ErrObjectNotFound) {
+			return c.String(http.StatusNotFound, "File not found")
+		}
+		h.logger.Error("failed to stat media file", "error", err, "key", key)
+		return c.String(http.StatusInternalServerError, "Failed to read file")
+	}
+
+	f, err := h.storage.Open(ctx, key)
+	if err != nil {
+		h.logger.Error("failed to open media file", "error", err, "key", key)
+		return c.String(http.StatusInternalServerError, "Failed to read file")
+	}
+	defer f.Close()
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		h.logger.Error("media file is not seekable", "key", key)
+		return c.String(http.StatusInternalServerError, "Failed to read file")
+	}
+
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := c.Response().Header()
+	header.Set("Content-Type", contentType)
+	header.Set("ETag", mediaETag(info))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", mediaFileCSP)
+	if expiresAt.IsZero() {
+		header.Set("Cache-Control", mediaCacheControl(key))
+	} else {
+		header.Set("Cache-Control", privateCacheControl(expiresAt))
+		header.Set("X-Robots-Tag", "noindex, nofollow")
+	}
+
+	// ServeContent handles Range, If-Range, If-None-Match and HEAD
+	http.ServeContent(c.Response(), c.Request(), "", info.LastModified, content)
+	return nil
+}
+
+// isServableKey rejects traversal and hidden entries (metadata sidecars,
+// in-flight uploads) before the key reaches storage
+func isServableKey(key string) bool {
+	if key == "" || strings.ContainsRune(key, '\\') || strings.ContainsRune(key, 0) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// mediaETag is a strong validator: files are written to a temp file and
+// renamed into place, so a key's size and modification time change whenever
+// its bytes do
+func mediaETag(info *services.ObjectInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.LastModified.UnixNano(), info.Size)
+}
+
+// mediaMaxAge is how long caches may reuse uploaded media before
+// revalidating it. It is kept short because a key's content is not fixed:
+// making media private moves it away from its public key, and renditions and
+// crops are rewritten in place when they are regenerated.
+const mediaMaxAge = 5 * time.Minute
+
+// mediaCacheControl lets uploaded media under media/ be reused for
+// mediaMaxAge. Anything else is revalidated against its ETag on every request.
+func mediaCacheControl(key string) string {
+	if strings.HasPrefix(key, "media/") {
+		return fmt.Sprintf("public, max-age=%d", int(mediaMaxAge.Seconds()))
+	}
+	return "public, no-cache"
+}
+
+// privateCacheControl keeps signed responses out of shared caches and stops
+// browsers reusing them after the URL expires
+func privateCacheControl(expiresAt time.Time) string {
+	return fmt.Sprintf("private, max-age=%d", int(time.Until(expiresAt).Seconds()))
+}
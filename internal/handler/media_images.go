@@ -0,0 +1,60 @@
+// internal/handler/media_images.go
+package handler
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/labstack/echo/v5"
+)
+
+// ServeImage serves a resized copy of a public image, e.g.
+// /img/:id?w=800&h=600&fit=cover&fm=webp&q=75. Each variant is generated on
+// first request and served from storage afterwards.
+func (h *MediaHandler) ServeImage(c *echo.Context) error {
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return c.String(http.StatusNotFound, "Image not found")
+	}
+
+	transform, err := services.ParseImageTransform(c.QueryParams())
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	image, err := h.mediaService.TransformImage(c.Request().Context(), mediaID, transform)
+	if err != nil {
+		if errors.Is(err, services.ErrNotTransformable) {
+			return c.String(http.StatusNotFound, "Image not found")
+		}
+		h.logger.Error("failed to transform image", "error", err, "media_id", c.Param("id"), "query", c.QueryString())
+		return c.String(http.StatusInternalServerError, "Failed to process image")
+	}
+	defer image.Body.Close()
+
+	// A derived image's key names everything that went into it, so its
+	// bytes never change. The URL can still stop being served once the image
+	// is trashed, made private or replaced, so caches keep it only for
+	// mediaMaxAge before revalidating against the ETag.
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(image.Key)))
+	header := c.Response().Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(mediaMaxAge.Seconds())))
+	header.Set("ETag", etag)
+	header.Set("X-Content-Type-Options", "nosniff")
+	if c.Request().Header.Get("If-None-Match") == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	header.Set("Content-Type", image.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(image.Size, 10))
+	c.Response().WriteHeader(http.StatusOK)
+	if _, err := io.Copy(c.Response(), image.Body); err != nil {
+		h.logger.Warn("failed to send image", "error", err, "key", image.Key)
+	}
+	return nil
+}
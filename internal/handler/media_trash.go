@@ -0,0 +1,93 @@
+// internal/handler/media_trash.go
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/iankencruz/threefive/internal/middleware"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/iankencruz/threefive/templates/pages/admin"
+	"github.com/labstack/echo/v5"
+)
+
+// ShowMediaTrash renders the soft-deleted media waiting to be purged
+func (h *MediaHandler) ShowMediaTrash(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	page := 1
+	if p := c.QueryParam("page"); p != "" {
+		if parsed, err := strconv.Atoi(p); err == nil && parsed > 0 {
+			page = parsed
+		}
+	}
+	limit := int32(20)
+	offset := int32((page - 1) * int(limit))
+
+	mediaList, err := h.mediaService.ListDeletedMedia(ctx, limit, offset)
+	if err != nil {
+		h.logger.Error("failed to list deleted media", "error", err)
+		return c.String(500, "Failed to load trash")
+	}
+
+	totalCount, err := h.mediaService.CountDeletedMedia(ctx)
+	if err != nil {
+		h.logger.Error("failed to count deleted media", "error", err)
+		totalCount = 0
+	}
+	totalPages := int((totalCount + int64(limit) - 1) / int64(limit))
+
+	component := admin.MediaTrash(admin.MediaTrashProps{
+		Media:         h.mediaService.ToMediaResponses(mediaList),
+		CurrentPage:   page,
+		TotalPages:    totalPages,
+		RetentionDays: int(services.MediaTrashRetention.Hours() / 24),
+	}, c.Request().URL.Path)
+
+	return responses.Render(lib.WithUser(ctx, middleware.GetUser(c)), c, component)
+}
+
+// RestoreMedia moves media out of the trash; its row in the trash is removed
+func (h *MediaHandler) RestoreMedia(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+
+	if err := h.mediaService.RestoreMedia(ctx, mediaID); err != nil {
+		h.logger.Error("failed to restore media", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(ctx, c, "Failed to restore media")
+	}
+
+	h.logger.Info("media restored", "media_id", c.Param("id"))
+	return responses.SuccessToast(ctx, c, "Media restored to the library")
+}
+
+// PurgeMedia permanently deletes media from the trash, including its files
+func (h *MediaHandler) PurgeMedia(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+
+	err = h.mediaService.DeleteTrashedMedia(ctx, mediaID)
+	switch {
+	case errors.Is(err, services.ErrMediaNotInTrash):
+		return responses.ErrorToast(ctx, c, "Move the media to the trash before deleting it permanently")
+	case errors.Is(err, services.ErrMediaFilesRemain):
+		// The media is gone; leftover files are reaped by the storage check
+		h.logger.Warn("media deleted but files remain", "error", err, "media_id", c.Param("id"))
+	case err != nil:
+		h.logger.Error("failed to permanently delete media", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(ctx, c, "Failed to delete media")
+	}
+
+	h.logger.Info("media permanently deleted", "media_id", c.Param("id"))
+	return responses.SuccessToast(ctx, c, "Media permanently deleted")
+}
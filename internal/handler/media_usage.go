@@ -0,0 +1,72 @@
+// internal/handler/media_usage.go
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/labstack/echo/v5"
+)
+
+// ReplaceMedia swaps the media for the chosen replacement everywhere content
+// uses it and re-renders the now empty usage list
+func (h *MediaHandler) ReplaceMedia(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+	replacementUUID, err := uuid.Parse(c.FormValue("replacement_id"))
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Choose the media to replace it with")
+	}
+
+	replaced, err := h.mediaService.ReplaceMedia(ctx, mediaID, pgtype.UUID{Bytes: replacementUUID, Valid: true})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidReplacement) {
+			return responses.ErrorToast(ctx, c, "Choose different media to replace it with")
+		}
+		if errors.Is(err, services.ErrReplacementKindMismatch) {
+			return responses.ErrorToast(ctx, c, "Pages show this media as an image; choose media of the same kind to replace it with")
+		}
+		h.logger.Error("failed to replace media", "error", err, "media_id", c.Param("id"), "replacement_id", replacementUUID)
+		return responses.ErrorToast(ctx, c, "Failed to replace media")
+	}
+
+	h.logger.Info("media replaced", "media_id", c.Param("id"), "replacement_id", replacementUUID, "content_items", replaced)
+
+	references, err := h.mediaService.GetMediaReferences(ctx, mediaID)
+	if err != nil {
+		h.logger.Error("failed to get media references", "error", err, "media_id", c.Param("id"))
+	}
+
+	component := lib.MediaUsage(c.Param("id"), references)
+	message := fmt.Sprintf("Replaced in %d projects, posts and pages", replaced)
+	if replaced == 1 {
+		message = "Replaced in 1 project, post or page"
+	}
+	return responses.RenderSuccess(ctx, c, component, message)
+}
+
+// privateEmbedWarning warns that a saved markdown body embeds private media,
+// which its public page leaves out. It is empty when there is none.
+func privateEmbedWarning(ctx context.Context, mediaService *services.MediaService, body string) string {
+	media, err := mediaService.PrivateEmbeddedMedia(ctx, body)
+	if err != nil || len(media) == 0 {
+		return ""
+	}
+
+	names := make([]string, len(media))
+	for i := range media {
+		names[i] = media[i].OriginalFilename
+	}
+	return fmt.Sprintf("Saved, but private media is hidden on the public page: %s", strings.Join(names, ", "))
+}
@@ -0,0 +1,77 @@
+// internal/handler/media_visibility.go
+package handler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/iankencruz/threefive/internal/services"
+	"github.com/iankencruz/threefive/pkg/responses"
+	"github.com/iankencruz/threefive/templates/lib"
+	"github.com/labstack/echo/v5"
+)
+
+// SetMediaVisibility makes a media item private or public and re-renders its card
+func (h *MediaHandler) SetMediaVisibility(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+
+	private := c.FormValue("private") == "true"
+	media, err := h.mediaService.SetMediaVisibility(ctx, mediaID, private)
+	if err != nil {
+		if errors.Is(err, services.ErrMediaProcessing) {
+			return responses.ErrorToast(ctx, c, "Wait for processing to finish before changing visibility")
+		}
+		h.logger.Error("failed to set media visibility", "error", err, "media_id", c.Param("id"), "private", private)
+		if media == nil {
+			return responses.ErrorToast(ctx, c, "Failed to change visibility")
+		}
+		// The change went through, but old files are still stored
+		component := lib.MediaCard(h.mediaService.ToMediaResponse(media))
+		return responses.RenderWarning(ctx, c, component, "Visibility changed, but some previous files could not be removed")
+	}
+
+	message := "Media is now public"
+	if media.IsPrivate {
+		message = "Media is now private"
+	}
+	component := lib.MediaCard(h.mediaService.ToMediaResponse(media))
+	return responses.RenderSuccess(ctx, c, component, message)
+}
+
+// ShareMedia creates a link to a media file that expires, for sending
+// private media to people without an account
+func (h *MediaHandler) ShareMedia(c *echo.Context) error {
+	ctx := c.Request().Context()
+
+	mediaID, err := parseMediaID(c)
+	if err != nil {
+		return responses.ErrorToast(ctx, c, "Invalid media ID format")
+	}
+
+	expires, err := time.ParseDuration(c.FormValue("expires"))
+	if err != nil || expires <= 0 || expires > services.MaxShareExpiry {
+		return responses.ErrorToast(ctx, c, "Choose how long the link should work")
+	}
+
+	media, err := h.mediaService.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		h.logger.Error("failed to get media", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(ctx, c, "Media not found")
+	}
+
+	url, err := h.mediaService.ShareMediaURL(media, expires)
+	if err != nil {
+		h.logger.Error("failed to create share link", "error", err, "media_id", c.Param("id"))
+		return responses.ErrorToast(ctx, c, "Failed to create share link")
+	}
+
+	h.logger.Info("media share link created", "media_id", c.Param("id"), "expires_in", expires)
+
+	component := lib.MediaShareLink(media.ID.String(), url, time.Now().Add(expires))
+	return responses.Render(ctx, c, component)
+}
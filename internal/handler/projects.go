@@ -2,6 +2,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -14,6 +15,7 @@ import (
 	"github.com/iankencruz/threefive/internal/services"
 	"github.com/iankencruz/threefive/pkg/responses"
 	"github.com/iankencruz/threefive/pkg/validation"
+	"github.com/iankencruz/threefive/templates/components/toast"
 	"github.com/iankencruz/threefive/templates/lib"
 	"github.com/iankencruz/threefive/templates/pages"
 	"github.com/iankencruz/threefive/templates/pages/admin"
@@ -121,6 +123,7 @@ func (h *ProjectHandler) CreateProject(c *echo.Context) error {
 	title := c.FormValue("title")
 	slug := c.FormValue("slug")
 	description := c.FormValue("description")
+	body := c.FormValue("body")
 	clientName := c.FormValue("client_name")
 	projectURL := c.FormValue("project_url")
 	status := c.FormValue("status")
@@ -192,6 +195,7 @@ func (h *ProjectHandler) CreateProject(c *echo.Context) error {
 		Title:           title,
 		Slug:            slug,
 		Description:     description,
+		Body:            body,
 		ProjectDate:     projectDate,
 		ClientName:      clientName,
 		ProjectYear:     projectYear,
@@ -224,13 +228,17 @@ func (h *ProjectHandler) CreateProject(c *echo.Context) error {
 		return responses.RenderError(c.Request().Context(), c, component, err.Error())
 	}
 
-	// Success - redirect to edit page
+	// Success - redirect to edit page, warning about media the public page hides
+	message, variant := "Project created successfully", toast.VariantSuccess
+	if warning := privateEmbedWarning(c.Request().Context(), h.mediaService, req.Body); warning != "" {
+		message, variant = warning, toast.VariantWarning
+	}
 	return responses.RedirectWithToast(
 		c.Request().Context(),
 		c,
 		"/admin/projects/"+project.Project.Slug,
-		"Project created successfully",
-		"success",
+		message,
+		variant,
 	)
 }
 
@@ -287,18 +295,20 @@ func (h *ProjectHandler) UpdateProject(c *echo.Context) error {
 
 	// Build update request
 	req := &services.UpdateProjectRequest{
-		Title:           c.FormValue("title"),
-		Slug:            c.FormValue("slug"),
-		Description:     c.FormValue("description"),
-		ClientName:      c.FormValue("client_name"),
-		ProjectURL:      c.FormValue("project_url"),
-		Status:          c.FormValue("status"),
-		ProjectStatus:   c.FormValue("project_status"),
-		ProjectYear:     c.FormValue("project_year"),
-		ProjectDate:     c.FormValue("project_date"),
-		FeaturedImageID: c.FormValue("featured_image_id"),
-		GalleryMediaIDs: c.FormValue("gallery_media_ids"),
-		Tags:            c.FormValue("tags"),
+		Title:            c.FormValue("title"),
+		Slug:             c.FormValue("slug"),
+		Description:      c.FormValue("description"),
+		Body:             c.FormValue("body"),
+		ClientName:       c.FormValue("client_name"),
+		ProjectURL:       c.FormValue("project_url"),
+		Status:           c.FormValue("status"),
+		ProjectStatus:    c.FormValue("project_status"),
+		ProjectYear:      c.FormValue("project_year"),
+		ProjectDate:      c.FormValue("project_date"),
+		FeaturedImageID:  c.FormValue("featured_image_id"),
+		GalleryMediaIDs:  c.FormValue("gallery_media_ids"),
+		DocumentMediaIDs: c.FormValue("document_media_ids"),
+		Tags:             c.FormValue("tags"),
 	}
 
 	fieldErrors, err := req.Validate()
@@ -330,6 +340,12 @@ func (h *ProjectHandler) UpdateProject(c *echo.Context) error {
 			return responses.RenderError(ctx, c, component, err.Error())
 		}
 
+		if errors.Is(err, services.ErrInvalidDocument) {
+			fieldErrors := validation.FieldErrors{"document_media_ids": err.Error()}
+			component := admin.ProjectEditForm(existing, tags, existingSEO, fieldErrors)
+			return responses.RenderError(ctx, c, component, err.Error())
+		}
+
 		// Generic database error
 		dbErrors := validation.FieldErrors{"general": err.Error()}
 		component := admin.ProjectEditForm(existing, tags, existingSEO, dbErrors)
@@ -358,14 +374,20 @@ func (h *ProjectHandler) UpdateProject(c *echo.Context) error {
 	}
 
 	// SUCCESS - Check if slug changed
+	warning := privateEmbedWarning(c.Request().Context(), h.mediaService, req.Body)
+
 	if updated.Project.Slug != slug {
 		// Slug changed - MUST redirect to new URL
+		message, variant := "Project updated successfully (URL changed)", toast.VariantSuccess
+		if warning != "" {
+			message, variant = warning, toast.VariantWarning
+		}
 		return responses.RedirectWithToast(
 			c.Request().Context(),
 			c,
 			"/admin/projects/"+updated.Project.Slug,
-			"Project updated successfully (URL changed)",
-			"success",
+			message,
+			variant,
 		)
 	}
 
@@ -375,6 +397,9 @@ func (h *ProjectHandler) UpdateProject(c *echo.Context) error {
 	ctx := lib.WithUser(c.Request().Context(), middleware.GetUser(c))
 
 	component := admin.ProjectEditForm(updated, tags, freshSEO, nil)
+	if warning != "" {
+		return responses.RenderWithToast(ctx, c, component, warning, toast.VariantWarning)
+	}
 	return responses.RenderSuccess(ctx, c, component, "Project updated successfully")
 }
 
@@ -485,6 +510,21 @@ func (h *ProjectHandler) ShowGallerySelector(c *echo.Context) error {
 	return responses.Render(c.Request().Context(), c, component)
 }
 
+// ShowDocumentSelector loads the PDFs that can be attached as case-study documents
+func (h *ProjectHandler) ShowDocumentSelector(c *echo.Context) error {
+	listID := c.QueryParam("document_list_id")
+	inputID := c.QueryParam("document_input_id")
+
+	media, err := h.mediaService.ListMediaByType(c.Request().Context(), "application/pdf", 100, 0)
+	if err != nil {
+		h.logger.Error("failed to list documents", "error", err)
+		media = []generated.Media{}
+	}
+
+	component := lib.DocumentMediaGrid(h.mediaService.ToMediaResponses(media), listID, inputID)
+	return responses.Render(c.Request().Context(), c, component)
+}
+
 // Public Handlers
 
 // ShowPublicProjectsList renders the public projects listing
@@ -512,8 +552,17 @@ func (h *ProjectHandler) ShowPublicProject(c *echo.Context) error {
 	if project.Project.Status.Valid && project.Project.Status.String != "published" {
 		return c.String(404, "Project not found")
 	}
+	if err := h.projectService.RenderBody(c.Request().Context(), project); err != nil {
+		h.logger.Error("failed to render project body", "error", err, "slug", slug)
+	}
+
+	var ogImage string
+	if project.FeaturedImage != nil && services.IsImage(project.FeaturedImage.MimeType) {
+		ogImage = project.FeaturedImage.OGURL
+	}
+
 	seo, _ := h.seoService.GetSEO(c.Request().Context(), "project", project.Project.ID.Bytes)
-	seoData := services.ToSEOData(seo, project.Project.Title, fullURL(c, "/projects/"+project.Project.Slug), "ThreeFive", "")
+	seoData := services.ToSEOData(seo, project.Project.Title, fullURL(c, "/projects/"+project.Project.Slug), "ThreeFive", ogImage)
 
 	component := pages.ProjectDetails(project, seoData)
 	return responses.Render(c.Request().Context(), c, component)
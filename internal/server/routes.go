@@ -1,8 +1,11 @@
 package server
 
 import (
+	"strings"
+
 	"github.com/iankencruz/threefive/internal/handler"
 	mw "github.com/iankencruz/threefive/internal/middleware"
+	"github.com/iankencruz/threefive/internal/services"
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
 	"golang.org/x/time/rate"
@@ -19,6 +22,7 @@ func (s *Server) RegisterRoutes() {
 	mediaHandler := handler.NewMediaHandler(s.MediaService, s.Log)
 	pageHandler := handler.NewPageHandler(s.Log, s.PageService, s.ProjectService, s.MediaService, s.SeoService)
 	projectHandler := handler.NewProjectHandler(s.Log, s.ProjectService, s.TagService, s.MediaService, s.SeoService)
+	blogHandler := handler.NewBlogHandler(s.Log, s.BlogService, s.TagService, s.MediaService, s.SeoService)
 	tagHandler := handler.NewTagHandler(s.Log, s.TagService)
 	contactHandler := handler.NewContactHandler(s.Log, s.ContactService)
 
@@ -32,6 +36,13 @@ func (s *Server) RegisterRoutes() {
 	// handlers
 	s.Echo.GET("/health", s.healthCheckHandler)
 
+	// Locally stored media; a non-path base URL means something else serves it
+	if local, ok := s.Storage.(*services.LocalStorage); ok && strings.HasPrefix(local.BaseURL(), "/") {
+		mediaFileHandler := handler.NewMediaFileHandler(local, s.Log)
+		s.Echo.GET(strings.TrimSuffix(local.BaseURL(), "/")+"/*", mediaFileHandler.ServeFile)
+		s.Echo.HEAD(strings.TrimSuffix(local.BaseURL(), "/")+"/*", mediaFileHandler.ServeFile)
+	}
+
 	// ── Auth (stricter: 5 attempts per minute) ──────────────────────────────
 	// rate.Limit(5.0/60) = 5 tokens per 60 seconds, burst of 5
 	loginLimiter := mw.RateLimit(rate.Limit(5.0/60), 5)
@@ -44,6 +55,9 @@ func (s *Server) RegisterRoutes() {
 	s.Echo.GET("/about", pageHandler.ShowPublicAbout)
 	s.Echo.GET("/projects", projectHandler.ShowPublicProjectsList)
 	s.Echo.GET("/projects/:slug", projectHandler.ShowPublicProject)
+	s.Echo.GET("/blog", blogHandler.ShowPublicBlogList)
+	s.Echo.GET("/blog/:slug", blogHandler.ShowPublicBlog)
+	s.Echo.GET("/img/:id", mediaHandler.ServeImage)
 
 	// ── Contact (10 submissions per hour per IP) ──────────────────────────
 	// rate.Limit(10.0/3600) = 10 tokens per hour, burst of 3
@@ -69,9 +83,37 @@ func (s *Server) RegisterRoutes() {
 	media := admin.Group("/media")
 	media.GET("", mediaHandler.ShowMediaList)
 	media.GET("/selector", mediaHandler.ShowMediaSelector)
+	media.GET("/trash", mediaHandler.ShowMediaTrash)
+	media.GET("/alt-text-audit", mediaHandler.ShowAltTextAudit)
+	media.POST("/collections", mediaHandler.CreateMediaCollection)
+	media.PUT("/collections/:id", mediaHandler.RenameMediaCollection)
+	media.DELETE("/collections/:id", mediaHandler.DeleteMediaCollection)
+	media.POST("/bulk/delete", mediaHandler.BulkDeleteMedia)
+	media.POST("/bulk/restore", mediaHandler.BulkRestoreMedia)
+	media.POST("/bulk/move", mediaHandler.BulkMoveMedia)
+	media.POST("/bulk/tags", mediaHandler.BulkTagMedia)
+	media.GET("/bulk/alt-text", mediaHandler.ShowBulkAltText)
+	media.POST("/bulk/alt-text", mediaHandler.BulkUpdateAltText)
 	media.POST("/upload", mediaHandler.UploadMedia)
+	media.POST("/direct-uploads", mediaHandler.BeginDirectUpload)
+	media.POST("/direct-uploads/:id/complete", mediaHandler.CompleteDirectUpload)
+	media.DELETE("/direct-uploads/:id", mediaHandler.CancelDirectUpload)
+	media.POST("/uploads", mediaHandler.BeginChunkedUpload)
+	media.GET("/uploads/:id", mediaHandler.GetChunkedUpload)
+	media.PATCH("/uploads/:id", mediaHandler.UploadChunk)
+	media.DELETE("/uploads/:id", mediaHandler.CancelChunkedUpload)
+	media.POST("/uploads/:id/complete", mediaHandler.CompleteChunkedUpload)
 	media.GET("/:id/detail", mediaHandler.GetMediaDetail)
+	media.GET("/:id/card", mediaHandler.GetMediaCard)
+	media.POST("/:id/reprocess", mediaHandler.ReprocessMedia)
+	media.PUT("/:id/visibility", mediaHandler.SetMediaVisibility)
+	media.PUT("/:id/focal-point", mediaHandler.SetMediaFocalPoint)
+	media.PUT("/:id/alt-text", mediaHandler.UpdateAuditedAltText)
+	media.POST("/:id/share", mediaHandler.ShareMedia)
+	media.POST("/:id/replace", mediaHandler.ReplaceMedia)
 	media.PUT("/:id", mediaHandler.UpdateMedia)
+	media.POST("/:id/restore", mediaHandler.RestoreMedia)
+	media.DELETE("/:id/permanent", mediaHandler.PurgeMedia)
 	media.DELETE("/:id", mediaHandler.DeleteMedia)
 
 	// Page management (admin only)
@@ -88,12 +130,25 @@ func (s *Server) RegisterRoutes() {
 	projects.POST("", projectHandler.CreateProject)
 	projects.GET("/create-modal", projectHandler.ShowCreateModal)
 	projects.GET("/gallery-selector", projectHandler.ShowGallerySelector)
+	projects.GET("/document-selector", projectHandler.ShowDocumentSelector)
 	projects.GET("/:slug", projectHandler.ShowEditPage)
 	projects.DELETE("/:slug", projectHandler.DeleteProject)
 	projects.PUT("/:slug", projectHandler.UpdateProject)
 	projects.PUT("/:slug/publish", projectHandler.PublishProject)
 	projects.PUT("/:slug/unpublish", projectHandler.UnpublishProject)
 
+	// Blog Management
+	blogs := admin.Group("/blogs")
+
+	blogs.GET("", blogHandler.ShowBlogsList)
+	blogs.POST("", blogHandler.CreateBlog)
+	blogs.GET("/create-modal", blogHandler.ShowCreateModal)
+	blogs.GET("/:slug", blogHandler.ShowEditPage)
+	blogs.DELETE("/:slug", blogHandler.DeleteBlog)
+	blogs.PUT("/:slug", blogHandler.UpdateBlog)
+	blogs.PUT("/:slug/publish", blogHandler.PublishBlog)
+	blogs.PUT("/:slug/unpublish", blogHandler.UnpublishBlog)
+
 	// Tag Management
 	tags := admin.Group("/tags")
 
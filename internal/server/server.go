@@ -2,11 +2,14 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/iankencruz/threefive/database"
@@ -24,8 +27,10 @@ type Server struct {
 	Queries           *generated.Queries
 	AuthService       *services.AuthService
 	MediaService      *services.MediaService
+	Storage           services.StorageProvider
 	PageService       *services.PageService
 	ProjectService    *services.ProjectService
+	BlogService       *services.BlogService
 	ContactService    *services.ContactService
 	TagService        *services.TagService
 	SeoService        *services.SEOService
@@ -66,38 +71,10 @@ func NewServer() *Server {
 		panic(err)
 	}
 
-	var storage services.StorageProvider
-	storageProvider := os.Getenv("STORAGE_PROVIDER")
-
-	if storageProvider == "s3" {
-		// Initialize S3 storage (Vultr Object Storage or AWS S3)
-		slogger.Info("Initializing S3 storage")
-		s3Storage, err := services.NewS3Storage(context.Background(), services.S3Config{
-			Bucket:          os.Getenv("S3_BUCKET"),
-			Region:          os.Getenv("S3_REGION"),
-			Endpoint:        os.Getenv("S3_ENDPOINT"), // For Vultr or other S3-compatible services
-			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
-			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
-			BaseURL:         os.Getenv("S3_BASE_URL"), // Optional CDN URL
-		})
-		if err != nil {
-			slogger.Error("failed to initialize S3 storage", "error", err)
-			panic(err)
-		}
-		storage = s3Storage
-		slogger.Info("S3 storage initialized", "bucket", os.Getenv("S3_BUCKET"), "region", os.Getenv("S3_REGION"))
-	} else {
-		// Initialize local storage (default for development)
-		uploadDir := os.Getenv("LOCAL_UPLOAD_DIR")
-		if uploadDir == "" {
-			uploadDir = "./uploads"
-		}
-		baseURL := os.Getenv("LOCAL_BASE_URL")
-		if baseURL == "" {
-			baseURL = "/uploads"
-		}
-		storage = services.NewLocalStorage(uploadDir, baseURL)
-		slogger.Info("Local storage initialized", "upload_dir", uploadDir, "base_url", baseURL)
+	storage, err := NewStorage(context.Background(), slogger)
+	if err != nil {
+		slogger.Error("failed to initialize storage", "error", err)
+		panic(err)
 	}
 
 	// Initialize SQLC queries
@@ -129,10 +106,12 @@ func NewServer() *Server {
 				"image/png",
 				"image/gif",
 				"image/webp",
+				"image/svg+xml", // sanitized on upload
 				"video/mp4",
 				"video/quicktime",
 				"application/pdf",
 			},
+			ChunkDir: os.Getenv("UPLOAD_CHUNK_DIR"), // Defaults to the system temp dir
 		},
 	)
 
@@ -157,6 +136,9 @@ func NewServer() *Server {
 	projectService := services.NewProjectService(queries, mediaService)
 	slogger.Info("projects service initialized")
 
+	blogService := services.NewBlogService(queries, mediaService)
+	slogger.Info("blogs service initialized")
+
 	sessionStore := session.NewPostgresStore(db.Pool(), queries, slogger)
 	slogger.Info("session store initialized")
 
@@ -182,8 +164,10 @@ func NewServer() *Server {
 		Queries:           queries,
 		AuthService:       authService,
 		MediaService:      mediaService,
+		Storage:           storage,
 		PageService:       pageService,
 		ProjectService:    projectService,
+		BlogService:       blogService,
 		TagService:        tagService,
 		SeoService:        seoService,
 		ContactService:    contactService,
@@ -203,9 +187,21 @@ func (s *Server) Start(ctx context.Context, port string) error {
 		port = ":8080" // fallback
 	}
 
+	// Background workers use the database, so shutdown waits for them
+	var workers sync.WaitGroup
+
 	// Start session cleanup
-	go s.sessionCleanupWorker(ctx)
-	go s.contactEmailRetryWorker(ctx)
+	workers.Go(func() { s.sessionCleanupWorker(ctx) })
+	workers.Go(func() { s.contactEmailRetryWorker(ctx) })
+
+	// Start media processing workers
+	for i := range mediaWorkerCount() {
+		workers.Go(func() { s.mediaJobWorker(ctx, i+1) })
+	}
+	workers.Go(func() { s.mediaJobCleanupWorker(ctx) })
+	workers.Go(func() { s.uploadCleanupWorker(ctx) })
+	workers.Go(func() { s.storageCheckWorker(ctx) })
+	workers.Go(func() { s.mediaTrashPurgeWorker(ctx) })
 
 	srv := &http.Server{
 		Addr:    port,
@@ -233,13 +229,35 @@ func (s *Server) Start(ctx context.Context, port string) error {
 		return err
 	}
 
-	// Only close database pool after server has shutdown
+	// Interrupted media jobs are released back to pending as workers stop
+	if !waitForWorkers(shutdownCtx, &workers) {
+		s.Log.Warn("background workers still running at shutdown deadline")
+	}
+
+	// Only close database pool after server and workers have shutdown
 	s.DB.Close()
 
 	s.Log.Info("Server exited cleanly")
 	return nil
 }
 
+// waitForWorkers waits for workers to return, giving up when ctx is done.
+// It reports whether they all returned.
+func waitForWorkers(ctx context.Context, workers *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 // sessionCleanupWorker periodically removes expired sessions
 func (s *Server) sessionCleanupWorker(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Hour)
@@ -281,3 +299,212 @@ func (s *Server) contactEmailRetryWorker(ctx context.Context) {
 		}
 	}
 }
+
+// mediaJobWorker drains the media job queue, polling when it is empty
+func (s *Server) mediaJobWorker(ctx context.Context, id int) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	s.Log.Info("media job worker started", "worker", id)
+
+	for {
+		// Keep claiming jobs until the queue is empty
+		for ctx.Err() == nil {
+			ran, err := s.MediaService.RunNextJob(ctx)
+			if errors.Is(err, services.ErrMediaJobInterrupted) {
+				s.Log.Info("media job released for retry after shutdown", "worker", id)
+			} else if err != nil {
+				s.Log.Error("media job failed", "worker", id, "error", err)
+			}
+			if !ran {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.Log.Info("media job worker stopped", "worker", id)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// mediaJobCleanupWorker periodically removes old completed media jobs
+func (s *Server) mediaJobCleanupWorker(ctx context.Context) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.MediaService.PurgeCompletedJobs(ctx); err != nil {
+				s.Log.Error("failed to purge completed media jobs", "error", err)
+			}
+		}
+	}
+}
+
+// uploadCleanupWorker periodically removes direct and chunked uploads that
+// were never finalized, including the objects, parts and temporary files
+// already stored
+func (s *Server) uploadCleanupWorker(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.MediaService.PurgeExpiredDirectUploads(ctx)
+			if err != nil {
+				s.Log.Error("failed to purge expired direct uploads", "error", err)
+			}
+			if purged > 0 {
+				s.Log.Info("purged expired direct uploads", "count", purged)
+			}
+
+			purged, err = s.MediaService.PurgeExpiredChunkedUploads(ctx)
+			if err != nil {
+				s.Log.Error("failed to purge expired chunked uploads", "error", err)
+			}
+			if purged > 0 {
+				s.Log.Info("purged expired chunked uploads", "count", purged)
+			}
+		}
+	}
+}
+
+// storageCheckWorker reconciles storage with the media table once a day,
+// logging missing files and, with STORAGE_REAP_ORPHANS=true, deleting
+// unreferenced objects older than STORAGE_ORPHAN_GRACE
+func (s *Server) storageCheckWorker(ctx context.Context) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	opts := storageCheckOptions(s.Log)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := s.MediaService.CheckStorage(ctx, opts)
+			if err != nil {
+				s.Log.Error("storage check failed", "error", err)
+			}
+			if report == nil {
+				continue
+			}
+			for _, missing := range report.Missing {
+				s.Log.Warn("media file missing from storage", "media_id", missing.MediaID.String(), "key", missing.Key)
+			}
+			s.Log.Info("storage check finished",
+				"objects", report.Objects,
+				"missing", len(report.Missing),
+				"orphans", len(report.Orphans),
+				"reaped", report.Reaped,
+				"unused_media", len(report.Unused),
+			)
+		}
+	}
+}
+
+// mediaTrashPurgeWorker permanently deletes media that has been in the trash
+// longer than services.MediaTrashRetention, once at startup and then hourly
+func (s *Server) mediaTrashPurgeWorker(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.MediaService.PurgeOldDeletedMedia(ctx)
+		if err != nil {
+			s.Log.Error("failed to purge deleted media", "error", err)
+		}
+		if purged > 0 {
+			s.Log.Info("purged deleted media", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// storageCheckOptions reads STORAGE_REAP_ORPHANS and STORAGE_ORPHAN_GRACE
+func storageCheckOptions(logger *slog.Logger) services.StorageCheckOptions {
+	opts := services.StorageCheckOptions{
+		DeleteOrphans: os.Getenv("STORAGE_REAP_ORPHANS") == "true",
+	}
+	if grace := os.Getenv("STORAGE_ORPHAN_GRACE"); grace != "" {
+		d, err := time.ParseDuration(grace)
+		if err != nil {
+			logger.Warn("invalid STORAGE_ORPHAN_GRACE, using the default", "value", grace, "default", services.DefaultOrphanGracePeriod)
+		}
+		opts.GracePeriod = d
+	}
+	return opts
+}
+
+// NewStorage builds the storage provider selected by STORAGE_PROVIDER: S3
+// (Vultr Object Storage, AWS S3 or MinIO) or, by default, the local disk
+func NewStorage(ctx context.Context, logger *slog.Logger) (services.StorageProvider, error) {
+	if os.Getenv("STORAGE_PROVIDER") == "s3" {
+		logger.Info("Initializing S3 storage")
+		s3Storage, err := services.NewS3Storage(ctx, services.S3Config{
+			Bucket:          os.Getenv("S3_BUCKET"),
+			Region:          os.Getenv("S3_REGION"),
+			Endpoint:        os.Getenv("S3_ENDPOINT"), // For Vultr or other S3-compatible services
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			BaseURL:         os.Getenv("S3_BASE_URL"), // Optional CDN URL
+		})
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("S3 storage initialized", "bucket", os.Getenv("S3_BUCKET"), "region", os.Getenv("S3_REGION"))
+		return s3Storage, nil
+	}
+
+	// Local storage (default for development)
+	uploadDir := os.Getenv("LOCAL_UPLOAD_DIR")
+	if uploadDir == "" {
+		uploadDir = "./uploads"
+	}
+	baseURL := os.Getenv("LOCAL_BASE_URL")
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+	logger.Info("Local storage initialized", "upload_dir", uploadDir, "base_url", baseURL)
+	return services.NewLocalStorage(uploadDir, baseURL, MediaSigningKey(logger)), nil
+}
+
+// mediaWorkerCount reads MEDIA_WORKERS, defaulting to 2
+func mediaWorkerCount() int {
+	n, err := strconv.Atoi(os.Getenv("MEDIA_WORKERS"))
+	if err != nil || n < 1 {
+		return 2
+	}
+	return n
+}
+
+// MediaSigningKey returns the key that signs URLs for private local media.
+// Without MEDIA_SIGNING_KEY a random key is used, so links shared before a
+// restart stop working.
+func MediaSigningKey(logger *slog.Logger) []byte {
+	if key := os.Getenv("MEDIA_SIGNING_KEY"); key != "" {
+		return []byte(key)
+	}
+
+	logger.Warn("MEDIA_SIGNING_KEY is not set; signed media URLs will not survive a restart")
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
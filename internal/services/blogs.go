@@ -0,0 +1,496 @@
+// internal/services/blogs.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/iankencruz/threefive/pkg/markdown"
+	"github.com/iankencruz/threefive/pkg/validation"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type BlogService struct {
+	queries      *generated.Queries
+	mediaService *MediaService
+}
+
+func NewBlogService(queries *generated.Queries, mediaService *MediaService) *BlogService {
+	return &BlogService{
+		queries:      queries,
+		mediaService: mediaService,
+	}
+}
+
+// BlogResponse is the view model for a blog post with related data
+type BlogResponse struct {
+	Blog          generated.Blog
+	FeaturedImage *MediaResponse
+	Tags          []TagResponse
+	BodyHTML      string // Sanitized HTML rendered from the markdown body (see RenderBody)
+	WordCount     int32
+	ReadingTime   int32  // Minutes; 0 when the post has no body
+	Excerpt       string // The author's excerpt, or one generated from the body
+}
+
+// CreateBlogRequest represents the data needed to create a blog post
+type CreateBlogRequest struct {
+	Title           string
+	Slug            string
+	Excerpt         string
+	Body            string // Markdown
+	Status          string // "draft", "published", "archived"
+	IsFeatured      bool
+	FeaturedImageID *uuid.UUID
+	AuthorID        uuid.UUID
+	TagNames        []string // Tag names (will be created if they don't exist)
+}
+
+// Validate validates the create request
+func (r *CreateBlogRequest) Validate() (validation.FieldErrors, error) {
+	fields := []validation.Field{
+		{
+			Name:  "title",
+			Value: r.Title,
+			Rules: []validation.ValidationRule{
+				validation.Required("Title is required"),
+				validation.MinLength(3, "Title must be at least 3 characters"),
+				validation.MaxLength(200, "Title must be at most 200 characters"),
+			},
+		},
+		{
+			Name:  "slug",
+			Value: r.Slug,
+			Rules: []validation.ValidationRule{
+				validation.IsSlug(""),
+				validation.MaxLength(200, "Slug must be at most 200 characters"),
+			},
+		},
+		{
+			Name:  "excerpt",
+			Value: r.Excerpt,
+			Rules: []validation.ValidationRule{
+				validation.MaxLength(500, "Excerpt must be at most 500 characters"),
+			},
+		},
+		{
+			Name:  "body",
+			Value: r.Body,
+			Rules: []validation.ValidationRule{
+				validation.MaxLength(100000, "Body must be at most 100000 characters"),
+			},
+		},
+		{
+			Name:  "status",
+			Value: r.Status,
+			Rules: []validation.ValidationRule{
+				validation.OneOf([]string{"draft", "published", "archived"}, ""),
+			},
+		},
+	}
+
+	errors := validation.ValidateFields(fields)
+	if errors.HasErrors() {
+		return errors, fmt.Errorf("validation failed")
+	}
+
+	return nil, nil
+}
+
+// CreateBlog creates a new blog post with tags and featured image
+func (s *BlogService) CreateBlog(ctx context.Context, req *CreateBlogRequest) (*BlogResponse, error) {
+	if req.Slug == "" {
+		req.Slug = GenerateSlug(req.Title)
+	}
+
+	// Check slug uniqueness
+	exists, err := s.queries.CheckBlogSlugExists(ctx, generated.CheckBlogSlugExistsParams{
+		Slug:   req.Slug,
+		BlogID: pgtype.UUID{Valid: false}, // Empty UUID for new blog
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check slug uniqueness: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("slug already exists: %s", req.Slug)
+	}
+
+	blogID := uuid.New()
+
+	var featuredImageID pgtype.UUID
+	if req.FeaturedImageID != nil {
+		featuredImageID = pgtype.UUID{Bytes: *req.FeaturedImageID, Valid: true}
+	}
+
+	metrics := computeContentMetrics(req.Body)
+
+	_, err = s.queries.CreateBlog(ctx, generated.CreateBlogParams{
+		ID:              pgtype.UUID{Bytes: blogID, Valid: true},
+		Title:           req.Title,
+		Slug:            req.Slug,
+		Excerpt:         pgtype.Text{String: req.Excerpt, Valid: req.Excerpt != ""},
+		Body:            pgtype.Text{String: req.Body, Valid: req.Body != ""},
+		Status:          pgtype.Text{String: req.Status, Valid: req.Status != ""},
+		ReadingTime:     metrics.ReadingTime,
+		WordCount:       metrics.WordCount,
+		IsFeatured:      pgtype.Bool{Bool: req.IsFeatured, Valid: true},
+		FeaturedImageID: featuredImageID,
+		AuthorID:        pgtype.UUID{Bytes: req.AuthorID, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blog: %w", err)
+	}
+
+	pgBlogID := pgtype.UUID{Bytes: blogID, Valid: true}
+
+	if err := s.syncFeaturedImage(ctx, pgBlogID, featuredImageID); err != nil {
+		return nil, fmt.Errorf("failed to set featured image: %w", err)
+	}
+
+	if len(req.TagNames) > 0 {
+		if err := s.syncBlogTags(ctx, pgBlogID, strings.Join(req.TagNames, ",")); err != nil {
+			return nil, fmt.Errorf("failed to add tags: %w", err)
+		}
+	}
+
+	return s.GetBlogByID(ctx, blogID)
+}
+
+// UpdateBlogRequest represents the data needed to update a blog post
+type UpdateBlogRequest struct {
+	Title           string
+	Slug            string
+	Excerpt         string
+	Body            string
+	Status          string
+	IsFeatured      bool
+	Tags            string
+	FeaturedImageID string
+}
+
+// Validate validates the update request
+func (r *UpdateBlogRequest) Validate() (validation.FieldErrors, error) {
+	fields := []validation.Field{
+		{
+			Name:  "title",
+			Value: r.Title,
+			Rules: []validation.ValidationRule{
+				validation.Required("Title is required"),
+				validation.MinLength(3, "Title must be at least 3 characters"),
+				validation.MaxLength(200, "Title must be at most 200 characters"),
+			},
+		},
+		{
+			Name:  "slug",
+			Value: r.Slug,
+			Rules: []validation.ValidationRule{
+				validation.Required("Slug is required"),
+				validation.IsSlug(""),
+				validation.MaxLength(200, "Slug must be at most 200 characters"),
+			},
+		},
+		{
+			Name:  "excerpt",
+			Value: r.Excerpt,
+			Rules: []validation.ValidationRule{
+				validation.MaxLength(500, "Excerpt must be at most 500 characters"),
+			},
+		},
+		{
+			Name:  "body",
+			Value: r.Body,
+			Rules: []validation.ValidationRule{
+				validation.MaxLength(100000, "Body must be at most 100000 characters"),
+			},
+		},
+		{
+			Name:  "status",
+			Value: r.Status,
+			Rules: []validation.ValidationRule{
+				validation.OneOf([]string{"draft", "published", "archived"}, ""),
+			},
+		},
+	}
+
+	// Validate featured image ID format if provided
+	if r.FeaturedImageID != "" {
+		if _, err := uuid.Parse(r.FeaturedImageID); err != nil {
+			errors := validation.FieldErrors{"featured_image_id": "Invalid image ID format"}
+			return errors, fmt.Errorf("validation failed")
+		}
+	}
+
+	errors := validation.ValidateFields(fields)
+	if errors.HasErrors() {
+		return errors, fmt.Errorf("validation failed")
+	}
+
+	return nil, nil
+}
+
+// UpdateBlogBySlug updates a blog post by slug
+func (s *BlogService) UpdateBlogBySlug(ctx context.Context, slug string, req *UpdateBlogRequest) (*BlogResponse, error) {
+	existing, err := s.queries.GetBlogBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("blog not found: %w", err)
+	}
+
+	if req.Slug != "" && req.Slug != slug {
+		exists, err := s.queries.CheckBlogSlugExists(ctx, generated.CheckBlogSlugExistsParams{
+			Slug:   req.Slug,
+			BlogID: existing.ID, // Exclude current blog
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to check slug uniqueness: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("slug already exists: %s", req.Slug)
+		}
+	}
+
+	params := generated.UpdateBlogParams{
+		ID:         existing.ID,
+		IsFeatured: pgtype.Bool{Bool: req.IsFeatured, Valid: true},
+	}
+
+	if req.Title != "" {
+		params.Title = pgtype.Text{String: req.Title, Valid: true}
+	}
+	if req.Slug != "" {
+		params.Slug = pgtype.Text{String: req.Slug, Valid: true}
+	}
+	// Body is always written so it can be cleared; metrics follow it
+	metrics := computeContentMetrics(req.Body)
+	params.Body = pgtype.Text{String: req.Body, Valid: true}
+	params.WordCount = pgtype.Int4{Int32: metrics.WordCount, Valid: true}
+	params.ReadingTime = metrics.ReadingTime
+
+	// Excerpt is always written; a blank one is stored as NULL and generated
+	// from the body when read
+	params.Excerpt = pgtype.Text{String: req.Excerpt, Valid: req.Excerpt != ""}
+	if req.Status != "" {
+		params.Status = pgtype.Text{String: req.Status, Valid: true}
+	}
+
+	// Handle featured image — clear if empty string, set if valid UUID
+	if req.FeaturedImageID != "" {
+		if imgUUID, err := uuid.Parse(req.FeaturedImageID); err == nil {
+			params.FeaturedImageID = pgtype.UUID{Bytes: imgUUID, Valid: true}
+		}
+	}
+
+	updated, err := s.queries.UpdateBlog(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update blog: %w", err)
+	}
+
+	if err := s.syncFeaturedImage(ctx, updated.ID, updated.FeaturedImageID); err != nil {
+		return nil, fmt.Errorf("failed to sync featured image: %w", err)
+	}
+
+	if err := s.syncBlogTags(ctx, updated.ID, req.Tags); err != nil {
+		return nil, fmt.Errorf("failed to sync tags: %w", err)
+	}
+
+	return s.GetBlogBySlug(ctx, updated.Slug)
+}
+
+// ListBlogs retrieves a paginated list of blog posts
+func (s *BlogService) ListBlogs(ctx context.Context, limit, offset int32) ([]BlogResponse, error) {
+	blogs, err := s.queries.ListBlogs(ctx, generated.ListBlogsParams{
+		LimitVal:  limit,
+		OffsetVal: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blogs: %w", err)
+	}
+
+	return s.buildBlogResponses(ctx, blogs)
+}
+
+// ListPublishedBlogs retrieves published blog posts only
+func (s *BlogService) ListPublishedBlogs(ctx context.Context, limit, offset int32) ([]BlogResponse, error) {
+	blogs, err := s.queries.ListPublishedBlogs(ctx, generated.ListPublishedBlogsParams{
+		LimitVal:  limit,
+		OffsetVal: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published blogs: %w", err)
+	}
+
+	return s.buildBlogResponses(ctx, blogs)
+}
+
+// GetBlogByID retrieves a blog post by ID with all related data
+func (s *BlogService) GetBlogByID(ctx context.Context, blogID uuid.UUID) (*BlogResponse, error) {
+	blog, err := s.queries.GetBlogByID(ctx, pgtype.UUID{Bytes: blogID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("blog not found: %w", err)
+	}
+
+	return s.buildBlogResponse(ctx, &blog)
+}
+
+// GetBlogBySlug retrieves a blog post by slug with all related data
+func (s *BlogService) GetBlogBySlug(ctx context.Context, slug string) (*BlogResponse, error) {
+	blog, err := s.queries.GetBlogBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("blog not found: %w", err)
+	}
+
+	return s.buildBlogResponse(ctx, &blog)
+}
+
+// DeleteBlogBySlug soft-deletes a blog post
+func (s *BlogService) DeleteBlogBySlug(ctx context.Context, slug string) error {
+	blogID, err := s.queries.GetBlogIDBySlug(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("blog not found: %w", err)
+	}
+
+	if err := s.queries.SoftDeleteBlog(ctx, blogID); err != nil {
+		return fmt.Errorf("failed to delete blog: %w", err)
+	}
+
+	return nil
+}
+
+// PublishBlog publishes a blog post
+func (s *BlogService) PublishBlog(ctx context.Context, blogID uuid.UUID) (*BlogResponse, error) {
+	if _, err := s.queries.PublishBlog(ctx, pgtype.UUID{Bytes: blogID, Valid: true}); err != nil {
+		return nil, fmt.Errorf("failed to publish blog: %w", err)
+	}
+
+	return s.GetBlogByID(ctx, blogID)
+}
+
+// UnpublishBlog reverts a blog post to draft
+func (s *BlogService) UnpublishBlog(ctx context.Context, blogID uuid.UUID) (*BlogResponse, error) {
+	if _, err := s.queries.UnpublishBlog(ctx, pgtype.UUID{Bytes: blogID, Valid: true}); err != nil {
+		return nil, fmt.Errorf("failed to unpublish blog: %w", err)
+	}
+
+	return s.GetBlogByID(ctx, blogID)
+}
+
+// CountBlogs returns the total count of blog posts
+func (s *BlogService) CountBlogs(ctx context.Context) (int64, error) {
+	return s.queries.CountBlogs(ctx)
+}
+
+// CountPublishedBlogs returns the count of published blog posts
+func (s *BlogService) CountPublishedBlogs(ctx context.Context) (int64, error) {
+	return s.queries.CountPublishedBlogs(ctx)
+}
+
+// RenderBody renders the blog post's markdown body into BodyHTML
+func (s *BlogService) RenderBody(ctx context.Context, blog *BlogResponse) error {
+	if !blog.Blog.Body.Valid {
+		return nil
+	}
+
+	html, err := markdown.Render(blog.Blog.Body.String, s.mediaService.MarkdownResolver(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to render blog body: %w", err)
+	}
+
+	blog.BodyHTML = html
+	return nil
+}
+
+// Helper functions
+
+func (s *BlogService) buildBlogResponse(ctx context.Context, blog *generated.Blog) (*BlogResponse, error) {
+	response := &BlogResponse{
+		Blog:        *blog,
+		WordCount:   blog.WordCount,
+		ReadingTime: blog.ReadingTime.Int32,
+		Excerpt:     summaryOrExcerpt(blog.Excerpt, blog.Body),
+	}
+
+	// Load featured image from media_relations
+	featuredMedia, err := s.mediaService.GetFeaturedMediaForEntity(ctx, "blog", blog.ID)
+	if err == nil {
+		mediaResp := s.mediaService.ToMediaResponse(featuredMedia)
+		response.FeaturedImage = &mediaResp
+	}
+
+	// Load tags
+	tags, err := s.queries.GetBlogTags(ctx, blog.ID)
+	if err == nil && len(tags) > 0 {
+		response.Tags = make([]TagResponse, len(tags))
+		for i, tag := range tags {
+			response.Tags[i] = TagResponse{Tag: tag}
+		}
+	}
+
+	return response, nil
+}
+
+func (s *BlogService) buildBlogResponses(ctx context.Context, blogs []generated.Blog) ([]BlogResponse, error) {
+	responses := make([]BlogResponse, len(blogs))
+	for i, blog := range blogs {
+		resp, err := s.buildBlogResponse(ctx, &blog)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = *resp
+	}
+	return responses, nil
+}
+
+// syncFeaturedImage replaces the featured media relation for a blog post
+func (s *BlogService) syncFeaturedImage(ctx context.Context, blogID pgtype.UUID, mediaID pgtype.UUID) error {
+	if err := s.queries.DeleteFeaturedMediaForEntity(ctx, generated.DeleteFeaturedMediaForEntityParams{
+		EntityType: "blog",
+		EntityID:   blogID,
+	}); err != nil {
+		return fmt.Errorf("failed to clear featured image: %w", err)
+	}
+
+	if !mediaID.Valid {
+		return nil
+	}
+
+	return s.mediaService.LinkMediaToEntity(ctx, mediaID, "blog", blogID, "featured", 0)
+}
+
+// syncBlogTags replaces all tags for a blog post
+func (s *BlogService) syncBlogTags(ctx context.Context, blogID pgtype.UUID, tagsCSV string) error {
+	if err := s.queries.ClearBlogTags(ctx, blogID); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+
+	if tagsCSV == "" {
+		return nil
+	}
+
+	for _, tagName := range strings.Split(tagsCSV, ",") {
+		tagName = strings.TrimSpace(tagName)
+		if tagName == "" {
+			continue
+		}
+
+		tag, err := s.queries.FindOrCreateTag(ctx, generated.FindOrCreateTagParams{
+			ID:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Name: tagName,
+			Slug: GenerateSlug(tagName),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to find or create tag %q: %w", tagName, err)
+		}
+
+		_, err = s.queries.AddBlogTag(ctx, generated.AddBlogTagParams{
+			BlogID: blogID,
+			TagID:  tag.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add tag %q: %w", tagName, err)
+		}
+	}
+
+	return nil
+}
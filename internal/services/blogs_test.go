@@ -0,0 +1,164 @@
+// internal/services/blogs_test.go
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBlogService_CreateBlog tests slug handling when creating blog posts
+func TestBlogService_CreateBlog(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	blogService := NewBlogService(queries, NewMediaService(pool, queries, nil, MediaConfig{}))
+	author := createTestUser(t, ctx, queries, "author@example.com", "AuthorPass123!", "Blog", "Author")
+
+	_, err := blogService.CreateBlog(ctx, &CreateBlogRequest{
+		Title:    "Existing Post",
+		Slug:     "existing-post",
+		Status:   "draft",
+		AuthorID: author.ID.Bytes,
+	})
+	require.NoError(t, err, "failed to create existing post")
+
+	tests := []struct {
+		name     string
+		req      CreateBlogRequest
+		wantErr  string
+		wantSlug string
+	}{
+		{
+			name:     "success: post is created with its slug",
+			req:      CreateBlogRequest{Title: "New Post", Slug: "new-post", Status: "draft"},
+			wantSlug: "new-post",
+		},
+		{
+			name:     "success: slug is generated from the title",
+			req:      CreateBlogRequest{Title: "Generated Slug Post", Status: "draft"},
+			wantSlug: "generated-slug-post",
+		},
+		{
+			name:    "failure: slug used by another post is rejected",
+			req:     CreateBlogRequest{Title: "Clashing Post", Slug: "existing-post", Status: "draft"},
+			wantErr: "slug already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			req.AuthorID = author.ID.Bytes
+
+			// Act
+			blog, err := blogService.CreateBlog(ctx, &req)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSlug, blog.Blog.Slug)
+		})
+	}
+
+	t.Run("success: featured flag and tags are saved", func(t *testing.T) {
+		// Act
+		blog, err := blogService.CreateBlog(ctx, &CreateBlogRequest{
+			Title:      "Featured Post",
+			Status:     "draft",
+			IsFeatured: true,
+			AuthorID:   author.ID.Bytes,
+			TagNames:   []string{"Design", "Process"},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		assert.True(t, blog.Blog.IsFeatured.Bool)
+		var tagNames []string
+		for _, tag := range blog.Tags {
+			tagNames = append(tagNames, tag.Tag.Name)
+		}
+		assert.ElementsMatch(t, []string{"Design", "Process"}, tagNames)
+	})
+}
+
+// TestBlogService_UpdateBlogBySlug tests slug handling when updating blog posts
+func TestBlogService_UpdateBlogBySlug(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	blogService := NewBlogService(queries, NewMediaService(pool, queries, nil, MediaConfig{}))
+	author := createTestUser(t, ctx, queries, "author@example.com", "AuthorPass123!", "Blog", "Author")
+
+	for _, slug := range []string{"first-post", "second-post", "deleted-post"} {
+		_, err := blogService.CreateBlog(ctx, &CreateBlogRequest{
+			Title:    "Post " + slug,
+			Slug:     slug,
+			Status:   "draft",
+			AuthorID: author.ID.Bytes,
+		})
+		require.NoError(t, err, "failed to create post %s", slug)
+	}
+	require.NoError(t, blogService.DeleteBlogBySlug(ctx, "deleted-post"))
+
+	tests := []struct {
+		name     string
+		slug     string
+		newSlug  string
+		wantErr  string
+		wantSlug string
+	}{
+		{
+			name:     "success: post keeps its own slug",
+			slug:     "first-post",
+			newSlug:  "first-post",
+			wantSlug: "first-post",
+		},
+		{
+			name:     "success: post is renamed to an unused slug",
+			slug:     "first-post",
+			newSlug:  "renamed-post",
+			wantSlug: "renamed-post",
+		},
+		{
+			name:     "edge: slug of a deleted post can be reused",
+			slug:     "renamed-post",
+			newSlug:  "deleted-post",
+			wantSlug: "deleted-post",
+		},
+		{
+			name:    "failure: slug used by another post is rejected",
+			slug:    "second-post",
+			newSlug: "deleted-post",
+			wantErr: "slug already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			blog, err := blogService.UpdateBlogBySlug(ctx, tt.slug, &UpdateBlogRequest{
+				Title:  "Updated Post",
+				Slug:   tt.newSlug,
+				Status: "draft",
+			})
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSlug, blog.Blog.Slug)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// internal/services/content.go
+package services
+
+import (
+	"fmt"
+
+	"github.com/iankencruz/threefive/pkg/markdown"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// autoExcerptLength caps generated excerpts/descriptions (well under the
+// 500/1000 character validation limits)
+const autoExcerptLength = 280
+
+// ContentMetrics holds values derived from a markdown body on save
+type ContentMetrics struct {
+	WordCount   int32
+	ReadingTime pgtype.Int4
+}
+
+// computeContentMetrics derives word count and reading time from a markdown
+// body
+func computeContentMetrics(body string) ContentMetrics {
+	summary := markdown.Summarize(body, autoExcerptLength)
+
+	return ContentMetrics{
+		WordCount:   int32(summary.WordCount),
+		ReadingTime: pgtype.Int4{Int32: int32(summary.ReadingTime), Valid: true},
+	}
+}
+
+// summaryOrExcerpt returns the author's summary, or an excerpt of the body
+// when they left it blank. The excerpt is never saved, so it follows edits
+// to the body.
+func summaryOrExcerpt(summary, body pgtype.Text) string {
+	if summary.String != "" {
+		return summary.String
+	}
+	return markdown.Summarize(body.String, autoExcerptLength).Excerpt
+}
+
+// FormatReadingTime renders a reading time for listings, e.g. "5 min read"
+func FormatReadingTime(minutes int32) string {
+	if minutes <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d min read", minutes)
+}
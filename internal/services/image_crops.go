@@ -0,0 +1,255 @@
+// internal/services/image_crops.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/image/draw"
+)
+
+// ImageCrop is a fixed aspect ratio crop generated around an image's focal
+// point, for layouts that would otherwise cut the subject off
+type ImageCrop struct {
+	Name   string // "hero", "card", "og"
+	Width  int
+	Height int
+}
+
+// imageCrops are generated for every processed image. Crops of smaller
+// images keep the aspect ratio but are not upscaled.
+var imageCrops = []ImageCrop{
+	{Name: "hero", Width: 1920, Height: 1080}, // 16:9
+	{Name: "card", Width: 800, Height: 800},   // 1:1
+	{Name: "og", Width: 1200, Height: 630},    // Open Graph
+}
+
+// ErrInvalidFocalPoint is returned for a focal point outside the image
+var ErrInvalidFocalPoint = errors.New("focal point must be within the image")
+
+// ErrNotCroppable is returned when setting a focal point on media that has
+// no crops, such as videos and SVGs
+var ErrNotCroppable = errors.New("only photos and other raster images can be cropped")
+
+// FocalPoint is where the subject of an image is, as fractions of its width
+// and height from the top left
+type FocalPoint struct {
+	X float64
+	Y float64
+}
+
+// CenterFocalPoint is used for images without a focal point
+var CenterFocalPoint = FocalPoint{X: 0.5, Y: 0.5}
+
+// Valid reports whether the point lies within the image
+func (f FocalPoint) Valid() bool {
+	return f.X >= 0 && f.X <= 1 && f.Y >= 0 && f.Y <= 1
+}
+
+// focalPointOf returns the media's focal point, the centre if none is set
+func focalPointOf(media *generated.Media) FocalPoint {
+	if !media.FocalX.Valid || !media.FocalY.Valid {
+		return CenterFocalPoint
+	}
+	return FocalPoint{X: float64(media.FocalX.Float32), Y: float64(media.FocalY.Float32)}
+}
+
+// cropRect returns the largest rectangle with the crop's aspect ratio that
+// fits in bounds, centred on the focal point as far as the edges allow
+func cropRect(bounds image.Rectangle, crop ImageCrop, focal FocalPoint) image.Rectangle {
+	width, height := bounds.Dx(), bounds.Dy()
+	if width*crop.Height > height*crop.Width {
+		width = max(1, height*crop.Width/crop.Height) // Wider than the crop
+	} else {
+		height = max(1, width*crop.Height/crop.Width)
+	}
+
+	x := clampInt(int(math.Round(focal.X*float64(bounds.Dx())))-width/2, 0, bounds.Dx()-width)
+	y := clampInt(int(math.Round(focal.Y*float64(bounds.Dy())))-height/2, 0, bounds.Dy()-height)
+	return image.Rect(x, y, x+width, y+height).Add(bounds.Min)
+}
+
+func clampInt(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
+
+// cropKey names a crop after the original and the focal point it was cut
+// around, so moving the point gives the crop a new URL rather than leaving
+// the old one cached
+func cropKey(originalKey string, crop ImageCrop, focal FocalPoint, ext string) string {
+	base := strings.TrimSuffix(originalKey, filepath.Ext(originalKey))
+	return fmt.Sprintf("%s_%s_%s%s", base, crop.Name, focalCode(focal), ext)
+}
+
+// focalCode encodes a focal point to the nearest percent, e.g. "050030"
+func focalCode(focal FocalPoint) string {
+	return fmt.Sprintf("%03d%03d", int(math.Round(focal.X*100)), int(math.Round(focal.Y*100)))
+}
+
+// generateCrops uploads every crop of an upright image, returning their
+// storage keys by crop name
+func (s *MediaService) generateCrops(ctx context.Context, img image.Image, originalKey string, focal FocalPoint) (map[string]string, error) {
+	contentType, ext := "image/jpeg", ".jpg"
+	if !isOpaque(img) {
+		contentType, ext = "image/png", ".png"
+	}
+
+	keys := make(map[string]string, len(imageCrops))
+	for _, crop := range imageCrops {
+		rect := cropRect(img.Bounds(), crop, focal)
+		width, height := crop.Width, crop.Height
+		if rect.Dx() < width {
+			width, height = rect.Dx(), rect.Dy()
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, rect, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := encodeImage(&buf, dst, contentType); err != nil {
+			s.deleteRenditions(ctx, keys)
+			return nil, fmt.Errorf("failed to encode %s crop: %w", crop.Name, err)
+		}
+
+		key := cropKey(originalKey, crop, focal, ext)
+		if err := s.storage.Upload(ctx, key, &buf, ObjectOptions{ContentType: contentType, Size: int64(buf.Len())}); err != nil {
+			s.deleteRenditions(ctx, keys)
+			return nil, fmt.Errorf("failed to upload %s crop: %w", crop.Name, err)
+		}
+		keys[crop.Name] = key
+	}
+	return keys, nil
+}
+
+// SetFocalPoint moves an image's focal point, or resets it to the centre
+// when focal is nil, and queues its crops to be cut again around it
+func (s *MediaService) SetFocalPoint(ctx context.Context, id pgtype.UUID, focal *FocalPoint) (*generated.Media, error) {
+	media, err := s.GetMediaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !IsImage(media.MimeType) || !needsProcessing(media.MimeType) {
+		return nil, ErrNotCroppable
+	}
+	if media.ProcessingStatus == MediaStatusPending || media.ProcessingStatus == MediaStatusProcessing {
+		return nil, ErrMediaProcessing
+	}
+
+	params := generated.SetMediaFocalPointParams{ID: id}
+	if focal != nil {
+		if !focal.Valid() {
+			return nil, ErrInvalidFocalPoint
+		}
+		params.FocalX = pgtype.Float4{Float32: float32(focal.X), Valid: true}
+		params.FocalY = pgtype.Float4{Float32: float32(focal.Y), Valid: true}
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	updated, err := qtx.SetMediaFocalPoint(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set focal point: %w", err)
+	}
+	if err := enqueueMediaJob(ctx, qtx, id, MediaJobCrops); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit focal point: %w", err)
+	}
+	return &updated, nil
+}
+
+// cropMedia cuts an image's crops around its current focal point, then
+// removes crops cut around a previous one. The media's processing status is
+// left alone: until the new crops are saved the old ones are still served.
+func (s *MediaService) cropMedia(ctx context.Context, mediaID pgtype.UUID) error {
+	media, err := s.queries.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get media: %w", err)
+	}
+	if !media.OriginalKey.Valid {
+		return fmt.Errorf("media has no original key")
+	}
+
+	src, err := s.storage.Open(ctx, media.OriginalKey.String)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	img, err := decodeUpright(src)
+	if err != nil {
+		return err
+	}
+
+	crops, err := s.generateCrops(ctx, img, media.OriginalKey.String, focalPointOf(&media))
+	if err != nil {
+		return err
+	}
+
+	params := generated.UpdateMediaCropsParams{
+		HeroKey: renditionKey(crops, "hero"),
+		CardKey: renditionKey(crops, "card"),
+		OgKey:   renditionKey(crops, "og"),
+		ID:      mediaID,
+	}
+	updated, err := s.queries.UpdateMediaCrops(ctx, params)
+	if err != nil {
+		// An unchanged focal point rewrites the crops the row still uses
+		s.deleteReplacedCrops(ctx, &generated.Media{HeroKey: params.HeroKey, CardKey: params.CardKey, OgKey: params.OgKey}, &media)
+		return fmt.Errorf("failed to save crops: %w", err)
+	}
+
+	s.deleteReplacedCrops(ctx, &media, &updated)
+	s.deleteStaleCovers(ctx, &updated)
+	return nil
+}
+
+// deleteReplacedCrops removes the crop files before no longer refers to.
+// Failures are left for the storage check to report as orphans.
+func (s *MediaService) deleteReplacedCrops(ctx context.Context, before, after *generated.Media) {
+	current := make(map[string]bool)
+	for _, key := range storedKeys(after.HeroKey, after.CardKey, after.OgKey) {
+		current[key] = true
+	}
+	for _, key := range storedKeys(before.HeroKey, before.CardKey, before.OgKey) {
+		if !current[key] {
+			s.storage.Delete(ctx, key)
+		}
+	}
+}
+
+// deleteStaleCovers removes derived covers cut around a previous focal
+// point, which the image endpoint no longer serves
+func (s *MediaService) deleteStaleCovers(ctx context.Context, media *generated.Media) {
+	prefix := derivedImagePrefix(media.OriginalKey.String)
+	objects, err := s.storage.List(ctx, prefix)
+	if err != nil {
+		return
+	}
+	cover := "_" + FitCover + "_q"
+	code := "_" + focalCode(focalPointOf(media)) + "."
+	for _, obj := range objects {
+		if strings.Contains(obj.Key, cover) && !strings.Contains(obj.Key, code) {
+			s.storage.Delete(ctx, obj.Key)
+		}
+	}
+}
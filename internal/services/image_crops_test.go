@@ -0,0 +1,133 @@
+package services
+
+import (
+	"image"
+	"testing"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCropRect(t *testing.T) {
+	hero := ImageCrop{Name: "hero", Width: 1920, Height: 1080}
+	card := ImageCrop{Name: "card", Width: 800, Height: 800}
+
+	tests := []struct {
+		name   string
+		bounds image.Rectangle
+		crop   ImageCrop
+		focal  FocalPoint
+		want   image.Rectangle
+	}{
+		{
+			name:   "success: centred square from landscape",
+			bounds: image.Rect(0, 0, 4000, 3000),
+			crop:   card,
+			focal:  CenterFocalPoint,
+			want:   image.Rect(500, 0, 3500, 3000),
+		},
+		{
+			name:   "success: follows the focal point",
+			bounds: image.Rect(0, 0, 4000, 3000),
+			crop:   card,
+			focal:  FocalPoint{X: 0.6, Y: 0.5},
+			want:   image.Rect(900, 0, 3900, 3000),
+		},
+		{
+			name:   "success: 16:9 from portrait keeps the full width",
+			bounds: image.Rect(0, 0, 1600, 2400),
+			crop:   hero,
+			focal:  FocalPoint{X: 0.5, Y: 0.25},
+			want:   image.Rect(0, 150, 1600, 1050),
+		},
+		{
+			name:   "edge: focal point near the edge clamps to the image",
+			bounds: image.Rect(0, 0, 4000, 3000),
+			crop:   card,
+			focal:  FocalPoint{X: 0.02, Y: 0.9},
+			want:   image.Rect(0, 0, 3000, 3000),
+		},
+		{
+			name:   "edge: bounds not at the origin",
+			bounds: image.Rect(10, 20, 410, 320),
+			crop:   card,
+			focal:  FocalPoint{X: 1, Y: 0},
+			want:   image.Rect(110, 20, 410, 320),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := cropRect(tt.bounds, tt.crop, tt.focal)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCropKey(t *testing.T) {
+	og := ImageCrop{Name: "og", Width: 1200, Height: 630}
+
+	t.Run("success: named after the original and focal point", func(t *testing.T) {
+		// Act
+		got := cropKey("media/2024/01/02/a.png", og, FocalPoint{X: 0.3, Y: 0.456}, ".jpg")
+
+		// Assert
+		assert.Equal(t, "media/2024/01/02/a_og_030046.jpg", got)
+	})
+
+	t.Run("success: a new focal point gives a new key", func(t *testing.T) {
+		// Act
+		before := cropKey("media/a.jpg", og, CenterFocalPoint, ".jpg")
+		after := cropKey("media/a.jpg", og, FocalPoint{X: 0.5, Y: 0.4}, ".jpg")
+
+		// Assert
+		assert.NotEqual(t, before, after)
+	})
+}
+
+func TestFocalPointOf(t *testing.T) {
+	t.Run("success: centre when unset", func(t *testing.T) {
+		// Act
+		got := focalPointOf(&generated.Media{})
+
+		// Assert
+		assert.Equal(t, CenterFocalPoint, got)
+	})
+
+	t.Run("success: stored point", func(t *testing.T) {
+		// Act
+		got := focalPointOf(&generated.Media{
+			FocalX: pgtype.Float4{Float32: 0.25, Valid: true},
+			FocalY: pgtype.Float4{Float32: 0.75, Valid: true},
+		})
+
+		// Assert
+		assert.Equal(t, FocalPoint{X: 0.25, Y: 0.75}, got)
+	})
+}
+
+func TestObjectPosition(t *testing.T) {
+	tests := []struct {
+		name  string
+		focal FocalPoint
+		want  string
+	}{
+		{name: "success: centre", focal: CenterFocalPoint, want: "50% 50%"},
+		{name: "success: rounded to one decimal", focal: FocalPoint{X: 0.3333, Y: 0.0251}, want: "33.3% 2.5%"},
+		{name: "edge: corner", focal: FocalPoint{X: 1, Y: 0}, want: "100% 0%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := MediaResponse{FocalPoint: tt.focal}.ObjectPosition()
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
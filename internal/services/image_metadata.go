@@ -0,0 +1,584 @@
+// internal/services/image_metadata.go
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"strings"
+
+	"golang.org/x/image/draw"
+)
+
+// ImageMetadata holds the EXIF fields we keep from an uploaded photo
+type ImageMetadata struct {
+	Orientation int // EXIF orientation 1-8; 1 when absent
+	Artist      string
+	Copyright   string
+}
+
+// EXIF tags read from IFD0
+const (
+	exifTagOrientation = 0x0112
+	exifTagArtist      = 0x013B
+	exifTagCopyright   = 0x8298
+)
+
+// TIFF field types
+const (
+	tiffTypeASCII = 2
+	tiffTypeShort = 3
+)
+
+const maxExifString = 1024 // Longer credits are almost certainly garbage
+
+var (
+	exifHeader   = []byte("Exif\x00\x00")
+	iccHeader    = []byte("ICC_PROFILE\x00")
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// ReadImageMetadata reads the orientation and credits from the EXIF block of a
+// JPEG, PNG or WebP. Missing or malformed EXIF yields the defaults.
+func ReadImageMetadata(data []byte) ImageMetadata {
+	meta := ImageMetadata{Orientation: 1}
+	if tiff := findEXIF(data); tiff != nil {
+		parseEXIF(tiff, &meta)
+	}
+	return meta
+}
+
+// StripImageMetadata removes EXIF, XMP, IPTC, comments and vendor data (GPS
+// position, device and software details) from a JPEG, PNG or WebP without
+// re-encoding it. Colour profiles are kept, and a minimal EXIF block carrying
+// only the orientation is written back so the image still displays upright.
+// Other formats are returned unchanged.
+func StripImageMetadata(data []byte, orientation int) ([]byte, error) {
+	var orientationEXIF []byte
+	if orientation > 1 && orientation <= 8 {
+		orientationEXIF = buildOrientationEXIF(orientation)
+	}
+
+	switch {
+	case isJPEG(data):
+		return stripJPEG(data, orientationEXIF)
+	case isPNG(data):
+		return stripPNG(data, orientationEXIF)
+	case isWebP(data):
+		return stripWebP(data, orientationEXIF)
+	default:
+		return data, nil
+	}
+}
+
+func isJPEG(data []byte) bool {
+	return len(data) > 3 && data[0] == 0xFF && data[1] == 0xD8 && data[2] == 0xFF
+}
+
+func isPNG(data []byte) bool {
+	return bytes.HasPrefix(data, pngSignature)
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// findEXIF returns the TIFF-structured EXIF block of an image, or nil
+func findEXIF(data []byte) []byte {
+	switch {
+	case isJPEG(data):
+		segments, _, err := parseJPEG(data)
+		if err != nil {
+			return nil
+		}
+		for _, seg := range segments {
+			if seg.marker == 0xE1 && bytes.HasPrefix(seg.payload, exifHeader) {
+				return seg.payload[len(exifHeader):]
+			}
+		}
+	case isPNG(data):
+		chunks, err := parsePNG(data)
+		if err != nil {
+			return nil
+		}
+		for _, chunk := range chunks {
+			if chunk.typ == "eXIf" {
+				return chunk.data
+			}
+		}
+	case isWebP(data):
+		chunks, err := parseWebP(data)
+		if err != nil {
+			return nil
+		}
+		for _, chunk := range chunks {
+			if chunk.fourCC == "EXIF" {
+				// Some encoders keep the JPEG APP1 header
+				return bytes.TrimPrefix(chunk.data, exifHeader)
+			}
+		}
+	}
+	return nil
+}
+
+// parseEXIF reads the tags we care about from IFD0 of a TIFF block
+func parseEXIF(tiff []byte, meta *ImageMetadata) {
+	if len(tiff) < 8 {
+		return
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+	if order.Uint16(tiff[2:4]) != 42 {
+		return
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return
+	}
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		tag := order.Uint16(tiff[entry : entry+2])
+		typ := order.Uint16(tiff[entry+2 : entry+4])
+		n := order.Uint32(tiff[entry+4 : entry+8])
+		value := tiff[entry+8 : entry+12]
+
+		switch tag {
+		case exifTagOrientation:
+			if typ == tiffTypeShort {
+				if o := int(order.Uint16(value)); o >= 1 && o <= 8 {
+					meta.Orientation = o
+				}
+			}
+		case exifTagArtist:
+			meta.Artist = exifString(tiff, order, typ, n, value)
+		case exifTagCopyright:
+			meta.Copyright = exifString(tiff, order, typ, n, value)
+		}
+	}
+}
+
+// exifString decodes an ASCII field. Copyright may hold separate photographer
+// and editor notices separated by NUL; they are joined with "; ".
+func exifString(tiff []byte, order binary.ByteOrder, typ uint16, n uint32, value []byte) string {
+	if typ != tiffTypeASCII || n == 0 || n > maxExifString {
+		return ""
+	}
+
+	var raw []byte
+	if n <= 4 {
+		raw = value[:n]
+	} else {
+		offset := order.Uint32(value)
+		if uint64(offset)+uint64(n) > uint64(len(tiff)) {
+			return ""
+		}
+		raw = tiff[offset : offset+n]
+	}
+
+	var parts []string
+	for _, part := range bytes.Split(raw, []byte{0}) {
+		if s := strings.TrimSpace(strings.ToValidUTF8(string(part), "")); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// buildOrientationEXIF returns a big-endian TIFF block whose IFD0 holds only
+// the orientation tag
+func buildOrientationEXIF(orientation int) []byte {
+	tiff := make([]byte, 26)
+	copy(tiff, "MM\x00\x2a")
+	binary.BigEndian.PutUint32(tiff[4:], 8) // IFD0 offset
+	binary.BigEndian.PutUint16(tiff[8:], 1) // Entry count
+	binary.BigEndian.PutUint16(tiff[10:], exifTagOrientation)
+	binary.BigEndian.PutUint16(tiff[12:], tiffTypeShort)
+	binary.BigEndian.PutUint32(tiff[14:], 1)
+	binary.BigEndian.PutUint16(tiff[18:], uint16(orientation))
+	// Next IFD offset (bytes 22-25) stays zero
+	return tiff
+}
+
+// JPEG
+
+type jpegSegment struct {
+	marker  byte
+	raw     []byte // Marker, length and payload
+	payload []byte
+}
+
+// parseJPEG splits a JPEG into the segments before the first scan and the
+// rest of the file, starting at the SOS marker
+func parseJPEG(data []byte) ([]jpegSegment, []byte, error) {
+	if !isJPEG(data) {
+		return nil, nil, fmt.Errorf("not a JPEG")
+	}
+
+	var segments []jpegSegment
+	i := 2
+	for {
+		// Markers may be preceded by any number of 0xFF fill bytes
+		for i+1 < len(data) && data[i] == 0xFF && data[i+1] == 0xFF {
+			i++
+		}
+		if i+1 >= len(data) || data[i] != 0xFF {
+			return nil, nil, fmt.Errorf("malformed JPEG: expected marker at offset %d", i)
+		}
+
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return segments, data[i:], nil
+		}
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			// Standalone markers have no length
+			segments = append(segments, jpegSegment{marker: marker, raw: data[i : i+2]})
+			i += 2
+			continue
+		}
+
+		if i+4 > len(data) {
+			return nil, nil, fmt.Errorf("malformed JPEG: truncated segment")
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return nil, nil, fmt.Errorf("malformed JPEG: invalid segment length")
+		}
+		segments = append(segments, jpegSegment{
+			marker:  marker,
+			raw:     data[i : i+2+length],
+			payload: data[i+4 : i+2+length],
+		})
+		i += 2 + length
+	}
+}
+
+// jpegImageEnd returns the offset just past the EOI marker ending the image in
+// rest (which starts at the first SOS), or len(rest) when there is none.
+// Anything after it — MPF secondary images and vendor trailers, which carry
+// their own EXIF — is not part of the image.
+func jpegImageEnd(rest []byte) int {
+	i := 0
+	for i+1 < len(rest) {
+		if rest[i] != 0xFF {
+			i++
+			continue
+		}
+		marker := rest[i+1]
+		switch {
+		case marker == 0xD9:
+			return i + 2
+		case marker == 0xFF:
+			i++ // Fill byte
+		case marker == 0x00 || (marker >= 0xD0 && marker <= 0xD7):
+			i += 2 // Stuffed byte or restart marker inside scan data
+		default:
+			// A segment between scans (SOS, DHT, DQT, ...), skip its header
+			if i+4 > len(rest) {
+				return len(rest)
+			}
+			i += 2 + int(binary.BigEndian.Uint16(rest[i+2:i+4]))
+		}
+	}
+	return len(rest)
+}
+
+// keepJPEGSegment reports whether a header segment survives stripping
+func keepJPEGSegment(seg jpegSegment) bool {
+	switch {
+	case seg.marker == 0xE0: // JFIF
+		return true
+	case seg.marker == 0xE2: // ICC profile; MPF indexes go with the trailer they describe
+		return bytes.HasPrefix(seg.payload, iccHeader)
+	case seg.marker == 0xEE: // Adobe colour transform, needed to decode CMYK correctly
+		return true
+	case seg.marker >= 0xE1 && seg.marker <= 0xEF: // EXIF, XMP, IPTC and vendor blocks
+		return false
+	case seg.marker == 0xFE: // Comments
+		return false
+	}
+	return true
+}
+
+func stripJPEG(data, orientationEXIF []byte) ([]byte, error) {
+	segments, rest, err := parseJPEG(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write([]byte{0xFF, 0xD8})
+
+	wroteEXIF := orientationEXIF == nil
+	for _, seg := range segments {
+		if !keepJPEGSegment(seg) {
+			continue
+		}
+		// EXIF goes after JFIF, ahead of everything else
+		if !wroteEXIF && seg.marker != 0xE0 {
+			writeJPEGEXIF(out, orientationEXIF)
+			wroteEXIF = true
+		}
+		out.Write(seg.raw)
+	}
+	if !wroteEXIF {
+		writeJPEGEXIF(out, orientationEXIF)
+	}
+
+	out.Write(rest[:jpegImageEnd(rest)])
+	return out.Bytes(), nil
+}
+
+func writeJPEGEXIF(out *bytes.Buffer, tiff []byte) {
+	var header [4]byte
+	header[0], header[1] = 0xFF, 0xE1
+	binary.BigEndian.PutUint16(header[2:], uint16(2+len(exifHeader)+len(tiff)))
+	out.Write(header[:])
+	out.Write(exifHeader)
+	out.Write(tiff)
+}
+
+// PNG
+
+type pngChunk struct {
+	typ  string
+	data []byte
+	raw  []byte // Length, type, data and CRC
+}
+
+// parsePNG splits a PNG into its chunks, up to and including IEND
+func parsePNG(data []byte) ([]pngChunk, error) {
+	if !isPNG(data) {
+		return nil, fmt.Errorf("not a PNG")
+	}
+
+	var chunks []pngChunk
+	i := len(pngSignature)
+	for i+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		if length < 0 || i+12+length > len(data) {
+			return nil, fmt.Errorf("malformed PNG: invalid chunk length")
+		}
+		chunk := pngChunk{
+			typ:  string(data[i+4 : i+8]),
+			data: data[i+8 : i+8+length],
+			raw:  data[i : i+12+length],
+		}
+		chunks = append(chunks, chunk)
+		i += 12 + length
+		if chunk.typ == "IEND" {
+			return chunks, nil
+		}
+	}
+	return nil, fmt.Errorf("malformed PNG: missing IEND chunk")
+}
+
+// pngMetadataChunks hold EXIF, text (XMP is stored in iTXt) and edit times
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data, orientationEXIF []byte) ([]byte, error) {
+	chunks, err := parsePNG(data)
+	if err != nil {
+		return nil, err
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+
+	wroteEXIF := orientationEXIF == nil
+	for _, chunk := range chunks {
+		if pngMetadataChunks[chunk.typ] {
+			continue
+		}
+		// eXIf must come before the image data
+		if !wroteEXIF && (chunk.typ == "IDAT" || chunk.typ == "IEND") {
+			writePNGChunk(out, "eXIf", orientationEXIF)
+			wroteEXIF = true
+		}
+		out.Write(chunk.raw)
+	}
+
+	return out.Bytes(), nil
+}
+
+func writePNGChunk(out *bytes.Buffer, typ string, data []byte) {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(data)))
+	out.Write(length[:])
+
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(data)
+	out.WriteString(typ)
+	out.Write(data)
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	out.Write(sum[:])
+}
+
+// WebP
+
+type webpChunk struct {
+	fourCC string
+	data   []byte
+}
+
+// VP8X feature flags
+const (
+	webpFlagXMP  = 0x04
+	webpFlagEXIF = 0x08
+)
+
+// parseWebP splits a WebP RIFF container into its chunks
+func parseWebP(data []byte) ([]webpChunk, error) {
+	if !isWebP(data) {
+		return nil, fmt.Errorf("not a WebP")
+	}
+
+	size := int(binary.LittleEndian.Uint32(data[4:8])) + 8
+	if size > len(data) {
+		return nil, fmt.Errorf("malformed WebP: truncated file")
+	}
+
+	var chunks []webpChunk
+	i := 12
+	for i+8 <= size {
+		length := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		if length < 0 || i+8+length > size {
+			return nil, fmt.Errorf("malformed WebP: invalid chunk length")
+		}
+		chunks = append(chunks, webpChunk{
+			fourCC: string(data[i : i+4]),
+			data:   data[i+8 : i+8+length],
+		})
+		i += 8 + length + length%2 // Chunks are padded to an even size
+	}
+	return chunks, nil
+}
+
+func stripWebP(data, orientationEXIF []byte) ([]byte, error) {
+	chunks, err := parseWebP(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// EXIF is only valid in the extended format, which simple files lack
+	extended := len(chunks) > 0 && chunks[0].fourCC == "VP8X" && len(chunks[0].data) >= 1
+	if !extended {
+		orientationEXIF = nil
+	}
+
+	body := bytes.NewBuffer(make([]byte, 0, len(data)))
+	body.WriteString("WEBP")
+	for i, chunk := range chunks {
+		switch {
+		case chunk.fourCC == "EXIF" || chunk.fourCC == "XMP ":
+			continue
+		case i == 0 && extended:
+			header := bytes.Clone(chunk.data)
+			header[0] &^= webpFlagEXIF | webpFlagXMP
+			if orientationEXIF != nil {
+				header[0] |= webpFlagEXIF
+			}
+			writeWebPChunk(body, chunk.fourCC, header)
+		default:
+			writeWebPChunk(body, chunk.fourCC, chunk.data)
+		}
+	}
+	if orientationEXIF != nil {
+		writeWebPChunk(body, "EXIF", orientationEXIF)
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, body.Len()+8))
+	out.WriteString("RIFF")
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(body.Len()))
+	out.Write(size[:])
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func writeWebPChunk(out *bytes.Buffer, fourCC string, data []byte) {
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(data)))
+	out.WriteString(fourCC)
+	out.Write(length[:])
+	out.Write(data)
+	if len(data)%2 == 1 {
+		out.WriteByte(0)
+	}
+}
+
+// Orientation
+
+// orientedSize returns the displayed size of an image stored as width x height.
+// Orientations 5-8 rotate by 90 degrees.
+func orientedSize(width, height, orientation int) (int, int) {
+	if orientation >= 5 && orientation <= 8 {
+		return height, width
+	}
+	return width, height
+}
+
+// applyOrientation rotates and flips img so it displays upright, as a viewer
+// honouring the EXIF orientation would show it
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+
+	dw, dh := orientedSize(w, h, orientation)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // Rotated 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // Mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // Transposed
+				sx, sy = y, x
+			case 6: // Rotated 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: // Transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // Rotated 90 counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			d := dst.PixOffset(x, y)
+			s := src.PixOffset(sx, sy)
+			copy(dst.Pix[d:d+4], src.Pix[s:s+4])
+		}
+	}
+
+	return dst
+}
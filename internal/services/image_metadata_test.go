@@ -0,0 +1,308 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildTestEXIF returns a little-endian TIFF block with orientation, credits
+// and a GPS IFD pointer whose data contains "GPSMARKER"
+func buildTestEXIF(orientation int, artist, copyright string) []byte {
+	const entries = 4
+	dataStart := 8 + 2 + entries*12 + 4
+	artistOffset := dataStart
+	copyrightOffset := artistOffset + len(artist) + 1
+	gpsOffset := copyrightOffset + len(copyright) + 1
+
+	tiff := make([]byte, gpsOffset)
+	copy(tiff, "II\x2a\x00")
+	binary.LittleEndian.PutUint32(tiff[4:], 8)
+	binary.LittleEndian.PutUint16(tiff[8:], entries)
+
+	entry := func(i int, tag, typ uint16, count, value uint32) {
+		off := 10 + i*12
+		binary.LittleEndian.PutUint16(tiff[off:], tag)
+		binary.LittleEndian.PutUint16(tiff[off+2:], typ)
+		binary.LittleEndian.PutUint32(tiff[off+4:], count)
+		binary.LittleEndian.PutUint32(tiff[off+8:], value)
+	}
+	entry(0, exifTagOrientation, tiffTypeShort, 1, uint32(orientation))
+	entry(1, exifTagArtist, tiffTypeASCII, uint32(len(artist)+1), uint32(artistOffset))
+	entry(2, exifTagCopyright, tiffTypeASCII, uint32(len(copyright)+1), uint32(copyrightOffset))
+	entry(3, 0x8825, 4, 1, uint32(gpsOffset)) // GPS IFD pointer
+
+	copy(tiff[artistOffset:], artist)
+	copy(tiff[copyrightOffset:], copyright)
+	return append(tiff, []byte("GPSMARKER")...)
+}
+
+func jpegAppSegment(marker byte, payload []byte) []byte {
+	seg := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(seg[2:], uint16(len(payload)+2))
+	return append(seg, payload...)
+}
+
+// encodeTestJPEG returns a 4x2 JPEG carrying EXIF, XMP, ICC, a comment and a
+// trailer after the image, like a phone camera upload
+func encodeTestJPEG(t *testing.T, exif []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2)), nil))
+	encoded := buf.Bytes()
+
+	var out bytes.Buffer
+	out.Write(encoded[:2])
+	if exif != nil {
+		out.Write(jpegAppSegment(0xE1, append([]byte("Exif\x00\x00"), exif...)))
+	}
+	out.Write(jpegAppSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta>GPSMARKER</x:xmpmeta>")))
+	out.Write(jpegAppSegment(0xE2, []byte("ICC_PROFILE\x00\x01\x01profile")))
+	out.Write(jpegAppSegment(0xFE, []byte("shot on a phone")))
+	out.Write(encoded[2:])
+	out.WriteString("MPF trailer GPSMARKER")
+	return out.Bytes()
+}
+
+// encodeTestPNGWithMetadata returns a 4x2 PNG with text and optional EXIF chunks
+func encodeTestPNGWithMetadata(t *testing.T, exif []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 2))))
+	encoded := buf.Bytes()
+
+	ihdrEnd := len(pngSignature) + 25
+	var out bytes.Buffer
+	out.Write(encoded[:ihdrEnd])
+	writePNGChunk(&out, "tEXt", []byte("Comment\x00GPSMARKER"))
+	if exif != nil {
+		writePNGChunk(&out, "eXIf", exif)
+	}
+	out.Write(encoded[ihdrEnd:])
+	return out.Bytes()
+}
+
+// buildTestWebP returns an extended WebP container with EXIF and XMP chunks.
+// The image chunk is a placeholder; only the container is inspected.
+func buildTestWebP(exif []byte) []byte {
+	var body bytes.Buffer
+	body.WriteString("WEBP")
+	writeWebPChunk(&body, "VP8X", []byte{webpFlagEXIF | webpFlagXMP, 0, 0, 0, 3, 0, 0, 1, 0, 0})
+	writeWebPChunk(&body, "VP8L", []byte{0x2f, 1, 2, 3, 4}) // Odd length, padded
+	writeWebPChunk(&body, "EXIF", exif)
+	writeWebPChunk(&body, "XMP ", []byte("<x:xmpmeta>GPSMARKER</x:xmpmeta>"))
+
+	var out bytes.Buffer
+	out.WriteString("RIFF")
+	binary.Write(&out, binary.LittleEndian, uint32(body.Len()))
+	out.Write(body.Bytes())
+	return out.Bytes()
+}
+
+func TestReadImageMetadata(t *testing.T) {
+	exif := buildTestEXIF(6, "Jane Doe", "Jane Doe\x00Studio Editor")
+
+	tests := []struct {
+		name string
+		data []byte
+		want ImageMetadata
+	}{
+		{
+			name: "success: jpeg exif",
+			data: encodeTestJPEG(t, exif),
+			want: ImageMetadata{Orientation: 6, Artist: "Jane Doe", Copyright: "Jane Doe; Studio Editor"},
+		},
+		{
+			name: "success: png exif chunk",
+			data: encodeTestPNGWithMetadata(t, exif),
+			want: ImageMetadata{Orientation: 6, Artist: "Jane Doe", Copyright: "Jane Doe; Studio Editor"},
+		},
+		{
+			name: "success: webp exif chunk with app1 header",
+			data: buildTestWebP(append([]byte("Exif\x00\x00"), exif...)),
+			want: ImageMetadata{Orientation: 6, Artist: "Jane Doe", Copyright: "Jane Doe; Studio Editor"},
+		},
+		{
+			name: "edge: no exif defaults to upright",
+			data: encodeTestJPEG(t, nil),
+			want: ImageMetadata{Orientation: 1},
+		},
+		{
+			name: "edge: malformed exif is ignored",
+			data: encodeTestJPEG(t, []byte("XX\x2a\x00garbage")),
+			want: ImageMetadata{Orientation: 1},
+		},
+		{
+			name: "edge: out of range orientation is ignored",
+			data: encodeTestJPEG(t, buildTestEXIF(9, "Jane Doe", "Jane Doe")),
+			want: ImageMetadata{Orientation: 1, Artist: "Jane Doe", Copyright: "Jane Doe"},
+		},
+		{
+			name: "edge: unsupported format",
+			data: encodeTestGIF(t),
+			want: ImageMetadata{Orientation: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := ReadImageMetadata(tt.data)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStripImageMetadata(t *testing.T) {
+	exif := buildTestEXIF(6, "Jane Doe", "Jane Doe")
+
+	tests := []struct {
+		name            string
+		data            []byte
+		orientation     int
+		wantOrientation int
+		decodable       bool
+	}{
+		{
+			name:            "security: jpeg keeps only orientation",
+			data:            encodeTestJPEG(t, exif),
+			orientation:     6,
+			wantOrientation: 6,
+			decodable:       true,
+		},
+		{
+			name:            "security: upright jpeg gets no exif",
+			data:            encodeTestJPEG(t, exif),
+			orientation:     1,
+			wantOrientation: 1,
+			decodable:       true,
+		},
+		{
+			name:            "security: png text and exif chunks removed",
+			data:            encodeTestPNGWithMetadata(t, exif),
+			orientation:     6,
+			wantOrientation: 6,
+			decodable:       true,
+		},
+		{
+			name:            "security: webp exif and xmp chunks removed",
+			data:            buildTestWebP(exif),
+			orientation:     6,
+			wantOrientation: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			stripped, err := StripImageMetadata(tt.data, tt.orientation)
+
+			// Assert
+			require.NoError(t, err)
+			assert.NotContains(t, string(stripped), "GPSMARKER")
+			assert.NotContains(t, string(stripped), "Jane Doe")
+
+			meta := ReadImageMetadata(stripped)
+			assert.Equal(t, tt.wantOrientation, meta.Orientation)
+			if tt.wantOrientation == 1 {
+				assert.NotContains(t, string(stripped), "Exif\x00\x00")
+			}
+
+			if tt.decodable {
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+				require.NoError(t, err)
+				assert.Equal(t, 4, cfg.Width)
+				assert.Equal(t, 2, cfg.Height)
+			}
+		})
+	}
+
+	t.Run("success: jpeg keeps colour profile", func(t *testing.T) {
+		// Act
+		stripped, err := StripImageMetadata(encodeTestJPEG(t, exif), 1)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, string(stripped), "ICC_PROFILE")
+		assert.Equal(t, []byte{0xFF, 0xD9}, stripped[len(stripped)-2:])
+	})
+
+	t.Run("success: webp flags and size updated", func(t *testing.T) {
+		// Act
+		stripped, err := StripImageMetadata(buildTestWebP(exif), 1)
+
+		// Assert
+		require.NoError(t, err)
+		chunks, err := parseWebP(stripped)
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, "VP8X", chunks[0].fourCC)
+		assert.Zero(t, chunks[0].data[0]&(webpFlagEXIF|webpFlagXMP))
+		assert.Equal(t, "VP8L", chunks[1].fourCC)
+		assert.Equal(t, len(stripped)-8, int(binary.LittleEndian.Uint32(stripped[4:8])))
+	})
+
+	t.Run("edge: unsupported format unchanged", func(t *testing.T) {
+		gifData := encodeTestGIF(t)
+
+		// Act
+		stripped, err := StripImageMetadata(gifData, 6)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, gifData, stripped)
+	})
+
+	t.Run("failure: truncated jpeg", func(t *testing.T) {
+		data := encodeTestJPEG(t, exif)
+
+		// Act
+		_, err := StripImageMetadata(data[:10], 1)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// 2x1: red on the left, blue on the right
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		name        string
+		orientation int
+		wantSize    image.Point
+		wantFirst   color.RGBA // Top-left pixel after orientation
+	}{
+		{name: "success: upright unchanged", orientation: 1, wantSize: image.Pt(2, 1), wantFirst: red},
+		{name: "success: mirrored", orientation: 2, wantSize: image.Pt(2, 1), wantFirst: blue},
+		{name: "success: rotated 180", orientation: 3, wantSize: image.Pt(2, 1), wantFirst: blue},
+		{name: "success: rotated 90 clockwise", orientation: 6, wantSize: image.Pt(1, 2), wantFirst: red},
+		{name: "success: rotated 90 counter-clockwise", orientation: 8, wantSize: image.Pt(1, 2), wantFirst: blue},
+		{name: "edge: invalid orientation unchanged", orientation: 0, wantSize: image.Pt(2, 1), wantFirst: red},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := applyOrientation(src, tt.orientation)
+
+			// Assert
+			assert.Equal(t, tt.wantSize, got.Bounds().Size())
+			assert.Equal(t, tt.wantFirst, color.RGBAModel.Convert(got.At(0, 0)))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// internal/services/image_processing.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif" // register GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path/filepath"
+	"strings"
+
+	_ "golang.org/x/image/webp" // register WebP decoder
+
+	"golang.org/x/image/draw"
+)
+
+// ImageRendition describes a resized variant generated for uploaded images
+type ImageRendition struct {
+	Name     string // "large", "medium", "thumbnail"
+	MaxWidth int
+}
+
+// imageRenditions are generated for every uploaded image, largest first.
+// Images narrower than a rendition are not upscaled; the original is used instead.
+var imageRenditions = []ImageRendition{
+	{Name: "large", MaxWidth: 1920},
+	{Name: "medium", MaxWidth: 1024},
+	{Name: "thumbnail", MaxWidth: 300},
+}
+
+// ProcessedImage holds the results of processing an uploaded image
+type ProcessedImage struct {
+	Width      int
+	Height     int
+	Renditions map[string]string // rendition name -> storage key
+	Crops      map[string]string // crop name -> storage key
+}
+
+// ProcessImage decodes an uploaded image, records its displayed dimensions and
+// uploads upright, resized renditions and crops around focal next to the
+// original, returning their storage keys.
+func (s *MediaService) ProcessImage(ctx context.Context, src io.Reader, originalKey string, focal FocalPoint) (*ProcessedImage, error) {
+	img, err := decodeUpright(src)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	result := &ProcessedImage{
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Renditions: make(map[string]string, len(imageRenditions)),
+	}
+
+	// Keep transparency for images with alpha, otherwise JPEG is much smaller
+	contentType, ext := "image/jpeg", ".jpg"
+	if !isOpaque(img) {
+		contentType, ext = "image/png", ".png"
+	}
+
+	base := strings.TrimSuffix(originalKey, filepath.Ext(originalKey))
+	for _, rendition := range imageRenditions {
+		if result.Width <= rendition.MaxWidth {
+			continue
+		}
+
+		resized := resizeToWidth(img, rendition.MaxWidth)
+
+		var buf bytes.Buffer
+		if err := encodeImage(&buf, resized, contentType); err != nil {
+			s.deleteRenditions(ctx, result.Renditions)
+			return nil, fmt.Errorf("failed to encode %s rendition: %w", rendition.Name, err)
+		}
+
+		key := fmt.Sprintf("%s_%s%s", base, rendition.Name, ext)
+		if err := s.storage.Upload(ctx, key, &buf, ObjectOptions{ContentType: contentType, Size: int64(buf.Len())}); err != nil {
+			s.deleteRenditions(ctx, result.Renditions)
+			return nil, fmt.Errorf("failed to upload %s rendition: %w", rendition.Name, err)
+		}
+		result.Renditions[rendition.Name] = key
+	}
+
+	if result.Crops, err = s.generateCrops(ctx, img, originalKey, focal); err != nil {
+		s.deleteRenditions(ctx, result.Renditions)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// decodeUpright decodes an image with its EXIF orientation baked into the
+// pixels, since renditions and crops carry no EXIF
+func decodeUpright(src io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return applyOrientation(img, ReadImageMetadata(data).Orientation), nil
+}
+
+// deleteRenditions removes already-uploaded renditions after a failure
+func (s *MediaService) deleteRenditions(ctx context.Context, renditions map[string]string) {
+	for _, key := range renditions {
+		s.storage.Delete(ctx, key)
+	}
+}
+
+// resizeToWidth scales img down to width, preserving aspect ratio
+func resizeToWidth(img image.Image, width int) image.Image {
+	bounds := img.Bounds()
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+	return dst
+}
+
+func encodeImage(w io.Writer, img image.Image, contentType string) error {
+	if contentType == "image/png" {
+		return png.Encode(w, img)
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
+
+// isOpaque reports whether an image has no transparent pixels
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
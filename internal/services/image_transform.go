@@ -0,0 +1,368 @@
+// internal/services/image_transform.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/image/draw"
+)
+
+// Image transform fits
+const (
+	FitCover   = "cover"   // Fill the size exactly, cropping around the focal point
+	FitContain = "contain" // Fit within the size, keeping the whole image
+)
+
+// ImageTransformSizes are the widths and heights the image endpoint will
+// produce. Limiting them bounds how many derived images one original can
+// have in storage.
+var ImageTransformSizes = []int{80, 120, 160, 240, 320, 400, 480, 600, 640, 800, 960, 1080, 1200, 1280, 1600, 1920, 2560}
+
+// ImageTransformQualities are the encoder qualities the endpoint accepts
+var ImageTransformQualities = []int{50, 75, 90}
+
+// DefaultImageTransformQuality is used when no quality is requested
+const DefaultImageTransformQuality = 75
+
+// imageTransformFormats maps a requested format to its content type and
+// file extension
+var imageTransformFormats = map[string]struct{ contentType, ext string }{
+	"webp": {"image/webp", ".webp"},
+	"avif": {"image/avif", ".avif"},
+	"jpeg": {"image/jpeg", ".jpg"},
+}
+
+// maxConcurrentTransforms bounds how many derived images are generated at
+// once; each decodes a full-size original
+const maxConcurrentTransforms = 2
+
+// ErrInvalidTransform is returned for transform parameters outside the
+// allow-lists
+var ErrInvalidTransform = errors.New("invalid image transform")
+
+// ErrNotTransformable is returned for media the image endpoint does not
+// serve: missing, trashed or private media, videos, documents and SVGs
+var ErrNotTransformable = errors.New("media cannot be transformed")
+
+// ImageTransform is a derived image requested from the image endpoint. A
+// zero width or height is derived from the other and the original's aspect.
+type ImageTransform struct {
+	Width   int
+	Height  int
+	Fit     string
+	Format  string // "webp", "avif" or "jpeg"
+	Quality int
+}
+
+// ParseImageTransform reads w, h, fit, fm and q from a query string,
+// rejecting anything outside the allow-lists. Fit defaults to cover when
+// both sides are given and format to WebP.
+func ParseImageTransform(query url.Values) (ImageTransform, error) {
+	t := ImageTransform{
+		Fit:     query.Get("fit"),
+		Format:  query.Get("fm"),
+		Quality: DefaultImageTransformQuality,
+	}
+
+	var err error
+	if t.Width, err = parseTransformInt(query.Get("w"), ImageTransformSizes); err != nil {
+		return t, fmt.Errorf("%w: width %w", ErrInvalidTransform, err)
+	}
+	if t.Height, err = parseTransformInt(query.Get("h"), ImageTransformSizes); err != nil {
+		return t, fmt.Errorf("%w: height %w", ErrInvalidTransform, err)
+	}
+	if t.Width == 0 && t.Height == 0 {
+		return t, fmt.Errorf("%w: a width or height is required", ErrInvalidTransform)
+	}
+	if q := query.Get("q"); q != "" {
+		if t.Quality, err = parseTransformInt(q, ImageTransformQualities); err != nil {
+			return t, fmt.Errorf("%w: quality %w", ErrInvalidTransform, err)
+		}
+	}
+
+	switch t.Fit {
+	case "":
+		t.Fit = FitContain
+		if t.Width > 0 && t.Height > 0 {
+			t.Fit = FitCover
+		}
+	case FitCover:
+		if t.Width == 0 || t.Height == 0 {
+			return t, fmt.Errorf("%w: cover needs a width and height", ErrInvalidTransform)
+		}
+	case FitContain:
+	default:
+		return t, fmt.Errorf("%w: fit must be cover or contain", ErrInvalidTransform)
+	}
+
+	if t.Format == "" {
+		t.Format = "webp"
+	}
+	if _, ok := imageTransformFormats[t.Format]; !ok {
+		return t, fmt.Errorf("%w: format must be webp, avif or jpeg", ErrInvalidTransform)
+	}
+	return t, nil
+}
+
+// parseTransformInt parses an optional value that must be one of allowed
+func parseTransformInt(value string, allowed []int) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || !slices.Contains(allowed, n) {
+		return 0, fmt.Errorf("must be one of %s", joinInts(allowed))
+	}
+	return n, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ContentType is the MIME type of the derived image
+func (t ImageTransform) ContentType() string {
+	return imageTransformFormats[t.Format].contentType
+}
+
+// Query encodes the transform for the image endpoint
+func (t ImageTransform) Query() url.Values {
+	query := url.Values{}
+	if t.Width > 0 {
+		query.Set("w", strconv.Itoa(t.Width))
+	}
+	if t.Height > 0 {
+		query.Set("h", strconv.Itoa(t.Height))
+	}
+	if t.Fit != "" {
+		query.Set("fit", t.Fit)
+	}
+	if t.Format != "" {
+		query.Set("fm", t.Format)
+	}
+	if t.Quality != 0 && t.Quality != DefaultImageTransformQuality {
+		query.Set("q", strconv.Itoa(t.Quality))
+	}
+	return query
+}
+
+// derivedImagePrefix is the directory holding an original's derived images,
+// named after it like its renditions
+func derivedImagePrefix(originalKey string) string {
+	return strings.TrimSuffix(originalKey, filepath.Ext(originalKey)) + "_img/"
+}
+
+// derivedImageKey names a derived image after its parameters. Covers also
+// carry the focal point they were cut around, so moving it gives new keys.
+func derivedImageKey(originalKey string, t ImageTransform, focal FocalPoint) string {
+	name := fmt.Sprintf("%dx%d_%s_q%d", t.Width, t.Height, t.Fit, t.Quality)
+	if t.Fit == FitCover {
+		name += "_" + focalCode(focal)
+	}
+	return derivedImagePrefix(originalKey) + name + imageTransformFormats[t.Format].ext
+}
+
+// transformable reports whether the image endpoint serves media
+func transformable(media *generated.Media) bool {
+	return IsImage(media.MimeType) && needsProcessing(media.MimeType) && !media.IsPrivate && media.OriginalKey.Valid
+}
+
+// DerivedImage is a derived image ready to be served. The caller closes Body.
+type DerivedImage struct {
+	Key         string
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+// TransformImage returns a derived image of media, generating and storing it
+// on first request. Later requests are served from storage.
+func (s *MediaService) TransformImage(ctx context.Context, id pgtype.UUID, t ImageTransform) (*DerivedImage, error) {
+	media, err := s.queries.GetMediaByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotTransformable
+		}
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	if !transformable(&media) {
+		return nil, ErrNotTransformable
+	}
+
+	key := derivedImageKey(media.OriginalKey.String, t, focalPointOf(&media))
+	info, err := s.storage.Stat(ctx, key)
+	if errors.Is(err, ErrObjectNotFound) {
+		if err := s.generateDerivedImage(ctx, &media, t, key); err != nil {
+			return nil, err
+		}
+		info, err = s.storage.Stat(ctx, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat derived image: %w", err)
+	}
+
+	body, err := s.storage.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &DerivedImage{Key: key, ContentType: t.ContentType(), Size: info.Size, Body: body}, nil
+}
+
+// generateDerivedImage resizes the original and stores it under key
+func (s *MediaService) generateDerivedImage(ctx context.Context, media *generated.Media, t ImageTransform, key string) error {
+	select {
+	case s.transforms <- struct{}{}:
+		defer func() { <-s.transforms }()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	src, err := s.storage.Open(ctx, media.OriginalKey.String)
+	if err != nil {
+		return err
+	}
+	img, err := decodeUpright(src)
+	src.Close()
+	if err != nil {
+		return err
+	}
+
+	resized := transformImage(img, t, focalPointOf(media))
+
+	var buf bytes.Buffer
+	if err := encodeTransformed(ctx, &buf, resized, t); err != nil {
+		return err
+	}
+	if err := s.storage.Upload(ctx, key, &buf, ObjectOptions{ContentType: t.ContentType(), Size: int64(buf.Len())}); err != nil {
+		return fmt.Errorf("failed to store derived image: %w", err)
+	}
+	return nil
+}
+
+// transformImage scales img to the transform's size, cropping around focal
+// for covers. Images are never upscaled; smaller sources keep the requested
+// aspect at their own size.
+func transformImage(img image.Image, t ImageTransform, focal FocalPoint) image.Image {
+	bounds := img.Bounds()
+	width, height := transformSize(bounds.Dx(), bounds.Dy(), t)
+
+	src := bounds
+	if t.Fit == FitCover {
+		src = cropRect(bounds, ImageCrop{Width: width, Height: height}, focal)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Over, nil)
+	return dst
+}
+
+// transformSize returns the output size for a source of width x height
+func transformSize(width, height int, t ImageTransform) (int, int) {
+	if t.Fit == FitCover {
+		// Shrink the requested box until the source can fill it
+		scale := min(1, float64(width)/float64(t.Width), float64(height)/float64(t.Height))
+		return max(1, int(float64(t.Width)*scale)), max(1, int(float64(t.Height)*scale))
+	}
+
+	scale := 1.0
+	if t.Width > 0 {
+		scale = min(scale, float64(t.Width)/float64(width))
+	}
+	if t.Height > 0 {
+		scale = min(scale, float64(t.Height)/float64(height))
+	}
+	return max(1, int(float64(width)*scale)), max(1, int(float64(height)*scale))
+}
+
+// encodeTransformed writes img in the transform's format. JPEG is encoded
+// here; WebP and AVIF go through ffmpeg, which the video pipeline already
+// needs.
+func encodeTransformed(ctx context.Context, w io.Writer, img image.Image, t ImageTransform) error {
+	if t.Format == "jpeg" {
+		if !isOpaque(img) {
+			img = flattenOnWhite(img)
+		}
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: t.Quality})
+	}
+
+	var png bytes.Buffer
+	if err := encodeImage(&png, img, "image/png"); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	inPath, err := saveTempFile(&png, "transform_*.png")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(inPath)
+	outPath := strings.TrimSuffix(inPath, ".png") + imageTransformFormats[t.Format].ext
+	defer os.Remove(outPath)
+
+	args := []string{"-y", "-i", inPath, "-frames:v", "1"}
+	switch t.Format {
+	case "webp":
+		args = append(args, "-c:v", "libwebp", "-quality", strconv.Itoa(t.Quality))
+	case "avif":
+		// libaom's CRF runs from 0 (lossless) to 63
+		args = append(args, "-c:v", "libaom-av1", "-still-picture", "1", "-cpu-used", "6",
+			"-crf", strconv.Itoa((100-t.Quality)*63/100))
+	}
+	if err := runFFmpeg(ctx, append(args, outPath)...); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", t.Format, err)
+	}
+
+	out, err := os.Open(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to open encoded image: %w", err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(w, out); err != nil {
+		return fmt.Errorf("failed to read encoded image: %w", err)
+	}
+	return nil
+}
+
+// flattenOnWhite drops transparency for formats without it
+func flattenOnWhite(img image.Image) image.Image {
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// ImageURL returns a URL on the image endpoint. Media it does not serve
+// falls back to the thumbnail (videos) or the original (SVGs and private
+// images). Covers carry the focal point so a new one is not hidden by the
+// long-lived cache.
+func (m MediaResponse) ImageURL(t ImageTransform) string {
+	switch {
+	case !IsImage(m.MimeType):
+		return m.ThumbnailURL
+	case !m.Croppable() || m.IsPrivate:
+		return m.URL
+	}
+	query := t.Query()
+	if t.Fit == FitCover {
+		query.Set("v", focalCode(m.FocalPoint))
+	}
+	return "/img/" + m.ID.String() + "?" + query.Encode()
+}
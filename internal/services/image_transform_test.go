@@ -0,0 +1,184 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageTransform(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    ImageTransform
+		wantErr bool
+	}{
+		{
+			name:  "success: both sides default to a WebP cover",
+			query: "w=800&h=600",
+			want:  ImageTransform{Width: 800, Height: 600, Fit: FitCover, Format: "webp", Quality: 75},
+		},
+		{
+			name:  "success: one side defaults to contain",
+			query: "w=640&fm=avif&q=50",
+			want:  ImageTransform{Width: 640, Fit: FitContain, Format: "avif", Quality: 50},
+		},
+		{
+			name:  "success: explicit contain box",
+			query: "w=1920&h=1200&fit=contain&fm=jpeg&q=90",
+			want:  ImageTransform{Width: 1920, Height: 1200, Fit: FitContain, Format: "jpeg", Quality: 90},
+		},
+		{name: "error: no size", query: "fm=webp", wantErr: true},
+		{name: "error: width not allowed", query: "w=801", wantErr: true},
+		{name: "error: height not a number", query: "w=800&h=tall", wantErr: true},
+		{name: "error: quality not allowed", query: "w=800&q=100", wantErr: true},
+		{name: "error: cover without a height", query: "w=800&fit=cover", wantErr: true},
+		{name: "error: unknown fit", query: "w=800&fit=stretch", wantErr: true},
+		{name: "error: unknown format", query: "w=800&fm=gif", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			// Act
+			got, err := ParseImageTransform(query)
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTransform)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTransformSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		height     int
+		transform  ImageTransform
+		wantWidth  int
+		wantHeight int
+	}{
+		{
+			name:       "success: cover is the requested box",
+			width:      4000,
+			height:     3000,
+			transform:  ImageTransform{Width: 800, Height: 800, Fit: FitCover},
+			wantWidth:  800,
+			wantHeight: 800,
+		},
+		{
+			name:       "success: contain keeps the aspect within the box",
+			width:      4000,
+			height:     3000,
+			transform:  ImageTransform{Width: 800, Height: 800, Fit: FitContain},
+			wantWidth:  800,
+			wantHeight: 600,
+		},
+		{
+			name:       "success: contain by height only",
+			width:      3000,
+			height:     4000,
+			transform:  ImageTransform{Height: 800, Fit: FitContain},
+			wantWidth:  600,
+			wantHeight: 800,
+		},
+		{
+			name:       "edge: contain never upscales",
+			width:      400,
+			height:     300,
+			transform:  ImageTransform{Width: 1920, Fit: FitContain},
+			wantWidth:  400,
+			wantHeight: 300,
+		},
+		{
+			name:       "edge: small cover keeps the requested aspect",
+			width:      600,
+			height:     400,
+			transform:  ImageTransform{Width: 1200, Height: 800, Fit: FitCover},
+			wantWidth:  600,
+			wantHeight: 400,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			width, height := transformSize(tt.width, tt.height, tt.transform)
+
+			// Assert
+			assert.Equal(t, tt.wantWidth, width)
+			assert.Equal(t, tt.wantHeight, height)
+		})
+	}
+}
+
+func TestDerivedImageKey(t *testing.T) {
+	t.Run("success: cover carries the focal point", func(t *testing.T) {
+		// Act
+		got := derivedImageKey("media/2024/01/02/a.jpg", ImageTransform{Width: 800, Height: 600, Fit: FitCover, Format: "webp", Quality: 75}, FocalPoint{X: 0.3, Y: 0.6})
+
+		// Assert
+		assert.Equal(t, "media/2024/01/02/a_img/800x600_cover_q75_030060.webp", got)
+	})
+
+	t.Run("success: contain ignores the focal point", func(t *testing.T) {
+		// Act
+		got := derivedImageKey("private/media/b.png", ImageTransform{Width: 640, Fit: FitContain, Format: "avif", Quality: 50}, FocalPoint{X: 0.3, Y: 0.6})
+
+		// Assert
+		assert.Equal(t, "private/media/b_img/640x0_contain_q50.avif", got)
+	})
+}
+
+func TestImageURL(t *testing.T) {
+	id := pgtype.UUID{Bytes: uuid.MustParse("5f0c6b7e-8a55-4d43-9a4c-2b1f3f2d7c10"), Valid: true}
+	cover := ImageTransform{Width: 160, Height: 120, Fit: FitCover}
+
+	tests := []struct {
+		name  string
+		media MediaResponse
+		want  string
+	}{
+		{
+			name:  "success: cover versioned by focal point",
+			media: MediaResponse{ID: id, MimeType: "image/jpeg", FocalPoint: FocalPoint{X: 0.5, Y: 0.25}},
+			want:  "/img/5f0c6b7e-8a55-4d43-9a4c-2b1f3f2d7c10?fit=cover&h=120&v=050025&w=160",
+		},
+		{
+			name:  "edge: private image uses its signed original",
+			media: MediaResponse{ID: id, MimeType: "image/png", IsPrivate: true, URL: "/uploads/private/a.png?signature=x"},
+			want:  "/uploads/private/a.png?signature=x",
+		},
+		{
+			name:  "edge: SVG uses the original",
+			media: MediaResponse{ID: id, MimeType: "image/svg+xml", URL: "/uploads/media/a.svg"},
+			want:  "/uploads/media/a.svg",
+		},
+		{
+			name:  "edge: video uses its thumbnail",
+			media: MediaResponse{ID: id, MimeType: "video/mp4", URL: "/uploads/media/a.mp4", ThumbnailURL: "/uploads/media/a_thumb.jpg"},
+			want:  "/uploads/media/a_thumb.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.media.ImageURL(cover)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,9 +2,13 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"slices"
@@ -13,6 +17,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/iankencruz/threefive/database/generated"
+	"github.com/iankencruz/threefive/pkg/markdown"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -22,11 +27,15 @@ type MediaService struct {
 	queries *generated.Queries
 	storage StorageProvider
 	config  MediaConfig
+	chunks  *chunkStore
+	// transforms holds a slot per derived image being generated
+	transforms chan struct{}
 }
 
 type MediaConfig struct {
 	MaxFileSize  int64
 	AllowedTypes []string
+	ChunkDir     string // Temporary storage for resumable uploads
 }
 
 func NewMediaService(db *pgxpool.Pool, queries *generated.Queries, storage StorageProvider, config MediaConfig) *MediaService {
@@ -41,118 +50,270 @@ func NewMediaService(db *pgxpool.Pool, queries *generated.Queries, storage Stora
 			"application/pdf",
 		}
 	}
+	if config.ChunkDir == "" {
+		config.ChunkDir = filepath.Join(os.TempDir(), "threefive-uploads")
+	}
 
 	return &MediaService{
-		db:      db,
-		queries: queries,
-		storage: storage,
-		config:  config,
+		db:         db,
+		queries:    queries,
+		storage:    storage,
+		config:     config,
+		chunks:     newChunkStore(config.ChunkDir),
+		transforms: make(chan struct{}, maxConcurrentTransforms),
 	}
 }
 
+// ErrUploadNotFound is returned for a direct or chunked upload that does not
+// exist, belongs to another user or has expired
+var ErrUploadNotFound = errors.New("upload not found or expired")
+
+// UploadRequest describes a file the browser is about to upload in pieces or
+// straight to storage
+type UploadRequest struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+}
+
+// UploadOptions controls how UploadMedia stores a file
+type UploadOptions struct {
+	// PreserveMetadata keeps EXIF/XMP (including GPS) on image originals;
+	// by default it is stripped and only the orientation is kept
+	PreserveMetadata bool
+	// AllowDuplicate stores the file even when identical content is already
+	// in the library; otherwise a DuplicateMediaError is returned
+	AllowDuplicate bool
+}
+
 // UploadMedia handles file upload and creates media record
-func (s *MediaService) UploadMedia(ctx context.Context, file *multipart.FileHeader, altText string, uploadedBy pgtype.UUID) (*generated.Media, error) {
+func (s *MediaService) UploadMedia(ctx context.Context, file *multipart.FileHeader, altText string, uploadedBy pgtype.UUID, opts UploadOptions) (*generated.Media, error) {
+	return s.storeUpload(ctx, uploadSource{
+		filename:     file.Filename,
+		size:         file.Size,
+		declaredType: file.Header.Get("Content-Type"),
+		open: func() (io.ReadCloser, error) {
+			return file.Open()
+		},
+	}, altText, uploadedBy, opts)
+}
+
+// uploadSource is a received file that has not been stored yet. It is opened
+// once to inspect the content and again to store it.
+type uploadSource struct {
+	filename     string
+	size         int64
+	declaredType string // Client supplied, checked against the content
+	open         func() (io.ReadCloser, error)
+}
+
+// storeUpload validates, stores and records a received file
+func (s *MediaService) storeUpload(ctx context.Context, file uploadSource, altText string, uploadedBy pgtype.UUID, opts UploadOptions) (*generated.Media, error) {
 	// Validate file size
-	if file.Size > s.config.MaxFileSize {
+	if file.size > s.config.MaxFileSize {
 		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)
 	}
 
-	// Validate file type
-	mimeType := file.Header.Get("Content-Type")
-	if !s.isAllowedType(mimeType) {
-		return nil, fmt.Errorf("file type %s is not allowed", mimeType)
+	// Detect the real type from the content; the declared type is client controlled
+	inspected, err := s.inspectUpload(file)
+	if err != nil {
+		return nil, err
 	}
+	if err := s.prepareUpload(inspected, opts); err != nil {
+		return nil, err
+	}
+	mimeType := inspected.MimeType
 
-	// Generate unique storage key
-	storageKey := GenerateStorageKey(file.Filename)
+	// The same file already in the library is offered instead of a second copy
+	contentHash, err := uploadChecksum(file)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDuplicate(ctx, contentHash, opts); err != nil {
+		return nil, err
+	}
+
+	storageKey := storageKeyFor(file.filename, mimeType)
 
-	// Upload file using storage provider
-	uploadedKey, err := s.storage.Upload(ctx, file, storageKey)
+	// Upload file using storage provider (sanitized or stripped content replaces the original)
+	fileSize := file.size
+	if inspected.Sanitized != nil {
+		fileSize = int64(len(inspected.Sanitized))
+		err = s.storage.Upload(ctx, storageKey, bytes.NewReader(inspected.Sanitized), ObjectOptions{ContentType: mimeType, Size: fileSize})
+	} else {
+		err = s.uploadWithType(ctx, file, storageKey, mimeType)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
 
-	// Get image dimensions if it's an image
-	var width, height pgtype.Int4
-	if strings.HasPrefix(mimeType, "image/") {
-		// TODO: Implement image dimension detection using image.DecodeConfig
-	}
-
-	// Process video thumbnail if it's a video
-	var thumbnailKey pgtype.Text
-	if strings.HasPrefix(mimeType, "video/") {
-		if thumbKey, err := s.ProcessVideoThumbnail(ctx, file, uploadedKey); err == nil {
-			thumbnailKey = pgtype.Text{String: thumbKey, Valid: true}
-		} else {
-			// Log warning but don't fail the upload
-			fmt.Printf("Warning: failed to generate video thumbnail: %v\n", err)
+	return s.createMediaRecord(ctx, mediaRecord{
+		storageKey:       storageKey,
+		originalFilename: file.filename,
+		fileSize:         fileSize,
+		contentHash:      contentHash,
+		inspected:        inspected,
+		altText:          altText,
+		uploadedBy:       uploadedBy,
+	})
+}
+
+// prepareUpload checks the detected type is allowed and strips photo metadata
+// into inspected.Sanitized unless the uploader opted to keep it
+func (s *MediaService) prepareUpload(inspected *InspectedUpload, opts UploadOptions) error {
+	if !s.isAllowedType(inspected.MimeType) {
+		return fmt.Errorf("file type %s is not allowed", inspected.MimeType)
+	}
+
+	// Photos lose their location and device metadata unless the uploader opts in
+	if inspected.raster != nil && !opts.PreserveMetadata {
+		stripped, err := StripImageMetadata(inspected.raster, inspected.Metadata.Orientation)
+		if err != nil {
+			return fmt.Errorf("failed to strip image metadata: %w", err)
 		}
+		inspected.Sanitized = stripped
+	}
+	return nil
+}
+
+// storageKeyFor generates a unique storage key, using the extension of the
+// detected type rather than the one the client named the file with
+func storageKeyFor(filename, mimeType string) string {
+	storageKey := GenerateStorageKey(filename)
+	if ext := GetExtensionFromMimeType(mimeType); ext != "" {
+		storageKey = strings.TrimSuffix(storageKey, filepath.Ext(storageKey)) + ext
 	}
+	return storageKey
+}
+
+// mediaRecord describes a stored upload that is ready to become a media row
+type mediaRecord struct {
+	storageKey       string
+	originalFilename string
+	fileSize         int64
+	contentHash      string // SHA-256 of the stored content
+	inspected        *InspectedUpload
+	altText          string
+	uploadedBy       pgtype.UUID
+}
+
+// createMediaRecord creates the media row and its processing job together.
+// The stored object is removed if either cannot be created.
+func (s *MediaService) createMediaRecord(ctx context.Context, rec mediaRecord) (*generated.Media, error) {
+	inspected := rec.inspected
+	mimeType := inspected.MimeType
+	storageKey := rec.storageKey
 
-	// Determine storage type
-	var storageType string
-	switch s.storage.(type) {
-	case *S3Storage:
-		storageType = "s3"
-	case *LocalStorage:
-		storageType = "local"
-	default:
-		storageType = "unknown"
+	var width, height pgtype.Int4
+	if inspected.Width > 0 && inspected.Height > 0 {
+		width = pgtype.Int4{Int32: int32(inspected.Width), Valid: true}
+		height = pgtype.Int4{Int32: int32(inspected.Height), Valid: true}
 	}
 
-	// Get S3 details if applicable
-	var s3Bucket, s3Region pgtype.Text
-	if s3Storage, ok := s.storage.(*S3Storage); ok {
-		s3Bucket = pgtype.Text{String: s3Storage.bucket, Valid: true}
-		s3Region = pgtype.Text{String: s3Storage.region, Valid: true}
+	// Renditions and video thumbnails are generated by the media job workers
+	processingStatus := MediaStatusReady
+	if needsProcessing(mimeType) {
+		processingStatus = MediaStatusPending
 	}
 
+	// Record where the file is stored (bucket and region for S3)
+	location := LocationOf(s.storage)
+
 	// Create media record in database
 	mediaID := uuid.New()
 	var pgMediaID pgtype.UUID
 	if err := pgMediaID.Scan(mediaID.String()); err != nil {
+		s.storage.Delete(ctx, storageKey)
 		return nil, fmt.Errorf("failed to convert media ID: %w", err)
 	}
 
 	// Prepare optional fields
 	var pgAltText pgtype.Text
-	if altText != "" {
-		pgAltText = pgtype.Text{String: altText, Valid: true}
+	if rec.altText != "" {
+		pgAltText = pgtype.Text{String: rec.altText, Valid: true}
 	}
 
 	var pgOriginalKey pgtype.Text
-	pgOriginalKey = pgtype.Text{String: uploadedKey, Valid: true}
+	pgOriginalKey = pgtype.Text{String: storageKey, Valid: true}
+
+	filename := filepath.Base(storageKey)
 
-	filename := filepath.Base(uploadedKey)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		s.storage.Delete(ctx, storageKey)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
 
-	media, err := s.queries.CreateMedia(ctx, generated.CreateMediaParams{
+	qtx := s.queries.WithTx(tx)
+
+	media, err := qtx.CreateMedia(ctx, generated.CreateMediaParams{
 		ID:               pgMediaID,
 		Filename:         filename,
-		OriginalFilename: file.Filename,
+		OriginalFilename: rec.originalFilename,
 		MimeType:         mimeType,
-		FileSize:         file.Size,
+		FileSize:         rec.fileSize,
 		Width:            width,
 		Height:           height,
-		StorageType:      storageType,
-		S3Bucket:         s3Bucket,
-		S3Region:         s3Region,
+		StorageType:      location.Type,
+		S3Bucket:         location.bucketText(),
+		S3Region:         location.regionText(),
 		OriginalKey:      pgOriginalKey,
-		ThumbnailKey:     thumbnailKey, // ADD THIS LINE
 		AltText:          pgAltText,
-		UploadedBy:       uploadedBy,
+		UploadedBy:       rec.uploadedBy,
+		ProcessingStatus: processingStatus,
+		Copyright:        pgtype.Text{String: inspected.Metadata.Copyright, Valid: inspected.Metadata.Copyright != ""},
+		Artist:           pgtype.Text{String: inspected.Metadata.Artist, Valid: inspected.Metadata.Artist != ""},
+		ContentHash:      pgtype.Text{String: rec.contentHash, Valid: rec.contentHash != ""},
 	})
 	if err != nil {
-		// Clean up uploaded files if database insert fails
-		s.storage.Delete(ctx, uploadedKey)
-		if thumbnailKey.Valid {
-			s.storage.Delete(ctx, thumbnailKey.String)
-		}
+		// Clean up uploaded file if database insert fails
+		s.storage.Delete(ctx, storageKey)
 		return nil, fmt.Errorf("failed to create media record: %w", err)
 	}
 
+	if processingStatus == MediaStatusPending {
+		if err := enqueueMediaJob(ctx, qtx, media.ID, MediaJobProcess); err != nil {
+			s.storage.Delete(ctx, storageKey)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		s.storage.Delete(ctx, storageKey)
+		return nil, fmt.Errorf("failed to commit media upload: %w", err)
+	}
+
 	return &media, nil
 }
 
+// inspectUpload opens the uploaded file and sniffs its content
+func (s *MediaService) inspectUpload(file uploadSource) (*InspectedUpload, error) {
+	src, err := file.open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	return InspectUpload(src, file.declaredType)
+}
+
+// uploadWithType stores the uploaded file with the detected content type
+func (s *MediaService) uploadWithType(ctx context.Context, file uploadSource, key, contentType string) error {
+	src, err := file.open()
+	if err != nil {
+		return fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	return s.storage.Upload(ctx, key, src, ObjectOptions{ContentType: contentType, Size: file.size})
+}
+
+func renditionKey(renditions map[string]string, name string) pgtype.Text {
+	key, ok := renditions[name]
+	return pgtype.Text{String: key, Valid: ok}
+}
+
 // GetMediaByID retrieves a media file by ID
 func (s *MediaService) GetMediaByID(ctx context.Context, id pgtype.UUID) (*generated.Media, error) {
 	media, err := s.queries.GetMediaByID(ctx, id)
@@ -233,8 +394,19 @@ func (s *MediaService) UpdateMediaAltText(ctx context.Context, id pgtype.UUID, a
 	return &media, nil
 }
 
-// DeleteMedia soft deletes a media file
-func (s *MediaService) DeleteMedia(ctx context.Context, id pgtype.UUID) error {
+// DeleteMedia soft deletes a media file. Media that content still uses is
+// refused with a MediaInUseError unless force is set.
+func (s *MediaService) DeleteMedia(ctx context.Context, id pgtype.UUID, force bool) error {
+	if !force {
+		refs, err := s.GetMediaReferences(ctx, id)
+		if err != nil {
+			return err
+		}
+		if len(refs) > 0 {
+			return &MediaInUseError{References: refs}
+		}
+	}
+
 	err := s.queries.SoftDeleteMedia(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete media: %w", err)
@@ -251,59 +423,119 @@ func (s *MediaService) RestoreMedia(ctx context.Context, id pgtype.UUID) error {
 	return nil
 }
 
-// HardDeleteMedia permanently deletes a media file and removes from storage
+// MediaTrashRetention is how long soft-deleted media is kept before
+// PurgeOldDeletedMedia removes it for good
+const MediaTrashRetention = 30 * 24 * time.Hour
+
+// ErrMediaFilesRemain is returned when media was deleted but some of its
+// files could not be removed from storage; the storage check reaps them later
+var ErrMediaFilesRemain = errors.New("media deleted but its files could not be removed")
+
+// ErrMediaNotInTrash is returned when permanently deleting media that has
+// not been soft deleted first
+var ErrMediaNotInTrash = errors.New("media is not in the trash")
+
+// ListDeletedMedia lists media in the trash, most recently deleted first
+func (s *MediaService) ListDeletedMedia(ctx context.Context, limit, offset int32) ([]generated.Media, error) {
+	mediaList, err := s.queries.GetDeletedMedia(ctx, generated.GetDeletedMediaParams{
+		LimitVal:  limit,
+		OffsetVal: offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deleted media: %w", err)
+	}
+	return mediaList, nil
+}
+
+// CountDeletedMedia returns the number of media items in the trash
+func (s *MediaService) CountDeletedMedia(ctx context.Context) (int64, error) {
+	count, err := s.queries.CountDeletedMedia(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted media: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteTrashedMedia permanently deletes media from the trash ahead of
+// PurgeOldDeletedMedia
+func (s *MediaService) DeleteTrashedMedia(ctx context.Context, id pgtype.UUID) error {
+	media, err := s.queries.GetMediaByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get media: %w", err)
+	}
+	if media.DeletedAt == nil {
+		return ErrMediaNotInTrash
+	}
+	return s.hardDelete(ctx, &media)
+}
+
+// HardDeleteMedia permanently deletes a media file, including soft-deleted
+// media, and removes its original and every derived file from storage
 func (s *MediaService) HardDeleteMedia(ctx context.Context, id pgtype.UUID) error {
-	// Get media to find storage key
-	media, err := s.GetMediaByID(ctx, id)
+	media, err := s.queries.GetMediaByIDIncludingDeleted(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get media: %w", err)
 	}
+	return s.hardDelete(ctx, &media)
+}
 
-	// Delete from database
-	err = s.queries.HardDeleteMedia(ctx, id)
+// hardDelete removes the row before its files, so a failure leaves
+// unreferenced files behind rather than a row pointing at missing ones
+func (s *MediaService) hardDelete(ctx context.Context, media *generated.Media) error {
+	keys, err := s.mediaObjectKeys(ctx, media)
 	if err != nil {
+		return err
+	}
+
+	if err := s.queries.HardDeleteMedia(ctx, media.ID); err != nil {
 		return fmt.Errorf("failed to delete media from database: %w", err)
 	}
 
-	// Delete from storage
-	if media.OriginalKey.Valid {
-		if err := s.storage.Delete(ctx, media.OriginalKey.String); err != nil {
-			fmt.Printf("Warning: failed to delete file from storage: %v\n", err)
+	var errs []error
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
 		}
 	}
-
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%w: %w", ErrMediaFilesRemain, err)
+	}
 	return nil
 }
 
-// PurgeOldDeletedMedia permanently deletes media files deleted more than 30 days ago
-func (s *MediaService) PurgeOldDeletedMedia(ctx context.Context) error {
-	// Get list of deleted media before purging
-	deletedMedia, err := s.queries.GetDeletedMedia(ctx, generated.GetDeletedMediaParams{
-		LimitVal:  1000,
-		OffsetVal: 0,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to get deleted media: %w", err)
-	}
+// PurgeOldDeletedMedia permanently deletes media soft-deleted more than
+// MediaTrashRetention ago, returning how many were purged. Files that could
+// not be removed are reported in the error without stopping the purge.
+func (s *MediaService) PurgeOldDeletedMedia(ctx context.Context) (int, error) {
+	const batchSize = 100
+	before := time.Now().Add(-MediaTrashRetention)
+
+	purged := 0
+	var errs []error
+	for {
+		batch, err := s.queries.ListMediaDeletedBefore(ctx, generated.ListMediaDeletedBeforeParams{
+			DeletedBefore: &before,
+			LimitVal:      batchSize,
+		})
+		if err != nil {
+			return purged, fmt.Errorf("failed to get deleted media: %w", err)
+		}
 
-	// Delete physical files older than 30 days
-	thirtyDaysAgo := time.Now().Add(-30 * 24 * time.Hour)
-	for _, media := range deletedMedia {
-		// Fixed: DeletedAt is a *time.Time pointer, not pgtype.Timestamp
-		if media.DeletedAt != nil && media.DeletedAt.Before(thirtyDaysAgo) {
-			if media.OriginalKey.Valid {
-				s.storage.Delete(ctx, media.OriginalKey.String) // Ignore errors
+		for _, media := range batch {
+			if err := s.hardDelete(ctx, &media); err != nil {
+				// A row that could not be deleted would be fetched again
+				if !errors.Is(err, ErrMediaFilesRemain) {
+					return purged, errors.Join(append(errs, err)...)
+				}
+				errs = append(errs, fmt.Errorf("media %s: %w", media.ID.String(), err))
 			}
+			purged++
 		}
-	}
 
-	// Purge from database
-	err = s.queries.PurgeOldDeletedMedia(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to purge old deleted media: %w", err)
+		if len(batch) < batchSize {
+			return purged, errors.Join(errs...)
+		}
 	}
-
-	return nil
 }
 
 // Media Relations
@@ -412,12 +644,45 @@ func (s *MediaService) isAllowedType(mimeType string) bool {
 	return slices.Contains(s.config.AllowedTypes, mimeType)
 }
 
-// GetMediaURL returns the public URL for a media file
+// GetMediaURL returns the URL for a media file, signed if it is private
 func (s *MediaService) GetMediaURL(media *generated.Media) string {
 	if !media.OriginalKey.Valid {
 		return ""
 	}
-	return s.storage.GetURL(media.OriginalKey.String)
+	return s.mediaURL(media, media.OriginalKey.String)
+}
+
+// MarkdownResolver returns a resolver that maps media:<id> references in
+// markdown bodies to the stored media URL and alt text. Bodies are public, so
+// private media is left out rather than given a signed URL that would leak
+// the file and expire in the page.
+func (s *MediaService) MarkdownResolver(ctx context.Context) markdown.MediaResolver {
+	return func(id uuid.UUID) (string, string, bool) {
+		media, err := s.GetMediaByID(ctx, pgtype.UUID{Bytes: id, Valid: true})
+		if err != nil || media.DeletedAt != nil || media.IsPrivate {
+			return "", "", false
+		}
+		return s.GetMediaURL(media), media.AltText.String, true
+	}
+}
+
+// PrivateEmbeddedMedia returns the private media a markdown body embeds,
+// which MarkdownResolver leaves out of the rendered page
+func (s *MediaService) PrivateEmbeddedMedia(ctx context.Context, body string) ([]generated.Media, error) {
+	ids := markdown.MediaIDs(body)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	mediaIDs := make([]pgtype.UUID, len(ids))
+	for i, id := range ids {
+		mediaIDs[i] = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	media, err := s.queries.GetMediaByIDs(ctx, mediaIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedded media: %w", err)
+	}
+	return slices.DeleteFunc(media, func(m generated.Media) bool { return !m.IsPrivate }), nil
 }
 
 // UpdateMedia updates media metadata (alt text)
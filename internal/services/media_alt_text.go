@@ -0,0 +1,167 @@
+// internal/services/media_alt_text.go
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AltTextIssue is why an image's alt text fails the accessibility audit
+type AltTextIssue string
+
+const (
+	AltTextOK       AltTextIssue = ""
+	AltTextMissing  AltTextIssue = "missing"
+	AltTextFilename AltTextIssue = "filename"
+	AltTextTooShort AltTextIssue = "too_short"
+)
+
+// minAltTextLength is the fewest characters that can describe an image
+const minAltTextLength = 10
+
+var (
+	// imageExtension matches alt text ending like a file name, e.g. "hero.jpg"
+	imageExtension = regexp.MustCompile(`(?i)\.(jpe?g|png|gif|webp|avif|heic|svg|tiff?|bmp)$`)
+	// cameraFilename matches names cameras and phones give photos, e.g. "IMG_2041"
+	cameraFilename = regexp.MustCompile(`(?i)^(img|dsc|dscn|dscf|pxl|dcim|mvimg|screenshot)[ _-]?\d`)
+	// slugLike matches a single word joined by underscores or hyphens, or
+	// with a run of digits, e.g. "hero-banner-v2" or "photo0012"
+	slugLike = regexp.MustCompile(`^\S*([_-]\S*|\d{3,}\S*)$`)
+)
+
+// CheckAltText returns what is wrong with an image's alt text, AltTextOK if
+// it reads like a description
+func CheckAltText(altText, originalFilename string) AltTextIssue {
+	alt := strings.TrimSpace(altText)
+	if alt == "" {
+		return AltTextMissing
+	}
+	if looksLikeFilename(alt, originalFilename) {
+		return AltTextFilename
+	}
+	if utf8.RuneCountInString(alt) < minAltTextLength {
+		return AltTextTooShort
+	}
+	return AltTextOK
+}
+
+func looksLikeFilename(alt, originalFilename string) bool {
+	base := strings.TrimSuffix(originalFilename, filepath.Ext(originalFilename))
+	if strings.EqualFold(alt, originalFilename) || strings.EqualFold(alt, base) {
+		return true
+	}
+	return imageExtension.MatchString(alt) || cameraFilename.MatchString(alt) || slugLike.MatchString(alt)
+}
+
+// Label is a short description of the issue
+func (i AltTextIssue) Label() string {
+	switch i {
+	case AltTextMissing:
+		return "Missing"
+	case AltTextFilename:
+		return "Looks like a filename"
+	case AltTextTooShort:
+		return "Too short"
+	}
+	return ""
+}
+
+// severity orders the report, worst first
+func (i AltTextIssue) severity() int {
+	switch i {
+	case AltTextMissing:
+		return 0
+	case AltTextFilename:
+		return 1
+	}
+	return 2
+}
+
+// AltTextAuditItem is an image published without usable alt text and
+// everywhere it appears
+type AltTextAuditItem struct {
+	Media      MediaResponse
+	Issue      AltTextIssue
+	References []MediaReference
+}
+
+// AuditAltText lists every image shown by published projects, blog posts and
+// pages, in their bodies or as their social share image, whose alt text is
+// missing or poor. Missing alt text is listed first.
+func (s *MediaService) AuditAltText(ctx context.Context) ([]AltTextAuditItem, error) {
+	return s.auditAltText(ctx, pgtype.UUID{})
+}
+
+// UpdateAuditedAltText saves alt text from the audit report, returning the
+// image's audit item if the new alt text still fails, or nil once it passes
+func (s *MediaService) UpdateAuditedAltText(ctx context.Context, id pgtype.UUID, altText string) (*AltTextAuditItem, error) {
+	if _, err := s.UpdateMediaAltText(ctx, id, strings.TrimSpace(altText)); err != nil {
+		return nil, err
+	}
+
+	items, err := s.auditAltText(ctx, id)
+	if err != nil || len(items) == 0 {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// auditAltText audits the published images, or just id when it is valid
+func (s *MediaService) auditAltText(ctx context.Context, id pgtype.UUID) ([]AltTextAuditItem, error) {
+	rows, err := s.queries.ListPublishedImageReferences(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list published images: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	refs := make(map[pgtype.UUID][]MediaReference)
+	var ids []pgtype.UUID
+	for _, row := range rows {
+		if _, ok := refs[row.MediaID]; !ok {
+			ids = append(ids, row.MediaID)
+		}
+		refs[row.MediaID] = append(refs[row.MediaID], MediaReference{
+			EntityType: row.EntityType,
+			EntityID:   row.EntityID,
+			Field:      row.Field,
+			Title:      row.Title,
+			Slug:       row.Slug,
+		})
+	}
+
+	media, err := s.queries.GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+
+	var items []AltTextAuditItem
+	for i := range media {
+		issue := CheckAltText(media[i].AltText.String, media[i].OriginalFilename)
+		if issue == AltTextOK {
+			continue
+		}
+		items = append(items, AltTextAuditItem{
+			Media:      s.ToMediaResponse(&media[i]),
+			Issue:      issue,
+			References: refs[media[i].ID],
+		})
+	}
+
+	slices.SortStableFunc(items, func(a, b AltTextAuditItem) int {
+		return cmp.Or(
+			cmp.Compare(a.Issue.severity(), b.Issue.severity()),
+			strings.Compare(a.Media.OriginalFilename, b.Media.OriginalFilename),
+		)
+	})
+	return items, nil
+}
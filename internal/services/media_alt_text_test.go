@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAltText(t *testing.T) {
+	tests := []struct {
+		name     string
+		altText  string
+		filename string
+		want     AltTextIssue
+	}{
+		{name: "success: descriptive alt text", altText: "Designer sketching wireframes at a desk", filename: "IMG_2041.jpg", want: AltTextOK},
+		{name: "success: short sentence", altText: "Red bicycle", filename: "bike.jpg", want: AltTextOK},
+		{name: "error: missing", altText: "", filename: "hero.jpg", want: AltTextMissing},
+		{name: "error: only whitespace", altText: "   ", filename: "hero.jpg", want: AltTextMissing},
+		{name: "error: original filename", altText: "Studio Portrait.JPG", filename: "studio portrait.jpg", want: AltTextFilename},
+		{name: "error: filename without extension", altText: "studio portrait", filename: "studio portrait.jpg", want: AltTextFilename},
+		{name: "error: image extension", altText: "final version.png", filename: "upload.png", want: AltTextFilename},
+		{name: "error: camera name", altText: "IMG 2041 edited", filename: "photo.jpg", want: AltTextFilename},
+		{name: "error: slug", altText: "hero-banner-v2", filename: "banner.jpg", want: AltTextFilename},
+		{name: "error: digits", altText: "photo0012", filename: "upload.jpg", want: AltTextFilename},
+		{name: "error: too short", altText: "Logo", filename: "brand.svg", want: AltTextTooShort},
+		{name: "edge: whitespace is trimmed before measuring", altText: "  A cat  ", filename: "cat.jpg", want: AltTextTooShort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := CheckAltText(tt.altText, tt.filename)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestMediaService_AuditAltText tests which published images the audit finds
+func TestMediaService_AuditAltText(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	mediaService := NewMediaService(pool, queries, nil, MediaConfig{})
+	blogService := NewBlogService(queries, mediaService)
+	author := createTestUser(t, ctx, queries, "author@example.com", "AuthorPass123!", "Blog", "Author")
+
+	published := createTestMedia(t, ctx, queries, "site-visit.jpg", "image/jpeg")
+	draft := createTestMedia(t, ctx, queries, "draft-only.jpg", "image/jpeg")
+	described := createTestMedia(t, ctx, queries, "described.jpg", "image/jpeg")
+	_, err := mediaService.UpdateMediaAltText(ctx, described.ID, "Builders pouring the slab at dawn")
+	require.NoError(t, err, "failed to set alt text")
+
+	for _, post := range []struct {
+		slug   string
+		status string
+		media  []uuid.UUID
+	}{
+		{"site-visit", "published", []uuid.UUID{published.ID.Bytes, described.ID.Bytes}},
+		{"draft-post", "draft", []uuid.UUID{draft.ID.Bytes}},
+	} {
+		body := "Notes from the day."
+		for _, id := range post.media {
+			body += "\n\n![](media:" + id.String() + ")"
+		}
+		_, err := blogService.CreateBlog(ctx, &CreateBlogRequest{
+			Title:    "Post " + post.slug,
+			Slug:     post.slug,
+			Body:     body,
+			Status:   post.status,
+			AuthorID: author.ID.Bytes,
+		})
+		require.NoError(t, err, "failed to create post %s", post.slug)
+	}
+
+	t.Run("success: image embedded in a published body is audited", func(t *testing.T) {
+		// Act
+		items, err := mediaService.AuditAltText(ctx)
+
+		// Assert
+		require.NoError(t, err)
+		require.Len(t, items, 1)
+		assert.Equal(t, published.ID, items[0].Media.ID)
+		assert.Equal(t, AltTextMissing, items[0].Issue)
+		require.Len(t, items[0].References, 1)
+		assert.Equal(t, "blog", items[0].References[0].EntityType)
+		assert.Equal(t, "body", items[0].References[0].Field)
+		assert.Equal(t, "site-visit", items[0].References[0].Slug)
+	})
+}
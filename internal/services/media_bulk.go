@@ -0,0 +1,232 @@
+// internal/services/media_bulk.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxBulkMedia caps how many media items one bulk operation touches
+const MaxBulkMedia = 500
+
+// ErrNoMediaSelected is returned by bulk operations given no media
+var ErrNoMediaSelected = errors.New("no media selected")
+
+// ErrTooManyMediaSelected is returned by bulk operations given more than
+// MaxBulkMedia items
+var ErrTooManyMediaSelected = fmt.Errorf("select at most %d media items at a time", MaxBulkMedia)
+
+// ErrNoTagsGiven is returned by BulkTagMedia given no tag names
+var ErrNoTagsGiven = errors.New("enter at least one tag")
+
+// BulkDeleteResult is the outcome of BulkDeleteMedia
+type BulkDeleteResult struct {
+	Deleted []pgtype.UUID
+	InUse   []pgtype.UUID // Kept because content still uses them
+}
+
+// AltTextUpdate is the new alt text for one media item
+type AltTextUpdate struct {
+	ID      pgtype.UUID
+	AltText string
+}
+
+func checkBulkSelection(n int) error {
+	switch {
+	case n == 0:
+		return ErrNoMediaSelected
+	case n > MaxBulkMedia:
+		return ErrTooManyMediaSelected
+	}
+	return nil
+}
+
+// GetMediaByIDs returns the live media among ids, newest first
+func (s *MediaService) GetMediaByIDs(ctx context.Context, ids []pgtype.UUID) ([]generated.Media, error) {
+	if err := checkBulkSelection(len(ids)); err != nil {
+		return nil, err
+	}
+
+	media, err := s.queries.GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return media, nil
+}
+
+// BulkDeleteMedia moves media to the trash in one transaction. Unless
+// forced, media that content still uses is kept and reported in InUse.
+func (s *MediaService) BulkDeleteMedia(ctx context.Context, ids []pgtype.UUID, force bool) (*BulkDeleteResult, error) {
+	if err := checkBulkSelection(len(ids)); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	result := &BulkDeleteResult{}
+	unused := ids
+	if !force {
+		unused = make([]pgtype.UUID, 0, len(ids))
+		for _, id := range ids {
+			refs, err := qtx.ListMediaReferences(ctx, id)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list media references: %w", err)
+			}
+			if len(refs) > 0 {
+				result.InUse = append(result.InUse, id)
+				continue
+			}
+			unused = append(unused, id)
+		}
+	}
+
+	if len(unused) > 0 {
+		if result.Deleted, err = qtx.SoftDeleteMediaBatch(ctx, unused); err != nil {
+			return nil, fmt.Errorf("failed to delete media: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk delete: %w", err)
+	}
+	return result, nil
+}
+
+// BulkRestoreMedia takes media out of the trash, returning the IDs restored
+func (s *MediaService) BulkRestoreMedia(ctx context.Context, ids []pgtype.UUID) ([]pgtype.UUID, error) {
+	if err := checkBulkSelection(len(ids)); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.queries.RestoreMediaBatch(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore media: %w", err)
+	}
+	return restored, nil
+}
+
+// BulkMoveMedia files media into a collection (the library root when
+// collectionID is not valid), returning the IDs moved
+func (s *MediaService) BulkMoveMedia(ctx context.Context, ids []pgtype.UUID, collectionID pgtype.UUID) ([]pgtype.UUID, error) {
+	if err := checkBulkSelection(len(ids)); err != nil {
+		return nil, err
+	}
+
+	moved, err := s.queries.MoveMediaToCollection(ctx, generated.MoveMediaToCollectionParams{
+		CollectionID: collectionID,
+		Ids:          ids,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to move media: %w", err)
+	}
+	return moved, nil
+}
+
+// BulkUpdateAltText saves alt text for several media items in one
+// transaction, returning how many were updated
+func (s *MediaService) BulkUpdateAltText(ctx context.Context, updates []AltTextUpdate) (int, error) {
+	if err := checkBulkSelection(len(updates)); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	for _, update := range updates {
+		var altText pgtype.Text
+		if update.AltText != "" {
+			altText = pgtype.Text{String: update.AltText, Valid: true}
+		}
+		if _, err := qtx.UpdateMediaAltText(ctx, generated.UpdateMediaAltTextParams{
+			ID:      update.ID,
+			AltText: altText,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to update alt text for %s: %w", update.ID.String(), err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit alt text: %w", err)
+	}
+	return len(updates), nil
+}
+
+// BulkTagMedia adds the comma-separated tags in tagsCSV to the selected
+// media in one transaction, creating new tags, or removes them when remove is
+// set. It returns how many live media items were changed.
+func (s *MediaService) BulkTagMedia(ctx context.Context, ids []pgtype.UUID, tagsCSV string, remove bool) (int, error) {
+	if err := checkBulkSelection(len(ids)); err != nil {
+		return 0, err
+	}
+	tagNames := splitTagNames(tagsCSV)
+	if len(tagNames) == 0 {
+		return 0, ErrNoTagsGiven
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	media, err := qtx.GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get media: %w", err)
+	}
+	live := make([]pgtype.UUID, len(media))
+	for i := range media {
+		live[i] = media[i].ID
+	}
+
+	for _, tagName := range tagNames {
+		if remove {
+			tag, err := qtx.GetTagBySlug(ctx, GenerateSlug(tagName))
+			if errors.Is(err, pgx.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return 0, fmt.Errorf("failed to get tag %q: %w", tagName, err)
+			}
+			if err := qtx.RemoveMediaTagBatch(ctx, generated.RemoveMediaTagBatchParams{
+				TagID: tag.ID,
+				Ids:   live,
+			}); err != nil {
+				return 0, fmt.Errorf("failed to remove tag %q: %w", tagName, err)
+			}
+			continue
+		}
+
+		tag, err := findOrCreateMediaTag(ctx, qtx, tagName)
+		if err != nil {
+			return 0, err
+		}
+		for _, id := range live {
+			if err := qtx.AddMediaTag(ctx, generated.AddMediaTagParams{
+				MediaID: id,
+				TagID:   tag.ID,
+			}); err != nil {
+				return 0, fmt.Errorf("failed to add tag %q: %w", tagName, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit media tags: %w", err)
+	}
+	return len(live), nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulkSelectionLimits(t *testing.T) {
+	// No queries are configured: rejected selections must not reach them
+	s := &MediaService{}
+	ctx := context.Background()
+	tooMany := make([]pgtype.UUID, MaxBulkMedia+1)
+
+	tests := []struct {
+		name string
+		run  func() error
+		want error
+	}{
+		{"error: delete nothing", func() error { _, err := s.BulkDeleteMedia(ctx, nil, false); return err }, ErrNoMediaSelected},
+		{"error: delete too many", func() error { _, err := s.BulkDeleteMedia(ctx, tooMany, true); return err }, ErrTooManyMediaSelected},
+		{"error: restore nothing", func() error { _, err := s.BulkRestoreMedia(ctx, nil); return err }, ErrNoMediaSelected},
+		{"error: move too many", func() error { _, err := s.BulkMoveMedia(ctx, tooMany, pgtype.UUID{}); return err }, ErrTooManyMediaSelected},
+		{"error: alt text for nothing", func() error { _, err := s.BulkUpdateAltText(ctx, nil); return err }, ErrNoMediaSelected},
+		{"error: tag nothing", func() error { _, err := s.BulkTagMedia(ctx, nil, "travel", false); return err }, ErrNoMediaSelected},
+		{"error: tag too many", func() error { _, err := s.BulkTagMedia(ctx, tooMany, "travel", true); return err }, ErrTooManyMediaSelected},
+		{"error: no tag names", func() error { _, err := s.BulkTagMedia(ctx, make([]pgtype.UUID, 1), " , ", false); return err }, ErrNoTagsGiven},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			err := tt.run()
+
+			// Assert
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+// TestMediaService_BulkTagMedia tests adding and removing tags on a selection
+func TestMediaService_BulkTagMedia(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	mediaService := NewMediaService(pool, queries, nil, MediaConfig{})
+
+	first := createTestMedia(t, ctx, queries, "beach.jpg", "image/jpeg")
+	second := createTestMedia(t, ctx, queries, "harbour.jpg", "image/jpeg")
+	trashed := createTestMedia(t, ctx, queries, "trashed.jpg", "image/jpeg")
+	require.NoError(t, mediaService.DeleteMedia(ctx, trashed.ID, false))
+	ids := []pgtype.UUID{first.ID, second.ID, trashed.ID}
+
+	tagNames := func(id pgtype.UUID) []string {
+		tags, err := queries.GetMediaTags(ctx, id)
+		require.NoError(t, err)
+		names := make([]string, len(tags))
+		for i, tag := range tags {
+			names[i] = tag.Name
+		}
+		return names
+	}
+
+	t.Run("success: tags are added to live media", func(t *testing.T) {
+		// Act
+		tagged, err := mediaService.BulkTagMedia(ctx, ids, "Summer, Travel", false)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 2, tagged)
+		assert.Equal(t, []string{"Summer", "Travel"}, tagNames(first.ID))
+		assert.Equal(t, []string{"Summer", "Travel"}, tagNames(second.ID))
+		assert.Empty(t, tagNames(trashed.ID))
+	})
+
+	t.Run("success: tags are removed, unknown tags ignored", func(t *testing.T) {
+		// Act
+		tagged, err := mediaService.BulkTagMedia(ctx, ids[:1], "travel, never-used", true)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, 1, tagged)
+		assert.Equal(t, []string{"Summer"}, tagNames(first.ID))
+		assert.Equal(t, []string{"Summer", "Travel"}, tagNames(second.ID))
+	})
+}
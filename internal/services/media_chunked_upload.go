@@ -0,0 +1,334 @@
+// internal/services/media_chunked_upload.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	chunkedUploadChunkSize = 8 * 1024 * 1024 // Suggested to the browser; any chunk size is accepted
+	chunkedUploadExpiry    = 24 * time.Hour
+)
+
+var (
+	// ErrUploadOffsetMismatch is returned when a chunk does not start where the
+	// received data ends; the client should resume from the current offset
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match received data")
+	// ErrUploadBusy is returned when another request is writing the same upload
+	ErrUploadBusy = errors.New("upload is already receiving data")
+)
+
+// ChunkedUpload is the state of a resumable upload. Offset counts the bytes
+// received and synced to disk, which is where the next chunk must start.
+type ChunkedUpload struct {
+	ID        string `json:"id"`
+	Offset    int64  `json:"offset"`
+	Size      int64  `json:"size"`
+	ChunkSize int64  `json:"chunk_size"`
+}
+
+// BeginChunkedUpload validates the declared file and reserves temporary
+// storage for it. The content is inspected once every chunk has arrived.
+func (s *MediaService) BeginChunkedUpload(ctx context.Context, req UploadRequest, uploadedBy pgtype.UUID) (*ChunkedUpload, error) {
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if req.Size > s.config.MaxFileSize {
+		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)
+	}
+	// Browsers leave the type empty for extensions they do not know
+	if mimeType := normalizeMimeType(req.ContentType); mimeType != "" && !s.isAllowedType(mimeType) {
+		return nil, fmt.Errorf("file type %s is not allowed", req.ContentType)
+	}
+
+	id, err := s.chunks.create(chunkState{
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+		UploadedBy:  uuid.UUID(uploadedBy.Bytes),
+		ExpiresAt:   time.Now().Add(chunkedUploadExpiry),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChunkedUpload{ID: id.String(), Size: req.Size, ChunkSize: chunkedUploadChunkSize}, nil
+}
+
+// GetChunkedUpload returns how much of an upload has been received
+func (s *MediaService) GetChunkedUpload(ctx context.Context, id uuid.UUID, uploadedBy pgtype.UUID) (*ChunkedUpload, error) {
+	state, offset, err := s.chunks.load(id, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkedUpload{ID: id.String(), Offset: offset, Size: state.Size, ChunkSize: chunkedUploadChunkSize}, nil
+}
+
+// WriteUploadChunk appends r to an upload, starting at offset. The returned
+// state is accurate even on error: whatever arrived before a dropped
+// connection is kept, so the client resumes from there.
+func (s *MediaService) WriteUploadChunk(ctx context.Context, id uuid.UUID, uploadedBy pgtype.UUID, offset int64, r io.Reader) (*ChunkedUpload, error) {
+	unlock, err := s.chunks.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	state, current, err := s.chunks.load(id, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+	upload := &ChunkedUpload{ID: id.String(), Offset: current, Size: state.Size, ChunkSize: chunkedUploadChunkSize}
+	if offset != current {
+		return upload, ErrUploadOffsetMismatch
+	}
+
+	written, err := s.chunks.append(id, r, state.Size-current)
+	upload.Offset += written
+	return upload, err
+}
+
+// CompleteChunkedUpload hands a fully received upload to the same validation
+// and storage path as a regular upload. Temporary data is kept if that fails
+// so completing can be retried; it is purged when the upload expires.
+func (s *MediaService) CompleteChunkedUpload(ctx context.Context, id uuid.UUID, uploadedBy pgtype.UUID, altText string, opts UploadOptions) (*generated.Media, error) {
+	unlock, err := s.chunks.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	state, offset, err := s.chunks.load(id, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+	if offset != state.Size {
+		return nil, fmt.Errorf("upload is incomplete: received %d of %d bytes", offset, state.Size)
+	}
+
+	dataPath := s.chunks.dataPath(id)
+	media, err := s.storeUpload(ctx, uploadSource{
+		filename:     state.Filename,
+		size:         state.Size,
+		declaredType: state.ContentType,
+		open: func() (io.ReadCloser, error) {
+			return os.Open(dataPath)
+		},
+	}, altText, uploadedBy, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s.chunks.remove(id)
+	return media, nil
+}
+
+// CancelChunkedUpload discards an upload and its received data
+func (s *MediaService) CancelChunkedUpload(ctx context.Context, id uuid.UUID, uploadedBy pgtype.UUID) error {
+	unlock, err := s.chunks.lock(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, _, err := s.chunks.load(id, uploadedBy); err != nil {
+		return err
+	}
+	return s.chunks.remove(id)
+}
+
+// PurgeExpiredChunkedUploads removes temporary data for uploads that were
+// abandoned before completing
+func (s *MediaService) PurgeExpiredChunkedUploads(ctx context.Context) (int, error) {
+	return s.chunks.purgeExpired(time.Now())
+}
+
+// chunkState is persisted next to the received data. The offset is not
+// stored; it is the size of the data file, so it cannot drift from it.
+type chunkState struct {
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// chunkStore keeps resumable uploads on local disk as <id>.json (state) and
+// <id>.part (data received so far)
+type chunkStore struct {
+	dir string
+
+	mu   sync.Mutex
+	busy map[uuid.UUID]bool
+}
+
+func newChunkStore(dir string) *chunkStore {
+	return &chunkStore{dir: dir, busy: make(map[uuid.UUID]bool)}
+}
+
+func (c *chunkStore) statePath(id uuid.UUID) string {
+	return filepath.Join(c.dir, id.String()+".json")
+}
+
+func (c *chunkStore) dataPath(id uuid.UUID) string {
+	return filepath.Join(c.dir, id.String()+".part")
+}
+
+// lock gives one request at a time access to an upload
+func (c *chunkStore) lock(id uuid.UUID) (func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.busy[id] {
+		return nil, ErrUploadBusy
+	}
+	c.busy[id] = true
+	return func() {
+		c.mu.Lock()
+		delete(c.busy, id)
+		c.mu.Unlock()
+	}, nil
+}
+
+func (c *chunkStore) create(state chunkState) (uuid.UUID, error) {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	id := uuid.New()
+	encoded, err := json.Marshal(state)
+	if err == nil {
+		err = os.WriteFile(c.statePath(id), encoded, 0o600)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to save upload state: %w", err)
+	}
+
+	// The data file comes second: purgeExpired treats one without state as abandoned
+	data, err := os.OpenFile(c.dataPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		os.Remove(c.statePath(id))
+		return uuid.Nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	data.Close()
+	return id, nil
+}
+
+// load returns an upload's state and offset. Uploads of other users are
+// reported as missing, like expired ones.
+func (c *chunkStore) load(id uuid.UUID, uploadedBy pgtype.UUID) (*chunkState, int64, error) {
+	encoded, err := os.ReadFile(c.statePath(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, ErrUploadNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to read upload state: %w", err)
+	}
+
+	var state chunkState
+	if err := json.Unmarshal(encoded, &state); err != nil {
+		return nil, 0, fmt.Errorf("failed to read upload state: %w", err)
+	}
+	if state.UploadedBy != uuid.UUID(uploadedBy.Bytes) || !time.Now().Before(state.ExpiresAt) {
+		return nil, 0, ErrUploadNotFound
+	}
+
+	fi, err := os.Stat(c.dataPath(id))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, 0, ErrUploadNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to read upload file: %w", err)
+	}
+	return &state, fi.Size(), nil
+}
+
+// append writes at most limit bytes of r to the end of the data file and
+// syncs them. Data beyond limit is an error, but what fitted is kept.
+func (c *chunkStore) append(id uuid.UUID, r io.Reader, limit int64) (int64, error) {
+	f, err := os.OpenFile(c.dataPath(id), os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer f.Close()
+
+	written, copyErr := io.Copy(f, io.LimitReader(r, limit))
+	// Whatever arrived is confirmed, even if the connection dropped mid-chunk
+	if err := f.Sync(); err != nil {
+		return 0, fmt.Errorf("failed to sync upload file: %w", err)
+	}
+	if copyErr != nil {
+		return written, fmt.Errorf("failed to receive chunk: %w", copyErr)
+	}
+
+	var extra [1]byte
+	if n, _ := r.Read(extra[:]); n > 0 {
+		return written, fmt.Errorf("chunk exceeds the declared upload size")
+	}
+	return written, nil
+}
+
+func (c *chunkStore) remove(id uuid.UUID) error {
+	if err := os.Remove(c.dataPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove upload file: %w", err)
+	}
+	if err := os.Remove(c.statePath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove upload state: %w", err)
+	}
+	return nil
+}
+
+// purgeExpired removes uploads that expired before now, along with data
+// files whose state is missing or unreadable
+func (c *chunkStore) purgeExpired(now time.Time) (int, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to list uploads: %w", err)
+	}
+
+	purged := 0
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".part")
+		if !ok {
+			continue
+		}
+		id, err := uuid.Parse(name)
+		if err != nil {
+			continue
+		}
+
+		var state chunkState
+		encoded, err := os.ReadFile(c.statePath(id))
+		if err == nil && json.Unmarshal(encoded, &state) == nil && now.Before(state.ExpiresAt) {
+			continue
+		}
+
+		unlock, err := c.lock(id)
+		if err != nil {
+			continue // Receiving data right now, so not abandoned
+		}
+		err = c.remove(id)
+		unlock()
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkedUpload(t *testing.T) {
+	ctx := context.Background()
+	owner := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	newService := func(t *testing.T) *MediaService {
+		return NewMediaService(nil, nil, nil, MediaConfig{MaxFileSize: 1024, ChunkDir: t.TempDir()})
+	}
+	begin := func(t *testing.T, s *MediaService, size int64) uuid.UUID {
+		t.Helper()
+		upload, err := s.BeginChunkedUpload(ctx, UploadRequest{Filename: "a.jpg", ContentType: "image/jpeg", Size: size}, owner)
+		require.NoError(t, err)
+		return uuid.MustParse(upload.ID)
+	}
+	received := func(t *testing.T, s *MediaService, id uuid.UUID) string {
+		t.Helper()
+		data, err := os.ReadFile(s.chunks.dataPath(id))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("success: chunks are appended in order", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+
+		// Act
+		first, err1 := s.WriteUploadChunk(ctx, id, owner, 0, strings.NewReader("hello"))
+		second, err2 := s.WriteUploadChunk(ctx, id, owner, 5, strings.NewReader("world"))
+
+		// Assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		assert.Equal(t, int64(5), first.Offset)
+		assert.Equal(t, int64(10), second.Offset)
+		assert.Equal(t, "helloworld", received(t, s, id))
+	})
+
+	t.Run("success: dropped connection keeps received bytes", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+		dropped := io.MultiReader(strings.NewReader("hel"), iotest.ErrReader(errors.New("connection reset")))
+
+		// Act
+		upload, err := s.WriteUploadChunk(ctx, id, owner, 0, dropped)
+
+		// Assert
+		require.Error(t, err)
+		assert.Equal(t, int64(3), upload.Offset)
+		status, err := s.GetChunkedUpload(ctx, id, owner)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), status.Offset)
+
+		_, err = s.WriteUploadChunk(ctx, id, owner, 3, strings.NewReader("loworld"))
+		require.NoError(t, err)
+		assert.Equal(t, "helloworld", received(t, s, id))
+	})
+
+	t.Run("failure: offset must match received data", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+		_, err := s.WriteUploadChunk(ctx, id, owner, 0, strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		// Act
+		upload, err := s.WriteUploadChunk(ctx, id, owner, 0, strings.NewReader("hello"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUploadOffsetMismatch)
+		assert.Equal(t, int64(5), upload.Offset)
+		assert.Equal(t, "hello", received(t, s, id))
+	})
+
+	t.Run("failure: data beyond the declared size", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 4)
+
+		// Act
+		upload, err := s.WriteUploadChunk(ctx, id, owner, 0, strings.NewReader("toolong"))
+
+		// Assert
+		assert.Error(t, err)
+		assert.Equal(t, int64(4), upload.Offset)
+		assert.Equal(t, "tool", received(t, s, id))
+	})
+
+	t.Run("failure: declared file too large", func(t *testing.T) {
+		s := newService(t)
+
+		// Act
+		_, err := s.BeginChunkedUpload(ctx, UploadRequest{Filename: "a.mp4", ContentType: "video/mp4", Size: 2048}, owner)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("failure: declared type not allowed", func(t *testing.T) {
+		s := newService(t)
+
+		// Act
+		_, err := s.BeginChunkedUpload(ctx, UploadRequest{Filename: "a.exe", ContentType: "application/x-msdownload", Size: 10}, owner)
+
+		// Assert
+		assert.Error(t, err)
+	})
+
+	t.Run("failure: incomplete upload cannot be completed", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+
+		// Act
+		_, err := s.CompleteChunkedUpload(ctx, id, owner, "", UploadOptions{})
+
+		// Assert
+		assert.ErrorContains(t, err, "incomplete")
+	})
+
+	t.Run("security: other users cannot see or write an upload", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+		other := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+		// Act
+		_, getErr := s.GetChunkedUpload(ctx, id, other)
+		_, writeErr := s.WriteUploadChunk(ctx, id, other, 0, strings.NewReader("x"))
+		cancelErr := s.CancelChunkedUpload(ctx, id, other)
+
+		// Assert
+		assert.ErrorIs(t, getErr, ErrUploadNotFound)
+		assert.ErrorIs(t, writeErr, ErrUploadNotFound)
+		assert.ErrorIs(t, cancelErr, ErrUploadNotFound)
+		assert.Equal(t, "", received(t, s, id))
+	})
+
+	t.Run("edge: concurrent writes are refused", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+		unlock, err := s.chunks.lock(id)
+		require.NoError(t, err)
+		defer unlock()
+
+		// Act
+		_, err = s.WriteUploadChunk(ctx, id, owner, 0, strings.NewReader("x"))
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUploadBusy)
+	})
+
+	t.Run("edge: cancel removes received data", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+
+		// Act
+		err := s.CancelChunkedUpload(ctx, id, owner)
+
+		// Assert
+		require.NoError(t, err)
+		_, err = s.GetChunkedUpload(ctx, id, owner)
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+	})
+
+	t.Run("edge: purge removes only expired uploads", func(t *testing.T) {
+		s := newService(t)
+		id := begin(t, s, 10)
+
+		// Act
+		kept, err1 := s.chunks.purgeExpired(time.Now())
+		purged, err2 := s.chunks.purgeExpired(time.Now().Add(chunkedUploadExpiry + time.Minute))
+
+		// Assert
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		assert.Equal(t, 0, kept)
+		assert.Equal(t, 1, purged)
+		_, err := os.Stat(s.chunks.dataPath(id))
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(s.chunks.statePath(id))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
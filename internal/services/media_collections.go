@@ -0,0 +1,152 @@
+// internal/services/media_collections.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrMediaCollectionExists is returned when a sibling collection already
+// has the name
+var ErrMediaCollectionExists = errors.New("a collection with this name already exists here")
+
+// ErrMediaCollectionNotFound is returned for an unknown collection
+var ErrMediaCollectionNotFound = errors.New("collection not found")
+
+// MediaCollectionNode is a collection placed in the collection tree
+type MediaCollectionNode struct {
+	ID         pgtype.UUID
+	ParentID   pgtype.UUID
+	Name       string
+	MediaCount int64
+	Depth      int // 0 for top-level collections
+}
+
+// ListMediaCollections returns every collection in tree order: each
+// collection is followed by its children, siblings sorted by name
+func (s *MediaService) ListMediaCollections(ctx context.Context) ([]MediaCollectionNode, error) {
+	rows, err := s.queries.ListMediaCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media collections: %w", err)
+	}
+	return collectionTree(rows), nil
+}
+
+// collectionTree flattens collections depth-first. Rows arrive sorted by
+// name, so children keep that order.
+func collectionTree(rows []generated.ListMediaCollectionsRow) []MediaCollectionNode {
+	children := make(map[pgtype.UUID][]generated.ListMediaCollectionsRow)
+	for _, row := range rows {
+		children[row.ParentID] = append(children[row.ParentID], row)
+	}
+
+	nodes := make([]MediaCollectionNode, 0, len(rows))
+	var walk func(parent pgtype.UUID, depth int)
+	walk = func(parent pgtype.UUID, depth int) {
+		for _, row := range children[parent] {
+			nodes = append(nodes, MediaCollectionNode{
+				ID:         row.ID,
+				ParentID:   row.ParentID,
+				Name:       row.Name,
+				MediaCount: row.MediaCount,
+				Depth:      depth,
+			})
+			walk(row.ID, depth+1)
+		}
+	}
+	walk(pgtype.UUID{}, 0)
+	return nodes
+}
+
+// WithinCollection reports whether collection id is ancestor or nested under
+// it, so that filtering by ancestor shows its media
+func WithinCollection(nodes []MediaCollectionNode, ancestor, id pgtype.UUID) bool {
+	parents := make(map[pgtype.UUID]pgtype.UUID, len(nodes))
+	for _, node := range nodes {
+		parents[node.ID] = node.ParentID
+	}
+	for steps := 0; id.Valid && steps <= len(nodes); steps++ {
+		if id == ancestor {
+			return true
+		}
+		id = parents[id]
+	}
+	return false
+}
+
+// CreateMediaCollection adds a collection inside parentID, or at the top
+// level when parentID is not valid
+func (s *MediaService) CreateMediaCollection(ctx context.Context, name string, parentID pgtype.UUID) (*generated.MediaCollection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("collection name is required")
+	}
+
+	if parentID.Valid {
+		if _, err := s.queries.GetMediaCollectionByID(ctx, parentID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrMediaCollectionNotFound
+			}
+			return nil, fmt.Errorf("failed to get parent collection: %w", err)
+		}
+	}
+
+	collection, err := s.queries.CreateMediaCollection(ctx, generated.CreateMediaCollectionParams{
+		ID:       pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		ParentID: parentID,
+		Name:     name,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrMediaCollectionExists
+		}
+		return nil, fmt.Errorf("failed to create media collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// RenameMediaCollection changes a collection's name
+func (s *MediaService) RenameMediaCollection(ctx context.Context, id pgtype.UUID, name string) (*generated.MediaCollection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("collection name is required")
+	}
+
+	collection, err := s.queries.RenameMediaCollection(ctx, generated.RenameMediaCollectionParams{
+		Name: name,
+		ID:   id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrMediaCollectionNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrMediaCollectionExists
+		}
+		return nil, fmt.Errorf("failed to rename media collection: %w", err)
+	}
+	return &collection, nil
+}
+
+// DeleteMediaCollection removes a collection and the collections nested in
+// it. Their media is kept and moves to the library root.
+func (s *MediaService) DeleteMediaCollection(ctx context.Context, id pgtype.UUID) error {
+	if err := s.queries.DeleteMediaCollection(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete media collection: %w", err)
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique_violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
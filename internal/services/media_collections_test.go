@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionTree(t *testing.T) {
+	id := func(b byte) pgtype.UUID {
+		return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+	}
+
+	// Sorted by name, as ListMediaCollections returns them
+	rows := []generated.ListMediaCollectionsRow{
+		{ID: id(1), Name: "Clients"},
+		{ID: id(2), ParentID: id(3), Name: "Headshots"},
+		{ID: id(3), Name: "People"},
+		{ID: id(4), ParentID: id(1), Name: "Acme"},
+		{ID: id(5), ParentID: id(4), Name: "2026"},
+	}
+
+	// Act
+	nodes := collectionTree(rows)
+
+	// Assert
+	var names []string
+	var depths []int
+	for _, node := range nodes {
+		names = append(names, node.Name)
+		depths = append(depths, node.Depth)
+	}
+	assert.Equal(t, []string{"Clients", "Acme", "2026", "People", "Headshots"}, names)
+	assert.Equal(t, []int{0, 1, 2, 0, 1}, depths)
+}
+
+func TestWithinCollection(t *testing.T) {
+	id := func(b byte) pgtype.UUID {
+		return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+	}
+	nodes := collectionTree([]generated.ListMediaCollectionsRow{
+		{ID: id(1), Name: "Clients"},
+		{ID: id(4), ParentID: id(1), Name: "Acme"},
+		{ID: id(5), ParentID: id(4), Name: "2026"},
+		{ID: id(3), Name: "People"},
+	})
+
+	tests := []struct {
+		name     string
+		ancestor pgtype.UUID
+		id       pgtype.UUID
+		want     bool
+	}{
+		{"success: collection itself", id(1), id(1), true},
+		{"success: nested two levels down", id(1), id(5), true},
+		{"edge: parent is not within its child", id(4), id(1), false},
+		{"edge: sibling tree", id(3), id(5), false},
+		{"edge: library root", id(1), pgtype.UUID{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := WithinCollection(nodes, tt.ancestor, tt.id)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+// internal/services/media_dedup.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DuplicateMediaError is returned when an upload's content is already in the
+// library. The upload is not stored; the uploader can reuse Existing or retry
+// with UploadOptions.AllowDuplicate.
+type DuplicateMediaError struct {
+	Existing *generated.Media
+}
+
+func (e *DuplicateMediaError) Error() string {
+	return fmt.Sprintf("this file is already in the library as %s", e.Existing.OriginalFilename)
+}
+
+// ContentHashBackfillReport is the result of BackfillContentHashes
+type ContentHashBackfillReport struct {
+	Hashed  int
+	Skipped int // Stored on another backend (see migrate-storage)
+	Failed  int
+}
+
+// checkDuplicate returns a DuplicateMediaError when live media already has
+// the content hash, unless duplicates were allowed
+func (s *MediaService) checkDuplicate(ctx context.Context, contentHash string, opts UploadOptions) error {
+	if opts.AllowDuplicate {
+		return nil
+	}
+
+	existing, err := s.queries.GetMediaByContentHash(ctx, pgtype.Text{String: contentHash, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to check for duplicate media: %w", err)
+	}
+	return &DuplicateMediaError{Existing: &existing}
+}
+
+// uploadChecksum hashes an upload as it was received. Metadata stripping and
+// SVG sanitizing are left out so that the same file matches whether or not
+// it was stripped, and matches originals stored before stripping existed.
+func uploadChecksum(file uploadSource) (string, error) {
+	src, err := file.open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, src); err != nil {
+		return "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// BackfillContentHashes hashes the stored original of every media row that
+// predates content hashing, which is the file as it was received for media
+// stored before metadata stripping. Rows on another storage backend are
+// skipped.
+func (s *MediaService) BackfillContentHashes(ctx context.Context, batchSize int32, logger *slog.Logger) (*ContentHashBackfillReport, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	here := LocationOf(s.storage)
+	report := &ContentHashBackfillReport{}
+	after := pgtype.UUID{Valid: true} // The nil UUID sorts first
+
+	for {
+		batch, err := s.queries.ListMediaWithoutContentHash(ctx, generated.ListMediaWithoutContentHashParams{
+			AfterID:  after,
+			LimitVal: batchSize,
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to list media: %w", err)
+		}
+
+		for _, media := range batch {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			after = media.ID
+			log := logger.With("media_id", media.ID.String(), "filename", media.OriginalFilename)
+
+			if !here.holds(&media) {
+				log.Warn("skipping media stored elsewhere", "storage_type", media.StorageType, "bucket", media.S3Bucket.String)
+				report.Skipped++
+				continue
+			}
+
+			contentHash, err := objectChecksum(ctx, s.storage, media.OriginalKey.String)
+			if err == nil {
+				err = s.queries.UpdateMediaContentHash(ctx, generated.UpdateMediaContentHashParams{
+					ContentHash: pgtype.Text{String: contentHash, Valid: true},
+					ID:          media.ID,
+				})
+			}
+			if err != nil {
+				log.Error("failed to hash media", "error", err)
+				report.Failed++
+				continue
+			}
+			report.Hashed++
+		}
+
+		if len(batch) < int(batchSize) {
+			return report, nil
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadChecksum(t *testing.T) {
+	const content = "received file bytes"
+	hashOf := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	source := uploadSource{
+		filename: "photo.jpg",
+		size:     int64(len(content)),
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(content)), nil
+		},
+	}
+
+	t.Run("success: hashes the received file", func(t *testing.T) {
+		// Act
+		got, err := uploadChecksum(source)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, hashOf(content), got)
+	})
+
+	t.Run("error: file cannot be opened", func(t *testing.T) {
+		broken := source
+		broken.open = func() (io.ReadCloser, error) {
+			return nil, errors.New("gone")
+		}
+
+		// Act
+		_, err := uploadChecksum(broken)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckDuplicateAllowed(t *testing.T) {
+	// No queries are configured: allowing duplicates must not look them up
+	s := &MediaService{}
+
+	// Act
+	err := s.checkDuplicate(context.Background(), "abc123", UploadOptions{AllowDuplicate: true})
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+// TestMediaService_DuplicateUpload tests that the same photo is found again
+// whether or not its metadata was stripped
+func TestMediaService_DuplicateUpload(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	storage := NewLocalStorage(t.TempDir(), "/uploads", []byte("test-signing-key"))
+	mediaService := NewMediaService(pool, queries, storage, MediaConfig{})
+	user := createTestUser(t, ctx, queries, "uploader@example.com", "UploadPass123!", "Media", "Uploader")
+
+	photo := encodeTestJPEG(t, buildTestEXIF(1, "Jane Photographer", "All rights reserved"))
+	source := uploadSource{
+		filename: "holiday.jpg",
+		size:     int64(len(photo)),
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(photo)), nil
+		},
+	}
+
+	stripped, err := mediaService.storeUpload(ctx, source, "", user.ID, UploadOptions{})
+	require.NoError(t, err, "failed to upload stripped photo")
+
+	t.Run("failure: same photo with metadata kept is a duplicate", func(t *testing.T) {
+		// Act
+		_, err := mediaService.storeUpload(ctx, source, "", user.ID, UploadOptions{PreserveMetadata: true})
+
+		// Assert
+		var duplicate *DuplicateMediaError
+		require.ErrorAs(t, err, &duplicate)
+		assert.Equal(t, stripped.ID, duplicate.Existing.ID)
+	})
+
+	t.Run("success: allowed duplicate has the same content hash", func(t *testing.T) {
+		// Act
+		kept, err := mediaService.storeUpload(ctx, source, "", user.ID, UploadOptions{PreserveMetadata: true, AllowDuplicate: true})
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, stripped.ContentHash, kept.ContentHash)
+		assert.NotEqual(t, stripped.FileSize, kept.FileSize, "only the stripped copy loses its metadata")
+	})
+}
@@ -0,0 +1,342 @@
+// internal/services/media_direct_upload.go
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	directUploadSinglePutMax = 100 * 1024 * 1024 // Larger files are uploaded in parts
+	directUploadPartSize     = 16 * 1024 * 1024  // S3 requires at least 5MB for all but the last part
+	directUploadExpiry       = 6 * time.Hour     // Presigned URLs and pending uploads expire together
+)
+
+// directUploadStagingPrefix holds what the browser uploads until it has been
+// verified. It is under PrivateKeyPrefix so the unverified bytes are never
+// publicly readable.
+const directUploadStagingPrefix = PrivateKeyPrefix + "uploads/"
+
+// ErrUploadInProgress is returned when another request is already finalizing
+// or cancelling the same direct upload
+var ErrUploadInProgress = errors.New("upload is already being finalized")
+
+// DirectUpload tells the browser where to send the file: either a single PUT
+// or one request per part, each PartSize bytes except the last
+type DirectUpload struct {
+	ID       string             `json:"id"`
+	Upload   *PresignedRequest  `json:"upload,omitempty"`
+	Parts    []DirectUploadPart `json:"parts,omitempty"`
+	PartSize int64              `json:"part_size,omitempty"`
+}
+
+// DirectUploadPart is a presigned request for one part of a multipart upload
+type DirectUploadPart struct {
+	PartNumber int32 `json:"part_number"`
+	*PresignedRequest
+}
+
+// SupportsDirectUpload reports whether the browser can upload straight to storage
+func (s *MediaService) SupportsDirectUpload() bool {
+	_, ok := s.storage.(DirectUploader)
+	return ok
+}
+
+// BeginDirectUpload validates the declared file and returns presigned requests
+// for a private staging key. The content is only trusted, and moved to its
+// media key, once FinalizeDirectUpload has inspected it.
+func (s *MediaService) BeginDirectUpload(ctx context.Context, req UploadRequest, uploadedBy pgtype.UUID) (*DirectUpload, error) {
+	uploader, ok := s.storage.(DirectUploader)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support direct uploads")
+	}
+
+	if req.Size <= 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if req.Size > s.config.MaxFileSize {
+		return nil, fmt.Errorf("file size exceeds maximum allowed size of %d bytes", s.config.MaxFileSize)
+	}
+	mimeType := normalizeMimeType(req.ContentType)
+	if !s.isAllowedType(mimeType) {
+		return nil, fmt.Errorf("file type %s is not allowed", req.ContentType)
+	}
+
+	uploadID := uuid.New()
+	storageKey := directUploadStagingPrefix + uploadID.String()
+	result := &DirectUpload{}
+
+	var multipartID pgtype.Text
+	if req.Size <= directUploadSinglePutMax {
+		put, err := uploader.PresignPut(ctx, storageKey, mimeType, req.Size, directUploadExpiry)
+		if err != nil {
+			return nil, err
+		}
+		result.Upload = put
+	} else {
+		uploadID, err := uploader.CreateMultipartUpload(ctx, storageKey, mimeType)
+		if err != nil {
+			return nil, err
+		}
+		multipartID = pgtype.Text{String: uploadID, Valid: true}
+
+		parts, err := presignParts(ctx, uploader, storageKey, uploadID, req.Size)
+		if err != nil {
+			uploader.AbortMultipartUpload(ctx, storageKey, uploadID)
+			return nil, err
+		}
+		result.Parts = parts
+		result.PartSize = directUploadPartSize
+	}
+
+	upload, err := s.queries.CreateMediaUpload(ctx, generated.CreateMediaUploadParams{
+		ID:                pgtype.UUID{Bytes: uploadID, Valid: true},
+		StorageKey:        storageKey,
+		MultipartUploadID: multipartID,
+		OriginalFilename:  req.Filename,
+		MimeType:          mimeType,
+		FileSize:          req.Size,
+		UploadedBy:        uploadedBy,
+		ExpiresAt:         time.Now().Add(directUploadExpiry),
+	})
+	if err != nil {
+		if multipartID.Valid {
+			uploader.AbortMultipartUpload(ctx, storageKey, multipartID.String)
+		}
+		return nil, fmt.Errorf("failed to record upload: %w", err)
+	}
+
+	result.ID = uuid.UUID(upload.ID.Bytes).String()
+	return result, nil
+}
+
+func presignParts(ctx context.Context, uploader DirectUploader, key, uploadID string, size int64) ([]DirectUploadPart, error) {
+	sizes := partSizes(size, directUploadPartSize)
+	parts := make([]DirectUploadPart, len(sizes))
+	for i, partSize := range sizes {
+		partNumber := int32(i + 1)
+		req, err := uploader.PresignUploadPart(ctx, key, uploadID, partNumber, partSize, directUploadExpiry)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = DirectUploadPart{PartNumber: partNumber, PresignedRequest: req}
+	}
+	return parts, nil
+}
+
+// partSizes splits size bytes into parts of partSize, the last one shorter
+func partSizes(size, partSize int64) []int64 {
+	var sizes []int64
+	for remaining := size; remaining > 0; remaining -= partSize {
+		sizes = append(sizes, min(remaining, partSize))
+	}
+	return sizes
+}
+
+// FinalizeDirectUpload completes an upload the browser sent straight to
+// storage, then verifies it as UploadMedia would: the stored size must match
+// the declared one and the content is sniffed, sanitized and stripped. Only
+// then is it copied to its media key and the media row created. Rejected
+// objects are deleted.
+func (s *MediaService) FinalizeDirectUpload(ctx context.Context, id pgtype.UUID, parts []CompletedPart, altText string, uploadedBy pgtype.UUID, opts UploadOptions) (*generated.Media, error) {
+	uploader, ok := s.storage.(DirectUploader)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support direct uploads")
+	}
+
+	upload, err := s.claimDirectUpload(ctx, id, uploadedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failures that keep the upload release the claim, so the browser can
+	// finalize it again
+	if upload.MultipartUploadID.Valid {
+		if want := len(partSizes(upload.FileSize, directUploadPartSize)); len(parts) != want {
+			s.queries.ReleaseMediaUpload(ctx, upload.ID)
+			return nil, fmt.Errorf("expected %d uploaded parts, got %d", want, len(parts))
+		}
+		if err := uploader.CompleteMultipartUpload(ctx, upload.StorageKey, upload.MultipartUploadID.String, parts); err != nil {
+			s.queries.ReleaseMediaUpload(ctx, upload.ID)
+			return nil, err
+		}
+		// A duplicate upload may be finalized again, now as a single object
+		if err := s.queries.ClearMediaUploadMultipart(ctx, upload.ID); err != nil {
+			s.queries.ReleaseMediaUpload(ctx, upload.ID)
+			return nil, fmt.Errorf("failed to update upload record: %w", err)
+		}
+		upload.MultipartUploadID = pgtype.Text{}
+	}
+
+	inspected, fileSize, contentHash, err := s.verifyDirectUpload(ctx, upload, opts)
+	if err != nil {
+		// Duplicates are kept until the uploader reuses the existing media
+		// (CancelDirectUpload) or uploads anyway
+		var dup *DuplicateMediaError
+		if errors.As(err, &dup) {
+			s.queries.ReleaseMediaUpload(ctx, upload.ID)
+		} else {
+			s.discardDirectUpload(ctx, upload)
+		}
+		return nil, err
+	}
+
+	// Sanitized or stripped content replaces what the browser uploaded
+	storageKey := storageKeyFor(upload.OriginalFilename, inspected.MimeType)
+	if inspected.Sanitized != nil {
+		err = s.storage.Upload(ctx, storageKey, bytes.NewReader(inspected.Sanitized), ObjectOptions{
+			ContentType: inspected.MimeType,
+			Size:        fileSize,
+		})
+	} else {
+		err = s.storage.Copy(ctx, upload.StorageKey, storageKey)
+	}
+	if err != nil {
+		s.discardDirectUpload(ctx, upload)
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	// The media key belongs to the media row from here on. A staged object
+	// that cannot be deleted is left for the storage check's orphan reaper.
+	if err := s.queries.DeleteMediaUpload(ctx, upload.ID); err != nil {
+		s.storage.Delete(ctx, storageKey)
+		s.discardDirectUpload(ctx, upload)
+		return nil, fmt.Errorf("failed to delete upload record: %w", err)
+	}
+	s.storage.Delete(ctx, upload.StorageKey)
+
+	return s.createMediaRecord(ctx, mediaRecord{
+		storageKey:       storageKey,
+		originalFilename: upload.OriginalFilename,
+		fileSize:         fileSize,
+		contentHash:      contentHash,
+		inspected:        inspected,
+		altText:          altText,
+		uploadedBy:       upload.UploadedBy,
+	})
+}
+
+// claimDirectUpload claims the uploader's pending upload for this request, so
+// concurrent requests cannot both finalize it
+func (s *MediaService) claimDirectUpload(ctx context.Context, id pgtype.UUID, uploadedBy pgtype.UUID) (generated.MediaUpload, error) {
+	params := generated.ClaimMediaUploadParams{ID: id, UploadedBy: uploadedBy}
+	upload, err := s.queries.ClaimMediaUpload(ctx, params)
+	if err == nil {
+		return upload, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return upload, fmt.Errorf("failed to claim upload: %w", err)
+	}
+
+	// Nothing claimable: either another request holds it or it is gone
+	_, err = s.queries.GetMediaUpload(ctx, generated.GetMediaUploadParams(params))
+	switch {
+	case err == nil:
+		return upload, ErrUploadInProgress
+	case errors.Is(err, pgx.ErrNoRows):
+		return upload, ErrUploadNotFound
+	}
+	return upload, fmt.Errorf("failed to get upload: %w", err)
+}
+
+// verifyDirectUpload checks the staged object against the declared size and
+// inspects its content. It returns the inspection, the size of what will be
+// stored, which is inspected.Sanitized when it is set, and the content hash
+// of the object as it was uploaded.
+func (s *MediaService) verifyDirectUpload(ctx context.Context, upload generated.MediaUpload, opts UploadOptions) (*InspectedUpload, int64, string, error) {
+	info, err := s.storage.Stat(ctx, upload.StorageKey)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return nil, 0, "", fmt.Errorf("file was not uploaded")
+		}
+		return nil, 0, "", err
+	}
+	if info.Size != upload.FileSize {
+		return nil, 0, "", fmt.Errorf("uploaded file is %d bytes, expected %d", info.Size, upload.FileSize)
+	}
+
+	// Hash the object while it is inspected rather than downloading it twice
+	src, err := s.storage.Open(ctx, upload.StorageKey)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	hash := sha256.New()
+	inspected, err := InspectUpload(io.TeeReader(src, hash), upload.MimeType)
+	if err == nil {
+		_, err = io.Copy(hash, src)
+	}
+	src.Close()
+	if err != nil {
+		return nil, 0, "", err
+	}
+	if err := s.prepareUpload(inspected, opts); err != nil {
+		return nil, 0, "", err
+	}
+
+	fileSize := info.Size
+	if inspected.Sanitized != nil {
+		fileSize = int64(len(inspected.Sanitized))
+	}
+	contentHash := hex.EncodeToString(hash.Sum(nil))
+	if err := s.checkDuplicate(ctx, contentHash, opts); err != nil {
+		return nil, 0, "", err
+	}
+	return inspected, fileSize, contentHash, nil
+}
+
+// CancelDirectUpload discards a pending upload, e.g. when the uploader
+// reuses the media it duplicates
+func (s *MediaService) CancelDirectUpload(ctx context.Context, id pgtype.UUID, uploadedBy pgtype.UUID) error {
+	upload, err := s.claimDirectUpload(ctx, id, uploadedBy)
+	if err != nil {
+		return err
+	}
+	return s.discardDirectUpload(ctx, upload)
+}
+
+// discardDirectUpload removes a pending upload's object, any unfinished
+// multipart upload and its row
+func (s *MediaService) discardDirectUpload(ctx context.Context, upload generated.MediaUpload) error {
+	if uploader, ok := s.storage.(DirectUploader); ok && upload.MultipartUploadID.Valid {
+		err := uploader.AbortMultipartUpload(ctx, upload.StorageKey, upload.MultipartUploadID.String)
+		if err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return err
+		}
+	}
+	if err := s.storage.Delete(ctx, upload.StorageKey); err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return err
+	}
+	if err := s.queries.DeleteMediaUpload(ctx, upload.ID); err != nil {
+		return fmt.Errorf("failed to delete upload record: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredDirectUploads removes uploads that were started but never
+// finalized, along with whatever the browser managed to store
+func (s *MediaService) PurgeExpiredDirectUploads(ctx context.Context) (int, error) {
+	uploads, err := s.queries.ListExpiredMediaUploads(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired uploads: %w", err)
+	}
+
+	purged := 0
+	for _, upload := range uploads {
+		if err := s.discardDirectUpload(ctx, upload); err != nil {
+			return purged, fmt.Errorf("failed to purge upload %s: %w", upload.StorageKey, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
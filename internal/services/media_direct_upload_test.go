@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMediaService_ClaimDirectUpload tests that only one request at a time
+// can finalize or cancel a direct upload
+func TestMediaService_ClaimDirectUpload(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	mediaService := NewMediaService(pool, queries, nil, MediaConfig{})
+	user := createTestUser(t, ctx, queries, "uploader@example.com", "UploadPass123!", "Media", "Uploader")
+
+	uploadID := uuid.New()
+	upload, err := queries.CreateMediaUpload(ctx, generated.CreateMediaUploadParams{
+		ID:               pgtype.UUID{Bytes: uploadID, Valid: true},
+		StorageKey:       directUploadStagingPrefix + uploadID.String(),
+		OriginalFilename: "photo.jpg",
+		MimeType:         "image/jpeg",
+		FileSize:         1024,
+		UploadedBy:       user.ID,
+		ExpiresAt:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err, "failed to create upload")
+
+	t.Run("success: first request claims the upload", func(t *testing.T) {
+		// Act
+		claimed, err := mediaService.claimDirectUpload(ctx, upload.ID, user.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, upload.StorageKey, claimed.StorageKey)
+	})
+
+	t.Run("failure: concurrent request gets a conflict", func(t *testing.T) {
+		// Act
+		_, err := mediaService.claimDirectUpload(ctx, upload.ID, user.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUploadInProgress)
+	})
+
+	t.Run("success: released upload can be claimed again", func(t *testing.T) {
+		require.NoError(t, queries.ReleaseMediaUpload(ctx, upload.ID))
+
+		// Act
+		_, err := mediaService.claimDirectUpload(ctx, upload.ID, user.ID)
+
+		// Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("failure: another user's upload is not found", func(t *testing.T) {
+		other := createTestUser(t, ctx, queries, "other@example.com", "OtherPass123!", "Other", "User")
+
+		// Act
+		_, err := mediaService.claimDirectUpload(ctx, upload.ID, other.ID)
+
+		// Assert
+		assert.ErrorIs(t, err, ErrUploadNotFound)
+	})
+}
@@ -0,0 +1,265 @@
+// internal/services/media_filter.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// mediaFilterDate is the date format of the from/to filters
+const mediaFilterDate = "2006-01-02"
+
+// MediaFilter narrows the media library. Zero fields match everything.
+type MediaFilter struct {
+	CollectionID pgtype.UUID
+	TagID        pgtype.UUID
+	Type         string // image, video or document
+	UploadedBy   pgtype.UUID
+	From         time.Time // First day included
+	To           time.Time // Last day included
+}
+
+// ParseMediaFilter reads a filter from query parameters (collection, tag,
+// type, uploader, from, to). Malformed values are ignored.
+func ParseMediaFilter(values url.Values) MediaFilter {
+	var f MediaFilter
+	f.CollectionID = parseFilterUUID(values.Get("collection"))
+	f.TagID = parseFilterUUID(values.Get("tag"))
+	f.UploadedBy = parseFilterUUID(values.Get("uploader"))
+	if MimePatternForType(values.Get("type")) != "" {
+		f.Type = values.Get("type")
+	}
+	if from, err := time.Parse(mediaFilterDate, values.Get("from")); err == nil {
+		f.From = from
+	}
+	if to, err := time.Parse(mediaFilterDate, values.Get("to")); err == nil {
+		f.To = to
+	}
+	return f
+}
+
+func parseFilterUUID(s string) pgtype.UUID {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}
+}
+
+// Query encodes the filter as query parameters, the inverse of
+// ParseMediaFilter
+func (f MediaFilter) Query() url.Values {
+	values := url.Values{}
+	if f.CollectionID.Valid {
+		values.Set("collection", f.CollectionID.String())
+	}
+	if f.TagID.Valid {
+		values.Set("tag", f.TagID.String())
+	}
+	if f.Type != "" {
+		values.Set("type", f.Type)
+	}
+	if f.UploadedBy.Valid {
+		values.Set("uploader", f.UploadedBy.String())
+	}
+	if !f.From.IsZero() {
+		values.Set("from", f.FromDate())
+	}
+	if !f.To.IsZero() {
+		values.Set("to", f.ToDate())
+	}
+	return values
+}
+
+// FromDate formats From for a date input, empty when unset
+func (f MediaFilter) FromDate() string {
+	if f.From.IsZero() {
+		return ""
+	}
+	return f.From.Format(mediaFilterDate)
+}
+
+// ToDate formats To for a date input, empty when unset
+func (f MediaFilter) ToDate() string {
+	if f.To.IsZero() {
+		return ""
+	}
+	return f.To.Format(mediaFilterDate)
+}
+
+// MimePatternForType maps a media type filter to a LIKE pattern for
+// ListMediaByType and FilterMedia; unknown types return ""
+func MimePatternForType(mediaType string) string {
+	switch mediaType {
+	case "image":
+		return "image/%"
+	case "video":
+		return "video/%"
+	case "document":
+		return "application/%"
+	}
+	return ""
+}
+
+func (f MediaFilter) params() generated.CountFilteredMediaParams {
+	p := generated.CountFilteredMediaParams{
+		CollectionID: f.CollectionID,
+		TagID:        f.TagID,
+		UploadedBy:   f.UploadedBy,
+	}
+	if pattern := MimePatternForType(f.Type); pattern != "" {
+		p.MimeTypePattern = pgtype.Text{String: pattern, Valid: true}
+	}
+	if !f.From.IsZero() {
+		from := f.From
+		p.CreatedFrom = &from
+	}
+	if !f.To.IsZero() {
+		before := f.To.AddDate(0, 0, 1)
+		p.CreatedBefore = &before
+	}
+	return p
+}
+
+// FilterMedia lists a page of media matching the filter along with the
+// total number of matches
+func (s *MediaService) FilterMedia(ctx context.Context, f MediaFilter, limit, offset int32) ([]generated.Media, int64, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	p := f.params()
+	media, err := s.queries.FilterMedia(ctx, generated.FilterMediaParams{
+		CollectionID:    p.CollectionID,
+		TagID:           p.TagID,
+		MimeTypePattern: p.MimeTypePattern,
+		UploadedBy:      p.UploadedBy,
+		CreatedFrom:     p.CreatedFrom,
+		CreatedBefore:   p.CreatedBefore,
+		LimitVal:        limit,
+		OffsetVal:       offset,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to filter media: %w", err)
+	}
+
+	total, err := s.queries.CountFilteredMedia(ctx, p)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count filtered media: %w", err)
+	}
+	return media, total, nil
+}
+
+// MediaUploader is a user who has uploaded live media
+type MediaUploader struct {
+	ID   pgtype.UUID
+	Name string
+}
+
+// ListMediaUploaders lists users with media in the library
+func (s *MediaService) ListMediaUploaders(ctx context.Context) ([]MediaUploader, error) {
+	rows, err := s.queries.ListMediaUploaders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media uploaders: %w", err)
+	}
+
+	uploaders := make([]MediaUploader, len(rows))
+	for i, row := range rows {
+		uploaders[i] = MediaUploader{
+			ID:   row.ID,
+			Name: strings.TrimSpace(row.FirstName + " " + row.LastName),
+		}
+	}
+	return uploaders, nil
+}
+
+// ListMediaTagsInUse lists the tags on live media
+func (s *MediaService) ListMediaTagsInUse(ctx context.Context) ([]generated.Tag, error) {
+	tags, err := s.queries.ListMediaTagsInUse(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media tags: %w", err)
+	}
+	return tags, nil
+}
+
+// GetMediaTags lists the tags on media
+func (s *MediaService) GetMediaTags(ctx context.Context, mediaID pgtype.UUID) ([]generated.Tag, error) {
+	tags, err := s.queries.GetMediaTags(ctx, mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media tags: %w", err)
+	}
+	return tags, nil
+}
+
+// OrganizeMedia moves media into a collection (the library root when
+// collectionID is not valid) and replaces its tags with the comma-separated
+// names in tagsCSV. New tags are created.
+func (s *MediaService) OrganizeMedia(ctx context.Context, id, collectionID pgtype.UUID, tagsCSV string) (*generated.Media, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	media, err := qtx.SetMediaCollection(ctx, generated.SetMediaCollectionParams{
+		CollectionID: collectionID,
+		ID:           id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set media collection: %w", err)
+	}
+
+	if err := qtx.ClearMediaTags(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to clear media tags: %w", err)
+	}
+	for _, tagName := range splitTagNames(tagsCSV) {
+		tag, err := findOrCreateMediaTag(ctx, qtx, tagName)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := qtx.AddMediaTag(ctx, generated.AddMediaTagParams{
+			MediaID: id,
+			TagID:   tag.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to add tag %q: %w", tagName, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit media organization: %w", err)
+	}
+	return &media, nil
+}
+
+// splitTagNames returns the non-blank names in a comma-separated tag list
+func splitTagNames(tagsCSV string) []string {
+	var names []string
+	for _, tagName := range strings.Split(tagsCSV, ",") {
+		if tagName = strings.TrimSpace(tagName); tagName != "" {
+			names = append(names, tagName)
+		}
+	}
+	return names
+}
+
+// findOrCreateMediaTag returns the tag named tagName, creating it if needed
+func findOrCreateMediaTag(ctx context.Context, qtx *generated.Queries, tagName string) (generated.Tag, error) {
+	tag, err := qtx.FindOrCreateTag(ctx, generated.FindOrCreateTagParams{
+		ID:   pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Name: tagName,
+		Slug: GenerateSlug(tagName),
+	})
+	if err != nil {
+		return generated.Tag{}, fmt.Errorf("failed to find or create tag %q: %w", tagName, err)
+	}
+	return tag, nil
+}
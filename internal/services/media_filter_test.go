@@ -0,0 +1,78 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMediaFilter(t *testing.T) {
+	t.Run("success: round trips through Query", func(t *testing.T) {
+		values := url.Values{
+			"collection": {"6f1c2a3e-0d4b-4c5a-9e8f-112233445566"},
+			"tag":        {"0a1b2c3d-4e5f-4a6b-8c7d-8e9fa0b1c2d3"},
+			"type":       {"image"},
+			"uploader":   {"11111111-2222-4333-8444-555555555555"},
+			"from":       {"2026-01-01"},
+			"to":         {"2026-01-31"},
+		}
+
+		// Act
+		f := ParseMediaFilter(values)
+
+		// Assert
+		assert.True(t, f.CollectionID.Valid)
+		assert.Equal(t, "image", f.Type)
+		assert.Equal(t, values, f.Query())
+	})
+
+	t.Run("success: ignores malformed values", func(t *testing.T) {
+		values := url.Values{
+			"collection": {"not-a-uuid"},
+			"type":       {"spreadsheet"},
+			"from":       {"01/02/2026"},
+		}
+
+		// Act
+		f := ParseMediaFilter(values)
+
+		// Assert
+		assert.Equal(t, MediaFilter{}, f)
+		assert.Empty(t, f.Query())
+	})
+}
+
+func TestMediaFilterParams(t *testing.T) {
+	t.Run("success: the to date includes the whole day", func(t *testing.T) {
+		f := MediaFilter{
+			Type: "video",
+			From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		}
+
+		// Act
+		p := f.params()
+
+		// Assert
+		require.NotNil(t, p.CreatedFrom)
+		require.NotNil(t, p.CreatedBefore)
+		assert.Equal(t, f.From, *p.CreatedFrom)
+		assert.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), *p.CreatedBefore)
+		assert.Equal(t, pgtype.Text{String: "video/%", Valid: true}, p.MimeTypePattern)
+	})
+
+	t.Run("success: an empty filter matches everything", func(t *testing.T) {
+		// Act
+		p := MediaFilter{}.params()
+
+		// Assert
+		assert.Nil(t, p.CreatedFrom)
+		assert.Nil(t, p.CreatedBefore)
+		assert.False(t, p.MimeTypePattern.Valid)
+		assert.False(t, p.CollectionID.Valid)
+	})
+}
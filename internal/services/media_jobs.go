@@ -0,0 +1,328 @@
+// internal/services/media_jobs.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Media processing statuses stored on the media row
+const (
+	MediaStatusPending    = "pending"
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
+
+// Media job types
+const (
+	MediaJobProcess = "process" // Renditions for images; metadata, thumbnail and transcodes for videos; page count and preview for PDFs
+	MediaJobCrops   = "crops"   // Image crops cut again after the focal point moves
+)
+
+const (
+	mediaJobMaxAttempts = 5
+	mediaJobBaseBackoff = 30 * time.Second
+	mediaJobMaxBackoff  = 1 * time.Hour
+)
+
+// needsProcessing reports whether an upload of this type gets a background job.
+// SVGs are vector and need no renditions.
+func needsProcessing(mimeType string) bool {
+	if mimeType == "image/svg+xml" {
+		return false
+	}
+	return strings.HasPrefix(mimeType, "image/") || strings.HasPrefix(mimeType, "video/") || IsPDF(mimeType)
+}
+
+// enqueueMediaJob adds a job to the queue using the given queries (pool or tx)
+func enqueueMediaJob(ctx context.Context, queries *generated.Queries, mediaID pgtype.UUID, jobType string) error {
+	_, err := queries.CreateMediaJob(ctx, generated.CreateMediaJobParams{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		MediaID:     mediaID,
+		JobType:     jobType,
+		MaxAttempts: mediaJobMaxAttempts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enqueue media job: %w", err)
+	}
+	return nil
+}
+
+// ReprocessMedia queues a media file for processing again (e.g. after a failure)
+func (s *MediaService) ReprocessMedia(ctx context.Context, mediaID pgtype.UUID) error {
+	if err := s.queries.UpdateMediaProcessingStatus(ctx, generated.UpdateMediaProcessingStatusParams{
+		ProcessingStatus: MediaStatusPending,
+		ID:               mediaID,
+	}); err != nil {
+		return fmt.Errorf("failed to reset processing status: %w", err)
+	}
+	return enqueueMediaJob(ctx, s.queries, mediaID, MediaJobProcess)
+}
+
+// ErrMediaJobInterrupted is returned when ctx is cancelled while a job runs.
+// The job has been put back in the queue.
+var ErrMediaJobInterrupted = errors.New("media job interrupted")
+
+// mediaJobReleaseTimeout bounds recording a job's outcome once ctx is
+// cancelled
+const mediaJobReleaseTimeout = 10 * time.Second
+
+// RunNextJob claims and runs a single queued job. It returns false when the
+// queue is empty. A job error is returned after it has been recorded for retry
+// or marked as failed. If ctx is cancelled mid-job the job is released back to
+// pending and ErrMediaJobInterrupted is returned.
+func (s *MediaService) RunNextJob(ctx context.Context) (bool, error) {
+	job, err := s.queries.ClaimMediaJob(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim media job: %w", err)
+	}
+
+	jobErr := s.runJob(ctx, job)
+
+	// Record the outcome even when shutdown cancelled ctx during the job
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), mediaJobReleaseTimeout)
+		defer cancel()
+
+		if jobErr != nil {
+			if err := s.queries.ReleaseMediaJob(ctx, job.ID); err != nil {
+				return true, fmt.Errorf("failed to release media job: %w", err)
+			}
+			s.setJobStatus(ctx, job, MediaStatusPending, pgtype.Text{})
+			return true, ErrMediaJobInterrupted
+		}
+	}
+
+	if jobErr == nil {
+		if err := s.queries.CompleteMediaJob(ctx, job.ID); err != nil {
+			return true, fmt.Errorf("failed to complete media job: %w", err)
+		}
+		return true, nil
+	}
+
+	lastError := pgtype.Text{String: jobErr.Error(), Valid: true}
+
+	// Retry with backoff until attempts are exhausted
+	if job.Attempts < job.MaxAttempts {
+		if err := s.queries.RetryMediaJob(ctx, generated.RetryMediaJobParams{
+			RunAt:     time.Now().Add(mediaJobBackoff(job.Attempts)),
+			LastError: lastError,
+			ID:        job.ID,
+		}); err != nil {
+			return true, fmt.Errorf("failed to reschedule media job: %w", err)
+		}
+		s.setJobStatus(ctx, job, MediaStatusPending, lastError)
+		return true, fmt.Errorf("media job attempt %d/%d failed: %w", job.Attempts, job.MaxAttempts, jobErr)
+	}
+
+	if err := s.queries.FailMediaJob(ctx, generated.FailMediaJobParams{
+		LastError: lastError,
+		ID:        job.ID,
+	}); err != nil {
+		return true, fmt.Errorf("failed to mark media job as failed: %w", err)
+	}
+	s.setJobStatus(ctx, job, MediaStatusFailed, lastError)
+	return true, fmt.Errorf("media job failed permanently: %w", jobErr)
+}
+
+// PurgeCompletedJobs removes completed jobs older than a week
+func (s *MediaService) PurgeCompletedJobs(ctx context.Context) error {
+	if err := s.queries.PurgeCompletedMediaJobs(ctx); err != nil {
+		return fmt.Errorf("failed to purge completed media jobs: %w", err)
+	}
+	return nil
+}
+
+func (s *MediaService) runJob(ctx context.Context, job generated.MediaJob) error {
+	switch job.JobType {
+	case MediaJobProcess:
+		return s.processMedia(ctx, job.MediaID)
+	case MediaJobCrops:
+		return s.cropMedia(ctx, job.MediaID)
+	default:
+		return fmt.Errorf("unknown media job type %q", job.JobType)
+	}
+}
+
+// processMedia generates renditions or a video thumbnail from the stored original
+func (s *MediaService) processMedia(ctx context.Context, mediaID pgtype.UUID) error {
+	media, err := s.queries.GetMediaByID(ctx, mediaID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Deleted before we got to it, nothing to do
+			return nil
+		}
+		return fmt.Errorf("failed to get media: %w", err)
+	}
+	if !media.OriginalKey.Valid {
+		return fmt.Errorf("media has no original key")
+	}
+
+	s.setProcessingStatus(ctx, mediaID, MediaStatusProcessing, pgtype.Text{})
+
+	src, err := s.storage.Open(ctx, media.OriginalKey.String)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	params := generated.UpdateMediaRenditionsParams{ID: mediaID}
+
+	switch {
+	case strings.HasPrefix(media.MimeType, "image/"):
+		processed, err := s.ProcessImage(ctx, src, media.OriginalKey.String, focalPointOf(&media))
+		if err != nil {
+			return err
+		}
+		params.Width = pgtype.Int4{Int32: int32(processed.Width), Valid: true}
+		params.Height = pgtype.Int4{Int32: int32(processed.Height), Valid: true}
+		params.LargeKey = renditionKey(processed.Renditions, "large")
+		params.MediumKey = renditionKey(processed.Renditions, "medium")
+		params.ThumbnailKey = renditionKey(processed.Renditions, "thumbnail")
+		params.HeroKey = renditionKey(processed.Crops, "hero")
+		params.CardKey = renditionKey(processed.Crops, "card")
+		params.OgKey = renditionKey(processed.Crops, "og")
+
+	case strings.HasPrefix(media.MimeType, "video/"):
+		if err := s.processVideo(ctx, src, &media, &params); err != nil {
+			return err
+		}
+
+	case IsPDF(media.MimeType):
+		if err := s.processPDF(ctx, src, &media, &params); err != nil {
+			return err
+		}
+	}
+
+	updated, err := s.queries.UpdateMediaRenditions(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to save renditions: %w", err)
+	}
+
+	s.deleteReplacedCrops(ctx, &media, &updated)
+	return nil
+}
+
+// processVideo probes a video for its metadata, generates its thumbnail and
+// transcodes the web MP4 and HLS renditions
+func (s *MediaService) processVideo(ctx context.Context, src io.Reader, media *generated.Media, params *generated.UpdateMediaRenditionsParams) error {
+	videoPath, err := saveTempFile(src, "video_*"+filepath.Ext(media.OriginalKey.String))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(videoPath)
+
+	meta, err := ProbeVideo(ctx, videoPath)
+	if err != nil {
+		return err
+	}
+
+	if err := s.queries.UpdateMediaMetadata(ctx, generated.UpdateMediaMetadataParams{
+		Width:      pgtype.Int4{Int32: int32(meta.Width), Valid: meta.Width > 0},
+		Height:     pgtype.Int4{Int32: int32(meta.Height), Valid: meta.Height > 0},
+		Duration:   pgtype.Int4{Int32: meta.DurationSeconds(), Valid: meta.Duration > 0},
+		VideoCodec: pgtype.Text{String: meta.Codec, Valid: meta.Codec != ""},
+		Bitrate:    pgtype.Int8{Int64: meta.Bitrate, Valid: meta.Bitrate > 0},
+		ID:         media.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to save video metadata: %w", err)
+	}
+
+	thumbKey, err := s.ProcessVideoThumbnail(ctx, videoPath, media.OriginalKey.String)
+	if err != nil {
+		return err
+	}
+	params.ThumbnailKey = pgtype.Text{String: thumbKey, Valid: true}
+
+	transcoded, err := s.TranscodeVideo(ctx, videoPath, media.OriginalKey.String, meta)
+	if err != nil {
+		return err
+	}
+	params.WebKey = pgtype.Text{String: transcoded.WebKey, Valid: true}
+	params.HlsKey = pgtype.Text{String: transcoded.HLSKey, Valid: true}
+
+	return nil
+}
+
+// processPDF reads a PDF's page count and title and renders its first page
+// as the thumbnail
+func (s *MediaService) processPDF(ctx context.Context, src io.Reader, media *generated.Media, params *generated.UpdateMediaRenditionsParams) error {
+	pdfPath, err := saveTempFile(src, "document_*.pdf")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(pdfPath)
+
+	meta, err := ProbePDF(ctx, pdfPath)
+	if err != nil {
+		return err
+	}
+
+	if err := s.queries.UpdateMediaDocumentMetadata(ctx, generated.UpdateMediaDocumentMetadataParams{
+		PageCount:     pgtype.Int4{Int32: int32(meta.Pages), Valid: true},
+		DocumentTitle: pgtype.Text{String: meta.Title, Valid: meta.Title != ""},
+		ID:            media.ID,
+	}); err != nil {
+		return fmt.Errorf("failed to save document metadata: %w", err)
+	}
+
+	previewKey, err := s.ProcessPDFPreview(ctx, pdfPath, media.OriginalKey.String)
+	if err != nil {
+		return err
+	}
+	params.ThumbnailKey = pgtype.Text{String: previewKey, Valid: true}
+
+	return nil
+}
+
+// setJobStatus records a job's progress on its media. Only processing jobs
+// do: a failed crop job leaves the media, and its renditions, ready.
+func (s *MediaService) setJobStatus(ctx context.Context, job generated.MediaJob, status string, processingError pgtype.Text) {
+	if job.JobType == MediaJobProcess {
+		s.setProcessingStatus(ctx, job.MediaID, status, processingError)
+	}
+}
+
+// setProcessingStatus records status on the media row; failures are only logged
+// since the job state is the source of truth for retries
+func (s *MediaService) setProcessingStatus(ctx context.Context, mediaID pgtype.UUID, status string, processingError pgtype.Text) {
+	if err := s.queries.UpdateMediaProcessingStatus(ctx, generated.UpdateMediaProcessingStatusParams{
+		ProcessingStatus: status,
+		ProcessingError:  processingError,
+		ID:               mediaID,
+	}); err != nil {
+		fmt.Printf("Warning: failed to update media processing status: %v\n", err)
+	}
+}
+
+// mediaJobBackoff returns the delay before the next attempt: 30s, 1m, 2m, ...
+// capped at an hour
+func mediaJobBackoff(attempt int32) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	backoff := mediaJobBaseBackoff
+	for i := int32(1); i < attempt; i++ {
+		backoff *= 2
+		if backoff >= mediaJobMaxBackoff {
+			return mediaJobMaxBackoff
+		}
+	}
+	return backoff
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaJobBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int32
+		want    time.Duration
+	}{
+		{
+			name:    "success: first attempt uses base backoff",
+			attempt: 1,
+			want:    30 * time.Second,
+		},
+		{
+			name:    "success: backoff doubles per attempt",
+			attempt: 3,
+			want:    2 * time.Minute,
+		},
+		{
+			name:    "success: backoff is capped at an hour",
+			attempt: 20,
+			want:    time.Hour,
+		},
+		{
+			name:    "edge: zero attempt treated as first",
+			attempt: 0,
+			want:    30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := mediaJobBackoff(tt.attempt)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNeedsProcessing(t *testing.T) {
+	tests := []struct {
+		name     string
+		mimeType string
+		want     bool
+	}{
+		{name: "success: images are processed", mimeType: "image/jpeg", want: true},
+		{name: "success: videos are processed", mimeType: "video/mp4", want: true},
+		{name: "success: pdfs are processed", mimeType: "application/pdf", want: true},
+		{name: "success: svgs are ready immediately", mimeType: "image/svg+xml", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := needsProcessing(tt.mimeType)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// createTestMedia creates a pending media row whose file is never stored
+func createTestMedia(t *testing.T, ctx context.Context, queries *generated.Queries, filename, mimeType string) generated.Media {
+	t.Helper()
+
+	media, err := queries.CreateMedia(ctx, generated.CreateMediaParams{
+		ID:               pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		Filename:         filename,
+		OriginalFilename: filename,
+		MimeType:         mimeType,
+		FileSize:         1024,
+		StorageType:      "local",
+		OriginalKey:      pgtype.Text{String: "media/" + filename, Valid: true},
+		ProcessingStatus: MediaStatusPending,
+	})
+	require.NoError(t, err, "failed to create media")
+
+	return media
+}
+
+// blockingStorage holds Open until the context is cancelled, standing in for
+// a slow download or transcode
+type blockingStorage struct {
+	StorageProvider
+	opened chan struct{}
+}
+
+func (b *blockingStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	close(b.opened)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestMediaService_RunNextJob tests that a job interrupted by shutdown goes
+// back in the queue
+func TestMediaService_RunNextJob(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	storage := &blockingStorage{
+		StorageProvider: NewLocalStorage(t.TempDir(), "/uploads", []byte("test-signing-key")),
+		opened:          make(chan struct{}),
+	}
+	mediaService := NewMediaService(pool, queries, storage, MediaConfig{})
+
+	media := createTestMedia(t, ctx, queries, "photo.jpg", "image/jpeg")
+	require.NoError(t, enqueueMediaJob(ctx, queries, media.ID, MediaJobProcess))
+
+	t.Run("edge: cancelling mid-job releases it to pending", func(t *testing.T) {
+		jobCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			<-storage.opened
+			cancel()
+		}()
+
+		// Act
+		ran, err := mediaService.RunNextJob(jobCtx)
+
+		// Assert
+		assert.True(t, ran)
+		assert.ErrorIs(t, err, ErrMediaJobInterrupted)
+
+		var status string
+		var attempts int32
+		err = pool.QueryRow(ctx, "SELECT status, attempts FROM media_jobs WHERE media_id = $1", media.ID).Scan(&status, &attempts)
+		require.NoError(t, err)
+		assert.Equal(t, "pending", status)
+		assert.Equal(t, int32(0), attempts, "interrupted attempt should not count")
+
+		got, err := queries.GetMediaByID(ctx, media.ID)
+		require.NoError(t, err)
+		assert.Equal(t, MediaStatusPending, got.ProcessingStatus)
+	})
+}
+
+// TestMediaService_RunCropJob tests that a failed re-crop leaves the media
+// ready, since its existing renditions are still served
+func TestMediaService_RunCropJob(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	// The original was never written, so every crop attempt fails to open it
+	storage := NewLocalStorage(t.TempDir(), "/uploads", []byte("test-signing-key"))
+	mediaService := NewMediaService(pool, queries, storage, MediaConfig{})
+
+	media := createTestMedia(t, ctx, queries, "photo.jpg", "image/jpeg")
+	require.NoError(t, queries.UpdateMediaProcessingStatus(ctx, generated.UpdateMediaProcessingStatusParams{
+		ProcessingStatus: MediaStatusReady,
+		ID:               media.ID,
+	}))
+	_, err := queries.CreateMediaJob(ctx, generated.CreateMediaJobParams{
+		ID:          pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		MediaID:     media.ID,
+		JobType:     MediaJobCrops,
+		MaxAttempts: 1,
+	})
+	require.NoError(t, err, "failed to enqueue crop job")
+
+	t.Run("edge: failed crop job keeps media ready", func(t *testing.T) {
+		// Act
+		ran, err := mediaService.RunNextJob(ctx)
+
+		// Assert
+		assert.True(t, ran)
+		assert.Error(t, err)
+
+		var status string
+		err = pool.QueryRow(ctx, "SELECT status FROM media_jobs WHERE media_id = $1", media.ID).Scan(&status)
+		require.NoError(t, err)
+		assert.Equal(t, "failed", status)
+
+		got, err := queries.GetMediaByID(ctx, media.ID)
+		require.NoError(t, err)
+		assert.Equal(t, MediaStatusReady, got.ProcessingStatus)
+		assert.False(t, got.ProcessingError.Valid)
+	})
+
+	t.Run("success: focal point can still be changed", func(t *testing.T) {
+		// Act
+		_, err := mediaService.SetFocalPoint(ctx, media.ID, &FocalPoint{X: 0.25, Y: 0.75})
+
+		// Assert
+		assert.NoError(t, err)
+	})
+}
@@ -2,41 +2,138 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
-// ProcessVideoThumbnail generates a thumbnail and uploads it, returning the storage key
-func (s *MediaService) ProcessVideoThumbnail(ctx context.Context, videoFile *multipart.FileHeader, originalKey string) (string, error) {
-	// Create temp files
-	tmpDir := os.TempDir()
-	videoPath := filepath.Join(tmpDir, fmt.Sprintf("video_%d%s", os.Getpid(), filepath.Ext(videoFile.Filename)))
-	thumbPath := filepath.Join(tmpDir, fmt.Sprintf("thumb_%d.jpg", os.Getpid()))
+// VideoMetadata holds the values we read from ffprobe
+type VideoMetadata struct {
+	Duration float64 // Seconds
+	Width    int     // Display width (rotation applied)
+	Height   int     // Display height (rotation applied)
+	Codec    string
+	Bitrate  int64 // Bits per second
+}
 
-	defer os.Remove(videoPath)
-	defer os.Remove(thumbPath)
+// DurationSeconds returns the duration rounded to whole seconds, as stored on media.duration
+func (m *VideoMetadata) DurationSeconds() int32 {
+	return int32(math.Round(m.Duration))
+}
 
-	// Save video to temp file
-	src, err := videoFile.Open()
+// saveTempFile copies src to a uniquely named temp file; the caller removes it
+func saveTempFile(src io.Reader, pattern string) (string, error) {
+	dst, err := os.CreateTemp("", pattern)
 	if err != nil {
-		return "", fmt.Errorf("failed to open video: %w", err)
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("failed to save temp file: %w", err)
 	}
-	defer src.Close()
 
-	dst, err := os.Create(videoPath)
+	return dst.Name(), nil
+}
+
+// ProbeVideo runs ffprobe against a local video file
+func ProbeVideo(ctx context.Context, videoPath string) (*VideoMetadata, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		videoPath,
+	)
+
+	output, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp video: %w", err)
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	return parseProbeOutput(output)
+}
+
+type probeOutput struct {
+	Streams []struct {
+		CodecType    string            `json:"codec_type"`
+		CodecName    string            `json:"codec_name"`
+		Width        int               `json:"width"`
+		Height       int               `json:"height"`
+		Duration     string            `json:"duration"`
+		BitRate      string            `json:"bit_rate"`
+		Tags         map[string]string `json:"tags"`
+		SideDataList []struct {
+			Rotation float64 `json:"rotation"`
+		} `json:"side_data_list"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// parseProbeOutput extracts metadata from ffprobe's JSON output, preferring
+// container-level duration and bitrate over the video stream's
+func parseProbeOutput(data []byte) (*VideoMetadata, error) {
+	var probe probeOutput
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	meta := &VideoMetadata{}
+	found := false
+
+	for _, stream := range probe.Streams {
+		if stream.CodecType != "video" {
+			continue
+		}
+		found = true
+
+		meta.Codec = stream.CodecName
+		meta.Width = stream.Width
+		meta.Height = stream.Height
+		meta.Duration, _ = strconv.ParseFloat(stream.Duration, 64)
+		meta.Bitrate, _ = strconv.ParseInt(stream.BitRate, 10, 64)
+
+		// Phones record portrait video as rotated landscape
+		rotation, _ := strconv.ParseFloat(stream.Tags["rotate"], 64)
+		for _, side := range stream.SideDataList {
+			if side.Rotation != 0 {
+				rotation = side.Rotation
+			}
+		}
+		if int(math.Abs(rotation))%180 == 90 {
+			meta.Width, meta.Height = meta.Height, meta.Width
+		}
+		break
 	}
-	if _, err = io.Copy(dst, src); err != nil {
-		dst.Close()
-		return "", fmt.Errorf("failed to save video: %w", err)
+
+	if !found {
+		return nil, fmt.Errorf("no video stream found")
 	}
-	dst.Close()
+
+	if d, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil && d > 0 {
+		meta.Duration = d
+	}
+	if b, err := strconv.ParseInt(probe.Format.BitRate, 10, 64); err == nil && b > 0 {
+		meta.Bitrate = b
+	}
+
+	return meta, nil
+}
+
+// ProcessVideoThumbnail generates a thumbnail and uploads it, returning the storage key
+func (s *MediaService) ProcessVideoThumbnail(ctx context.Context, videoPath string, originalKey string) (string, error) {
+	thumbPath := strings.TrimSuffix(videoPath, filepath.Ext(videoPath)) + "_thumb.jpg"
+	defer os.Remove(thumbPath)
 
 	// Run FFmpeg to extract thumbnail
 	cmd := exec.CommandContext(ctx, "ffmpeg",
@@ -54,58 +151,12 @@ func (s *MediaService) ProcessVideoThumbnail(ctx context.Context, videoFile *mul
 		return "", fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, string(output))
 	}
 
-	// Verify thumbnail was created
-	if _, err := os.Stat(thumbPath); err != nil {
-		return "", fmt.Errorf("thumbnail not created: %w", err)
-	}
-
 	// Generate storage key for thumbnail
 	thumbnailKey := strings.TrimSuffix(originalKey, filepath.Ext(originalKey)) + "_thumb.jpg"
 
-	// Upload based on storage type
-	switch storage := s.storage.(type) {
-	case *LocalStorage:
-		// For local storage, copy the file
-		dstPath := filepath.Join(storage.uploadDir, thumbnailKey)
-		if err := os.MkdirAll(filepath.Dir(dstPath), 0o755); err != nil {
-			return "", fmt.Errorf("failed to create thumbnail directory: %w", err)
-		}
-		if err := copyFile(thumbPath, dstPath); err != nil {
-			return "", fmt.Errorf("failed to copy thumbnail: %w", err)
-		}
-
-	case *S3Storage:
-		// For S3, upload the file
-		thumbFile, err := os.Open(thumbPath)
-		if err != nil {
-			return "", fmt.Errorf("failed to open thumbnail: %w", err)
-		}
-		defer thumbFile.Close()
-
-		if err := storage.uploadFile(ctx, thumbFile, thumbnailKey, "image/jpeg"); err != nil {
-			return "", fmt.Errorf("failed to upload thumbnail to S3: %w", err)
-		}
-	default:
-		return "", fmt.Errorf("unsupported storage type")
+	if err := s.uploadLocalFile(ctx, thumbPath, thumbnailKey, "image/jpeg"); err != nil {
+		return "", err
 	}
 
 	return thumbnailKey, nil
 }
-
-// copyFile is a helper to copy files
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer in.Close()
-
-	out, err := os.Create(dst)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, in)
-	return err
-}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *VideoMetadata
+		wantErr bool
+	}{
+		{
+			name: "success: container duration and bitrate preferred",
+			output: `{
+				"streams": [
+					{"codec_type": "audio", "codec_name": "aac"},
+					{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080, "duration": "12.000", "bit_rate": "4000000"}
+				],
+				"format": {"duration": "12.480", "bit_rate": "4200000"}
+			}`,
+			want: &VideoMetadata{Duration: 12.48, Width: 1920, Height: 1080, Codec: "h264", Bitrate: 4200000},
+		},
+		{
+			name: "success: rotated phone video swaps dimensions",
+			output: `{
+				"streams": [
+					{"codec_type": "video", "codec_name": "hevc", "width": 1920, "height": 1080, "side_data_list": [{"rotation": -90}]}
+				],
+				"format": {"duration": "3.2", "bit_rate": "9000000"}
+			}`,
+			want: &VideoMetadata{Duration: 3.2, Width: 1080, Height: 1920, Codec: "hevc", Bitrate: 9000000},
+		},
+		{
+			name: "success: legacy rotate tag",
+			output: `{
+				"streams": [
+					{"codec_type": "video", "codec_name": "h264", "width": 640, "height": 480, "tags": {"rotate": "270"}}
+				],
+				"format": {}
+			}`,
+			want: &VideoMetadata{Width: 480, Height: 640, Codec: "h264"},
+		},
+		{
+			name:    "failure: no video stream",
+			output:  `{"streams": [{"codec_type": "audio", "codec_name": "mp3"}], "format": {}}`,
+			wantErr: true,
+		},
+		{
+			name:    "failure: invalid json",
+			output:  `not json`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := parseProbeOutput([]byte(tt.output))
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -2,6 +2,9 @@
 package services
 
 import (
+	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,13 +21,32 @@ type MediaResponse struct {
 	FileSize         int64
 	Width            pgtype.Int4
 	Height           pgtype.Int4
-	Duration         pgtype.Int4
+	Duration         pgtype.Int4 // Seconds (videos)
+	VideoCodec       string
+	Bitrate          int64 // Bits per second (videos)
+	PageCount        int32 // PDFs; 0 until processed
+	DocumentTitle    string
+	Artist           string
+	Copyright        string
 	StorageType      string
 	AltText          string
-	URL              string // Public URL for serving the file
-	ThumbnailURL     string // URL for thumbnail (if available)
+	CollectionID     pgtype.UUID // Not valid at the library root
+	IsPrivate        bool        // URLs are signed and expire
+	URL              string      // URL for serving the file
+	LargeURL         string      // 1920px rendition (falls back to URL)
+	MediumURL        string      // 1024px rendition (falls back to URL)
+	ThumbnailURL     string      // URL for thumbnail (if available)
+	WebURL           string      // Transcoded H.264 MP4 (falls back to URL)
+	HeroURL          string      // 16:9 crop around the focal point (falls back to LargeURL)
+	CardURL          string      // 1:1 crop around the focal point (falls back to MediumURL)
+	OGURL            string      // 1200x630 crop around the focal point (falls back to LargeURL)
+	FocalPoint       FocalPoint  // The centre unless an editor has set one
+	HLSURL           string      // HLS master playlist (empty until transcoded, or for private media)
+	ProcessingStatus string      // pending, processing, ready or failed
+	ProcessingError  string
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+	DeletedAt        *time.Time // Set while the media is in the trash
 }
 
 // ToMediaResponse converts a generated.Media to MediaResponse with URLs
@@ -36,8 +58,14 @@ func (s *MediaService) ToMediaResponse(media *generated.Media) MediaResponse {
 		MimeType:         media.MimeType,
 		FileSize:         media.FileSize,
 		StorageType:      media.StorageType,
+		CollectionID:     media.CollectionID,
+		FocalPoint:       focalPointOf(media),
+		IsPrivate:        media.IsPrivate,
+		ProcessingStatus: media.ProcessingStatus,
+		ProcessingError:  media.ProcessingError.String,
 		CreatedAt:        media.CreatedAt,
 		UpdatedAt:        media.UpdatedAt,
+		DeletedAt:        media.DeletedAt,
 	}
 
 	// Handle optional fields
@@ -57,10 +85,39 @@ func (s *MediaService) ToMediaResponse(media *generated.Media) MediaResponse {
 	if media.AltText.Valid {
 		resp.AltText = media.AltText.String
 	}
+	if media.VideoCodec.Valid {
+		resp.VideoCodec = media.VideoCodec.String
+	}
+	if media.Bitrate.Valid {
+		resp.Bitrate = media.Bitrate.Int64
+	}
+	if media.PageCount.Valid {
+		resp.PageCount = media.PageCount.Int32
+	}
+	if media.DocumentTitle.Valid {
+		resp.DocumentTitle = media.DocumentTitle.String
+	}
+	if media.Artist.Valid {
+		resp.Artist = media.Artist.String
+	}
+	if media.Copyright.Valid {
+		resp.Copyright = media.Copyright.String
+	}
 
 	// Generate URLs
 	resp.URL = s.GetMediaURL(media)
+	resp.LargeURL = s.getRenditionURL(media, media.LargeKey)
+	resp.MediumURL = s.getRenditionURL(media, media.MediumKey)
 	resp.ThumbnailURL = s.GetThumbnailURL(media)
+	resp.WebURL = s.getRenditionURL(media, media.WebKey)
+	resp.HeroURL = s.getCropURL(media, media.HeroKey, resp.LargeURL)
+	resp.CardURL = s.getCropURL(media, media.CardKey, resp.MediumURL)
+	resp.OGURL = s.getCropURL(media, media.OgKey, resp.LargeURL)
+	// Segment URLs in the playlists are relative and unsigned, so private
+	// videos play from the MP4 instead
+	if media.HlsKey.Valid && !media.IsPrivate {
+		resp.HLSURL = s.storage.GetURL(media.HlsKey.String)
+	}
 
 	return resp
 }
@@ -81,21 +138,141 @@ func (s *MediaService) GetThumbnailURL(media *generated.Media) string {
 		return s.GetMediaURL(media)
 	}
 
-	// Use the same URL generation logic as GetMediaURL, but with thumbnail key
-	if media.StorageType == "s3" {
-		// For S3-compatible storage, use GetURL from storage provider
-		if storage, ok := s.storage.(*S3Storage); ok {
-			return storage.GetURL(media.ThumbnailKey.String)
+	return s.mediaURL(media, media.ThumbnailKey.String)
+}
+
+// getRenditionURL returns the URL for a rendition key, falling back to the original
+func (s *MediaService) getRenditionURL(media *generated.Media, key pgtype.Text) string {
+	if !key.Valid || key.String == "" {
+		return s.GetMediaURL(media)
+	}
+	return s.mediaURL(media, key.String)
+}
+
+// getCropURL returns the URL for a crop key, falling back to a rendition
+// for media processed before crops existed
+func (s *MediaService) getCropURL(media *generated.Media, key pgtype.Text, fallback string) string {
+	if !key.Valid || key.String == "" {
+		return fallback
+	}
+	return s.mediaURL(media, key.String)
+}
+
+// ObjectPosition is the CSS object-position keeping the focal point in view
+// when the image is cropped by object-cover, e.g. "30% 45%"
+func (m MediaResponse) ObjectPosition() string {
+	return fmt.Sprintf("%s%% %s%%", percent(m.FocalPoint.X), percent(m.FocalPoint.Y))
+}
+
+// percent formats a fraction as a percentage with at most one decimal
+func percent(fraction float64) string {
+	return strconv.FormatFloat(math.Round(fraction*1000)/10, 'f', -1, 64)
+}
+
+// SrcSet builds an HTML srcset from the available renditions, e.g.
+// "/a_thumbnail.jpg 300w, /a_medium.jpg 1024w, /a.jpg 1600w"
+func (m MediaResponse) SrcSet() string {
+	if !m.Width.Valid || !IsImage(m.MimeType) {
+		return ""
+	}
+
+	var entries []string
+	for i := len(imageRenditions) - 1; i >= 0; i-- {
+		rendition := imageRenditions[i]
+		if int(m.Width.Int32) <= rendition.MaxWidth {
+			continue
+		}
+
+		var url string
+		switch rendition.Name {
+		case "large":
+			url = m.LargeURL
+		case "medium":
+			url = m.MediumURL
+		case "thumbnail":
+			url = m.ThumbnailURL
 		}
-	} else if media.StorageType == "local" {
-		// For local storage
-		if storage, ok := s.storage.(*LocalStorage); ok {
-			return storage.GetURL(media.ThumbnailKey.String)
+		if url != "" && url != m.URL {
+			entries = append(entries, fmt.Sprintf("%s %dw", url, rendition.MaxWidth))
 		}
 	}
+	entries = append(entries, fmt.Sprintf("%s %dw", m.URL, m.Width.Int32))
+
+	return strings.Join(entries, ", ")
+}
+
+// FormatDuration returns the play length as m:ss (or h:mm:ss), empty if unknown
+func (m MediaResponse) FormatDuration() string {
+	if !m.Duration.Valid {
+		return ""
+	}
+	total := int(m.Duration.Int32)
+	hours, minutes, seconds := total/3600, (total%3600)/60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, seconds)
+}
+
+// FormatBitrate returns the bitrate in kbps or Mbps, empty if unknown
+func (m MediaResponse) FormatBitrate() string {
+	switch {
+	case m.Bitrate <= 0:
+		return ""
+	case m.Bitrate >= 1_000_000:
+		return fmt.Sprintf("%.1f Mbps", float64(m.Bitrate)/1_000_000)
+	default:
+		return fmt.Sprintf("%d kbps", m.Bitrate/1000)
+	}
+}
+
+// PurgeAt is when trashed media is permanently deleted; zero if it is not
+// in the trash
+func (m MediaResponse) PurgeAt() time.Time {
+	if m.DeletedAt == nil {
+		return time.Time{}
+	}
+	return m.DeletedAt.Add(MediaTrashRetention)
+}
+
+// DaysUntilPurge is the number of whole days, rounded up, before trashed
+// media is permanently deleted; 0 once it is due
+func (m MediaResponse) DaysUntilPurge() int {
+	remaining := time.Until(m.PurgeAt())
+	if m.DeletedAt == nil || remaining <= 0 {
+		return 0
+	}
+	return int((remaining + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// FormatPageCount returns e.g. "12 pages", empty if unknown
+func (m MediaResponse) FormatPageCount() string {
+	switch m.PageCount {
+	case 0:
+		return ""
+	case 1:
+		return "1 page"
+	default:
+		return fmt.Sprintf("%d pages", m.PageCount)
+	}
+}
+
+// DisplayTitle is the document title, falling back to the uploaded filename
+func (m MediaResponse) DisplayTitle() string {
+	if m.DocumentTitle != "" {
+		return m.DocumentTitle
+	}
+	return m.OriginalFilename
+}
+
+// Croppable reports whether the media has a focal point and crops
+func (m MediaResponse) Croppable() bool {
+	return IsImage(m.MimeType) && needsProcessing(m.MimeType)
+}
 
-	// Fallback to original URL
-	return s.GetMediaURL(media)
+// IsProcessing reports whether background processing is still outstanding
+func (m MediaResponse) IsProcessing() bool {
+	return m.ProcessingStatus == MediaStatusPending || m.ProcessingStatus == MediaStatusProcessing
 }
 
 // Helper: Check if media is an image
@@ -119,8 +296,12 @@ func GetExtensionFromMimeType(mimeType string) string {
 		return ".gif"
 	case "image/webp":
 		return ".webp"
+	case "image/svg+xml":
+		return ".svg"
 	case "video/mp4":
 		return ".mp4"
+	case "video/quicktime":
+		return ".mov"
 	case "application/pdf":
 		return ".pdf"
 	default:
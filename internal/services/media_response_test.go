@@ -0,0 +1,58 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaysUntilPurge(t *testing.T) {
+	deletedAgo := func(d time.Duration) *time.Time {
+		at := time.Now().Add(-d)
+		return &at
+	}
+
+	tests := []struct {
+		name      string
+		deletedAt *time.Time
+		want      int
+	}{
+		{name: "success: just deleted", deletedAt: deletedAgo(time.Minute), want: 30},
+		{name: "success: partial days round up", deletedAt: deletedAgo(29*24*time.Hour + time.Hour), want: 1},
+		{name: "edge: purge is due", deletedAt: deletedAgo(MediaTrashRetention + time.Hour), want: 0},
+		{name: "edge: not in the trash", deletedAt: nil, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := MediaResponse{DeletedAt: tt.deletedAt}.DaysUntilPurge()
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatPageCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		pageCount int32
+		want      string
+	}{
+		{name: "success: several pages", pageCount: 12, want: "12 pages"},
+		{name: "success: single page", pageCount: 1, want: "1 page"},
+		{name: "edge: not yet processed", pageCount: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := MediaResponse{PageCount: tt.pageCount}.FormatPageCount()
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+// internal/services/media_usage.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MediaReference is a place where a project, blog post or page uses media
+type MediaReference struct {
+	EntityType string // project, blog or page
+	EntityID   pgtype.UUID
+	Field      string // featured, gallery, document, hero, content_image, og_image or body
+	Title      string
+	Slug       string
+}
+
+// EditURL links to the admin editor of the content using the media
+func (r MediaReference) EditURL() string {
+	switch r.EntityType {
+	case "project":
+		return "/admin/projects/" + r.Slug
+	case "blog":
+		return "/admin/blogs/" + r.Slug
+	case "page":
+		return "/admin/pages/" + r.Slug
+	}
+	return ""
+}
+
+// EntityLabel names the kind of content using the media
+func (r MediaReference) EntityLabel() string {
+	switch r.EntityType {
+	case "project":
+		return "Project"
+	case "blog":
+		return "Blog post"
+	case "page":
+		return "Page"
+	}
+	return r.EntityType
+}
+
+// FieldLabel describes how the content uses the media
+func (r MediaReference) FieldLabel() string {
+	switch r.Field {
+	case "featured":
+		return "Featured image"
+	case "gallery":
+		return "Gallery"
+	case "document":
+		return "Case study document"
+	case "hero":
+		return "Hero"
+	case "content_image":
+		return "Content image"
+	case "og_image":
+		return "Social share image"
+	case "body":
+		return "Body image"
+	}
+	return r.Field
+}
+
+// MediaInUseError is returned when deleting media that content still uses
+type MediaInUseError struct {
+	References []MediaReference
+}
+
+func (e *MediaInUseError) Error() string {
+	if len(e.References) == 1 {
+		return "media is used in 1 place"
+	}
+	return fmt.Sprintf("media is used in %d places", len(e.References))
+}
+
+// ErrInvalidReplacement is returned when media cannot replace the original,
+// because it is the same media or no longer exists
+var ErrInvalidReplacement = errors.New("choose different media to replace it with")
+
+// ErrReplacementKindMismatch is returned when media shown as an image would be
+// replaced by a video or document, or the other way round
+var ErrReplacementKindMismatch = errors.New("replace it with media of the same kind")
+
+// imageFields are the reference fields that show media as an image
+var imageFields = map[string]bool{
+	"featured":      true,
+	"hero":          true,
+	"content_image": true,
+	"og_image":      true,
+	"body":          true,
+}
+
+// mediaKind groups media by how pages can show it: image, video or document
+func mediaKind(mimeType string) string {
+	switch {
+	case IsImage(mimeType):
+		return "image"
+	case IsVideo(mimeType):
+		return "video"
+	}
+	return "document"
+}
+
+// GetMediaReferences lists where live content uses the media
+func (s *MediaService) GetMediaReferences(ctx context.Context, id pgtype.UUID) ([]MediaReference, error) {
+	rows, err := s.queries.ListMediaReferences(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media references: %w", err)
+	}
+
+	refs := make([]MediaReference, len(rows))
+	for i, row := range rows {
+		refs[i] = MediaReference{
+			EntityType: row.EntityType,
+			EntityID:   row.EntityID,
+			Field:      row.Field,
+			Title:      row.Title,
+			Slug:       row.Slug,
+		}
+	}
+	return refs, nil
+}
+
+// ReplaceMedia points every relation, image column and markdown body that
+// uses oldID at newID instead, returning how many live projects, blog posts
+// and pages changed. Gallery entries the replacement already has are dropped
+// rather than duplicated. Media shown as an image can only be replaced by
+// media of the same kind.
+func (s *MediaService) ReplaceMedia(ctx context.Context, oldID, newID pgtype.UUID) (int64, error) {
+	if oldID == newID {
+		return 0, ErrInvalidReplacement
+	}
+	original, err := s.queries.GetMediaByIDIncludingDeleted(ctx, oldID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get media: %w", err)
+	}
+	replacement, err := s.queries.GetMediaByID(ctx, newID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrInvalidReplacement
+		}
+		return 0, fmt.Errorf("failed to get replacement media: %w", err)
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	// Content using the media in several fields is counted once
+	refs, err := qtx.ListMediaReferences(ctx, oldID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list media references: %w", err)
+	}
+	type contentItem struct {
+		entityType string
+		entityID   pgtype.UUID
+	}
+	sameKind := mediaKind(original.MimeType) == mediaKind(replacement.MimeType)
+	replaced := make(map[contentItem]bool)
+	for _, ref := range refs {
+		if !sameKind && imageFields[ref.Field] {
+			return 0, ErrReplacementKindMismatch
+		}
+		replaced[contentItem{ref.EntityType, ref.EntityID}] = true
+	}
+
+	if err := qtx.DeleteMediaRelationsSharedWith(ctx, generated.DeleteMediaRelationsSharedWithParams{
+		OldMediaID: oldID,
+		NewMediaID: newID,
+	}); err != nil {
+		return 0, fmt.Errorf("failed to remove duplicate relations: %w", err)
+	}
+
+	if err := qtx.ReplaceMediaInRelations(ctx, generated.ReplaceMediaInRelationsParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace media relations: %w", err)
+	}
+	if err := qtx.ReplaceProjectFeaturedImage(ctx, generated.ReplaceProjectFeaturedImageParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace project featured images: %w", err)
+	}
+	if err := qtx.ReplaceBlogFeaturedImage(ctx, generated.ReplaceBlogFeaturedImageParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace blog featured images: %w", err)
+	}
+	if err := qtx.ReplacePageHeroMedia(ctx, generated.ReplacePageHeroMediaParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace page hero media: %w", err)
+	}
+	if err := qtx.ReplacePageContentImage(ctx, generated.ReplacePageContentImageParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace page content images: %w", err)
+	}
+	if err := qtx.ReplaceSeoOgImage(ctx, generated.ReplaceSeoOgImageParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace social share images: %w", err)
+	}
+	if err := qtx.ReplaceProjectBodyMedia(ctx, generated.ReplaceProjectBodyMediaParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace media in project bodies: %w", err)
+	}
+	if err := qtx.ReplaceBlogBodyMedia(ctx, generated.ReplaceBlogBodyMediaParams{OldMediaID: oldID, NewMediaID: newID}); err != nil {
+		return 0, fmt.Errorf("failed to replace media in blog bodies: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit media replacement: %w", err)
+	}
+	return int64(len(replaced)), nil
+}
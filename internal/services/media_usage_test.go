@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaReferenceEditURL(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  MediaReference
+		want string
+	}{
+		{"success: project", MediaReference{EntityType: "project", Slug: "harbour"}, "/admin/projects/harbour"},
+		{"success: blog post", MediaReference{EntityType: "blog", Slug: "notes"}, "/admin/blogs/notes"},
+		{"success: page", MediaReference{EntityType: "page", Slug: "about"}, "/admin/pages/about"},
+		{"error: unknown content", MediaReference{EntityType: "seo", Slug: "x"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.ref.EditURL()
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMediaInUseError(t *testing.T) {
+	t.Run("success: one place", func(t *testing.T) {
+		err := &MediaInUseError{References: []MediaReference{{}}}
+
+		// Assert
+		assert.Equal(t, "media is used in 1 place", err.Error())
+	})
+
+	t.Run("success: several places", func(t *testing.T) {
+		err := &MediaInUseError{References: make([]MediaReference, 3)}
+
+		// Assert
+		assert.Equal(t, "media is used in 3 places", err.Error())
+	})
+}
+
+func TestReplaceMediaWithItself(t *testing.T) {
+	// No queries are configured: replacing media with itself must fail early
+	s := &MediaService{}
+	id := pgtype.UUID{Bytes: [16]byte{1}, Valid: true}
+
+	// Act
+	_, err := s.ReplaceMedia(context.Background(), id, id)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidReplacement)
+}
+
+// TestMediaService_BodyReferences tests that media embedded in a markdown
+// body counts as used and moves with "Replace everywhere"
+func TestMediaService_BodyReferences(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	mediaService := NewMediaService(pool, queries, nil, MediaConfig{})
+	blogService := NewBlogService(queries, mediaService)
+	author := createTestUser(t, ctx, queries, "author@example.com", "AuthorPass123!", "Blog", "Author")
+
+	original := createTestMedia(t, ctx, queries, "floor-plan.png", "image/png")
+	replacement := createTestMedia(t, ctx, queries, "floor-plan-v2.png", "image/png")
+	originalID := uuid.UUID(original.ID.Bytes)
+
+	// The post uses the image twice, as its featured image and in its body
+	blog, err := blogService.CreateBlog(ctx, &CreateBlogRequest{
+		Title:           "Renovation notes",
+		Slug:            "renovation-notes",
+		Body:            "Before:\n\n![Ground floor](media:" + originalID.String() + ")",
+		Status:          "published",
+		FeaturedImageID: &originalID,
+		AuthorID:        author.ID.Bytes,
+	})
+	require.NoError(t, err, "failed to create blog")
+
+	t.Run("success: body reference is listed", func(t *testing.T) {
+		// Act
+		refs, err := mediaService.GetMediaReferences(ctx, original.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Contains(t, refs, MediaReference{
+			EntityType: "blog",
+			EntityID:   blog.Blog.ID,
+			Field:      "body",
+			Title:      "Renovation notes",
+			Slug:       "renovation-notes",
+		})
+	})
+
+	t.Run("failure: media used in a body cannot be deleted", func(t *testing.T) {
+		// Act
+		err := mediaService.DeleteMedia(ctx, original.ID, false)
+
+		// Assert
+		var inUse *MediaInUseError
+		assert.ErrorAs(t, err, &inUse)
+	})
+
+	t.Run("success: replacing rewrites the body and counts the post once", func(t *testing.T) {
+		// Act
+		replaced, err := mediaService.ReplaceMedia(ctx, original.ID, replacement.ID)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), replaced)
+
+		updated, err := blogService.GetBlogBySlug(ctx, "renovation-notes")
+		require.NoError(t, err)
+		assert.Contains(t, updated.Blog.Body.String, "media:"+uuid.UUID(replacement.ID.Bytes).String())
+		assert.NotContains(t, updated.Blog.Body.String, "media:"+originalID.String())
+
+		refs, err := mediaService.GetMediaReferences(ctx, original.ID)
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+	})
+}
+
+// TestMediaService_ReplaceMediaKind tests that media shown as an image is only
+// replaced by media of the same kind
+func TestMediaService_ReplaceMediaKind(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	mediaService := NewMediaService(pool, queries, nil, MediaConfig{})
+	blogService := NewBlogService(queries, mediaService)
+	author := createTestUser(t, ctx, queries, "author@example.com", "AuthorPass123!", "Blog", "Author")
+
+	original := createTestMedia(t, ctx, queries, "cover.jpg", "image/jpeg")
+	originalID := uuid.UUID(original.ID.Bytes)
+	_, err := blogService.CreateBlog(ctx, &CreateBlogRequest{
+		Title:           "Launch day",
+		Slug:            "launch-day",
+		Status:          "published",
+		FeaturedImageID: &originalID,
+		AuthorID:        author.ID.Bytes,
+	})
+	require.NoError(t, err, "failed to create blog")
+
+	tests := []struct {
+		name     string
+		filename string
+		mimeType string
+		wantErr  error
+	}{
+		{"failure: document cannot replace a featured image", "brochure.pdf", "application/pdf", ErrReplacementKindMismatch},
+		{"failure: video cannot replace a featured image", "teaser.mp4", "video/mp4", ErrReplacementKindMismatch},
+		{"success: image replaces a featured image", "cover-v2.png", "image/png", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replacement := createTestMedia(t, ctx, queries, tt.filename, tt.mimeType)
+
+			// Act
+			replaced, err := mediaService.ReplaceMedia(ctx, original.ID, replacement.ID)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				refs, err := mediaService.GetMediaReferences(ctx, original.ID)
+				require.NoError(t, err)
+				assert.Len(t, refs, 1, "the original stays in place")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), replaced)
+		})
+	}
+}
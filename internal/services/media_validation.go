@@ -0,0 +1,445 @@
+// internal/services/media_validation.go
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	sniffLen          = 512
+	maxImagePixels    = 100_000_000 // Reject decompression bombs
+	maxTrailingBytes  = 16          // Padding some encoders leave after the end marker
+	pdfScanBufferSize = 64 * 1024
+)
+
+// InspectedUpload is the result of inspecting an uploaded file's content
+type InspectedUpload struct {
+	MimeType string        // Detected from content, not the client header
+	Width    int           // Images only, as displayed (EXIF orientation applied)
+	Height   int           // Images only, as displayed (EXIF orientation applied)
+	Metadata ImageMetadata // Raster images only
+	// Sanitized replaces the upload when the content had to be rewritten
+	// (e.g. SVG with scripts removed); nil when the original is safe to store
+	Sanitized []byte
+
+	raster []byte // Raster image content, kept for metadata stripping
+}
+
+// mimeAliases normalizes client-supplied types that name the same format
+var mimeAliases = map[string]string{
+	"image/jpg":         "image/jpeg",
+	"image/pjpeg":       "image/jpeg",
+	"video/x-m4v":       "video/mp4",
+	"application/x-pdf": "application/pdf",
+}
+
+// normalizeMimeType strips parameters and resolves aliases
+func normalizeMimeType(mimeType string) string {
+	mimeType = strings.ToLower(strings.TrimSpace(strings.Split(mimeType, ";")[0]))
+	if alias, ok := mimeAliases[mimeType]; ok {
+		return alias
+	}
+	return mimeType
+}
+
+// InspectUpload detects the real content type of an upload, rejects files whose
+// content does not match the declared type, and checks for embedded payloads.
+// An empty or generic declared type (application/octet-stream) is accepted as-is.
+func InspectUpload(src io.Reader, declaredType string) (*InspectedUpload, error) {
+	br := bufio.NewReaderSize(src, pdfScanBufferSize)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	detected := DetectContentType(head)
+	declared := normalizeMimeType(declaredType)
+	if declared != "" && declared != "application/octet-stream" && declared != detected {
+		return nil, fmt.Errorf("file content (%s) does not match its declared type (%s)", detected, declared)
+	}
+
+	result := &InspectedUpload{MimeType: detected}
+
+	switch {
+	case detected == "image/svg+xml":
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+		sanitized, err := SanitizeSVG(data)
+		if err != nil {
+			return nil, err
+		}
+		result.Sanitized = sanitized
+
+	case strings.HasPrefix(detected, "image/"):
+		data, err := io.ReadAll(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+		if err := checkImagePayload(data, detected); err != nil {
+			return nil, err
+		}
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("file is not a valid image: %w", err)
+		}
+		if cfg.Width*cfg.Height > maxImagePixels {
+			return nil, fmt.Errorf("image dimensions %dx%d are too large", cfg.Width, cfg.Height)
+		}
+		if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("file is not a valid image: %w", err)
+		}
+		result.Metadata = ReadImageMetadata(data)
+		result.Width, result.Height = orientedSize(cfg.Width, cfg.Height, result.Metadata.Orientation)
+		result.raster = data
+
+	case detected == "application/pdf":
+		if err := checkPDFPayload(br); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// DetectContentType sniffs the content type from the first bytes of a file.
+// It extends http.DetectContentType with ISO media brands (MP4 vs QuickTime)
+// and SVG, which the standard sniffer reports as generic XML or text.
+func DetectContentType(head []byte) string {
+	if mimeType, ok := detectISOMedia(head); ok {
+		return mimeType
+	}
+
+	mimeType := normalizeMimeType(http.DetectContentType(head))
+	if strings.HasPrefix(mimeType, "text/xml") || strings.HasPrefix(mimeType, "text/plain") || mimeType == "text/html" {
+		if svgRootPattern.Match(head) {
+			return "image/svg+xml"
+		}
+	}
+
+	return mimeType
+}
+
+var svgRootPattern = regexp.MustCompile(`(?i)<svg[\s>]`)
+
+// detectISOMedia reads the ftyp box of ISO base media files (MP4, MOV, HEIC)
+func detectISOMedia(head []byte) (string, bool) {
+	if len(head) < 12 || string(head[4:8]) != "ftyp" {
+		return "", false
+	}
+	boxSize := int(binary.BigEndian.Uint32(head[0:4]))
+	if boxSize < 12 || boxSize > len(head) {
+		boxSize = len(head)
+	}
+
+	// Major brand, then compatible brands after the minor version
+	brands := []string{string(head[8:12])}
+	for i := 16; i+4 <= boxSize; i += 4 {
+		brands = append(brands, string(head[i:i+4]))
+	}
+
+	for _, brand := range brands {
+		switch brand {
+		case "qt  ":
+			return "video/quicktime", true
+		case "heic", "heix", "mif1", "msf1":
+			return "image/heic", true
+		case "avif":
+			return "image/avif", true
+		}
+	}
+	return "video/mp4", true
+}
+
+// dangerousMarkers are sequences that have no business inside a media file and
+// indicate a polyglot (a file that is also valid HTML, script or an archive)
+var dangerousMarkers = [][]byte{
+	[]byte("<script"),
+	[]byte("<html"),
+	[]byte("<!doctype html"),
+	[]byte("<?php"),
+	[]byte("<iframe"),
+	[]byte("javascript:"),
+}
+
+// zipEndOfDirectory marks the end of a zip archive; archives appended to an
+// image (GIFAR, JAR) end with it
+var zipEndOfDirectory = []byte("PK\x05\x06")
+
+// zipTrailerWindow is the largest possible end-of-directory record
+const zipTrailerWindow = 22 + 65535
+
+// checkImagePayload rejects raster images with embedded markup or archives,
+// and PNG/GIF files with data after the format's end marker
+func checkImagePayload(data []byte, mimeType string) error {
+	lower := bytes.ToLower(data)
+	for _, marker := range dangerousMarkers {
+		if bytes.Contains(lower, marker) {
+			return fmt.Errorf("image contains embedded content and was rejected")
+		}
+	}
+	if bytes.Contains(data[max(0, len(data)-zipTrailerWindow):], zipEndOfDirectory) {
+		return fmt.Errorf("image contains an embedded archive and was rejected")
+	}
+
+	// JPEG is not checked here: phone cameras routinely append vendor trailers
+	var end int
+	switch mimeType {
+	case "image/png":
+		// IEND chunk: type + CRC
+		if i := bytes.LastIndex(data, []byte("IEND")); i >= 0 {
+			end = i + 8
+		}
+	case "image/gif":
+		end = bytes.LastIndexByte(data, 0x3B) + 1
+	default:
+		return nil
+	}
+
+	if end <= 0 {
+		return fmt.Errorf("file is not a valid image: missing end marker")
+	}
+	if len(data)-end > maxTrailingBytes {
+		return fmt.Errorf("image has unexpected data after its end marker and was rejected")
+	}
+	return nil
+}
+
+// pdfActionPattern matches PDF actions that run code or launch programs
+var pdfActionPattern = regexp.MustCompile(`/(JavaScript|JS|Launch|EmbeddedFile)\b`)
+
+// checkPDFPayload scans a PDF for script or launch actions
+func checkPDFPayload(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, pdfScanBufferSize), pdfScanBufferSize)
+	scanner.Split(scanOverlapping(64))
+	for scanner.Scan() {
+		if pdfActionPattern.Match(scanner.Bytes()) {
+			return fmt.Errorf("PDF contains scripts or embedded files and was rejected")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+	return nil
+}
+
+// scanOverlapping returns a split function yielding large chunks that overlap
+// by n bytes, so markers spanning a chunk boundary are still found
+func scanOverlapping(n int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF {
+			if len(data) == 0 {
+				return 0, nil, nil
+			}
+			return len(data), data, nil
+		}
+		if len(data) < pdfScanBufferSize {
+			return 0, nil, nil // Request more data
+		}
+		return len(data) - n, data, nil
+	}
+}
+
+// svgBlockedElements are removed together with their content
+var svgBlockedElements = map[string]bool{
+	"script":        true,
+	"foreignobject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// SanitizeSVG removes scripts, event handlers, foreign content, stylesheets
+// that can fetch resources and external or javascript: references from an
+// SVG document
+func SanitizeSVG(data []byte) ([]byte, error) {
+	// RawToken keeps namespace prefixes as written (xlink:href), which
+	// encoding/xml's encoder cannot round-trip, so the output is written by hand
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	var stack []string
+	skipDepth := 0
+	sawRoot := false
+
+	for {
+		tok, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("file is not a valid SVG: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipDepth > 0 {
+				skipDepth++
+				continue
+			}
+			if svgBlockedElements[strings.ToLower(t.Name.Local)] {
+				skipDepth = 1
+				continue
+			}
+			if !sawRoot {
+				if strings.ToLower(t.Name.Local) != "svg" {
+					return nil, fmt.Errorf("file is not a valid SVG: unexpected root element %q", t.Name.Local)
+				}
+				sawRoot = true
+			}
+
+			name := qualifiedName(t.Name)
+			// Stylesheets are kept only when they cannot fetch anything
+			if strings.ToLower(t.Name.Local) == "style" {
+				css, err := readSVGStyle(decoder)
+				if err != nil {
+					return nil, err
+				}
+				if !safeSVGCSS(css) {
+					continue
+				}
+				writeSVGStartTag(&out, name, t.Attr)
+				xml.EscapeText(&out, []byte(css))
+				out.WriteString("</" + name + ">")
+				continue
+			}
+
+			stack = append(stack, name)
+			writeSVGStartTag(&out, name, t.Attr)
+
+		case xml.EndElement:
+			if skipDepth > 0 {
+				skipDepth--
+				continue
+			}
+			name := qualifiedName(t.Name)
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				return nil, fmt.Errorf("file is not a valid SVG: unexpected </%s>", name)
+			}
+			stack = stack[:len(stack)-1]
+			out.WriteString("</" + name + ">")
+
+		case xml.CharData:
+			if skipDepth > 0 {
+				continue
+			}
+			xml.EscapeText(&out, t)
+
+		case xml.Directive, xml.ProcInst, xml.Comment:
+			// Dropped: DOCTYPE/entity declarations, processing instructions
+			// (e.g. xml-stylesheet) and comments
+			continue
+		}
+	}
+
+	if !sawRoot || len(stack) > 0 {
+		return nil, fmt.Errorf("file is not a valid SVG: incomplete document")
+	}
+
+	return out.Bytes(), nil
+}
+
+func writeSVGStartTag(out *bytes.Buffer, name string, attrs []xml.Attr) {
+	out.WriteString("<" + name)
+	for _, attr := range sanitizeSVGAttrs(attrs) {
+		out.WriteString(" " + qualifiedName(attr.Name) + `="`)
+		xml.EscapeText(out, []byte(attr.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+// readSVGStyle reads a <style> element's stylesheet up to its end tag
+func readSVGStyle(decoder *xml.Decoder) (string, error) {
+	var css strings.Builder
+	for {
+		tok, err := decoder.RawToken()
+		if err != nil {
+			return "", fmt.Errorf("file is not a valid SVG: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			css.Write(t)
+		case xml.StartElement:
+			return "", fmt.Errorf("file is not a valid SVG: unexpected <%s> in <style>", qualifiedName(t.Name))
+		case xml.EndElement:
+			return css.String(), nil
+		}
+	}
+}
+
+// safeSVGCSS reports whether a stylesheet cannot fetch anything. Like style
+// attributes, any url() is refused, and CSS escapes are refused because they
+// can spell url( or @import in a way this check would miss.
+func safeSVGCSS(css string) bool {
+	value := strings.ToLower(strings.Join(strings.Fields(css), ""))
+	return !strings.Contains(value, "url(") && !strings.Contains(value, "@import") &&
+		!strings.Contains(value, "expression(") && !strings.Contains(value, `\`)
+}
+
+// svgURLPattern captures the target of each url() in a lowercased,
+// whitespace-free attribute value
+var svgURLPattern = regexp.MustCompile(`url\(['"]?([^'")]*)`)
+
+// localSVGURLs reports whether every url() in value points into the same
+// document, e.g. fill="url(#gradient)"
+func localSVGURLs(value string) bool {
+	for _, match := range svgURLPattern.FindAllStringSubmatch(value, -1) {
+		if !strings.HasPrefix(match[1], "#") {
+			return false
+		}
+	}
+	return true
+}
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func sanitizeSVGAttrs(attrs []xml.Attr) []xml.Attr {
+	clean := attrs[:0]
+	for _, attr := range attrs {
+		name := strings.ToLower(attr.Name.Local)
+		value := strings.ToLower(strings.Join(strings.Fields(attr.Value), ""))
+
+		switch {
+		case strings.HasPrefix(name, "on"):
+			continue // Event handlers
+		case name == "href" || name == "src":
+			// Only same-document fragments and embedded raster data
+			if !strings.HasPrefix(value, "#") && !strings.HasPrefix(value, "data:image/png") &&
+				!strings.HasPrefix(value, "data:image/jpeg") && !strings.HasPrefix(value, "data:image/gif") &&
+				!strings.HasPrefix(value, "data:image/webp") {
+				continue
+			}
+		case name == "style" && !safeSVGCSS(attr.Value):
+			continue
+		case strings.Contains(value, `\`):
+			continue // A CSS escape can spell url( in a presentation attribute
+		case !localSVGURLs(value):
+			continue // e.g. fill, filter, mask or marker-end pointing off the document
+		case strings.Contains(value, "javascript:"):
+			continue
+		}
+		clean = append(clean, attr)
+	}
+	return clean
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeTestPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 3))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func encodeTestGIF(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White})
+	var buf bytes.Buffer
+	require.NoError(t, gif.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func TestInspectUpload(t *testing.T) {
+	pngData := encodeTestPNG(t)
+	gifData := encodeTestGIF(t)
+	mp4Head := append([]byte{0, 0, 0, 0x18}, []byte("ftypmp42\x00\x00\x00\x00isommp42")...)
+	movHead := append([]byte{0, 0, 0, 0x14}, []byte("ftypqt  \x00\x00\x00\x00qt  ")...)
+
+	tests := []struct {
+		name         string
+		data         []byte
+		declaredType string
+		wantType     string
+		wantErr      string
+	}{
+		{
+			name:         "success: png matches declared type",
+			data:         pngData,
+			declaredType: "image/png",
+			wantType:     "image/png",
+		},
+		{
+			name:         "success: generic declared type uses detected type",
+			data:         pngData,
+			declaredType: "application/octet-stream",
+			wantType:     "image/png",
+		},
+		{
+			name:         "success: mp4 detected from ftyp brand",
+			data:         mp4Head,
+			declaredType: "video/mp4",
+			wantType:     "video/mp4",
+		},
+		{
+			name:         "success: quicktime detected from ftyp brand",
+			data:         movHead,
+			declaredType: "video/quicktime",
+			wantType:     "video/quicktime",
+		},
+		{
+			name:         "failure: png labelled as jpeg",
+			data:         pngData,
+			declaredType: "image/jpeg",
+			wantErr:      "does not match",
+		},
+		{
+			name:         "failure: html labelled as image",
+			data:         []byte("<!DOCTYPE html><html><body>hi</body></html>"),
+			declaredType: "image/png",
+			wantErr:      "does not match",
+		},
+		{
+			name:         "failure: truncated png does not decode",
+			data:         pngData[:len(pngData)/2],
+			declaredType: "image/png",
+			wantErr:      "not a valid image",
+		},
+		{
+			name:         "security: gif with appended script",
+			data:         append(append([]byte{}, gifData...), []byte("<script>alert(1)</script>")...),
+			declaredType: "image/gif",
+			wantErr:      "embedded content",
+		},
+		{
+			name:         "security: png with appended zip",
+			data:         append(append([]byte{}, pngData...), []byte("PK\x05\x06\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")...),
+			declaredType: "image/png",
+			wantErr:      "embedded archive",
+		},
+		{
+			name:         "security: png with trailing data",
+			data:         append(append([]byte{}, pngData...), bytes.Repeat([]byte{0xAB}, 64)...),
+			declaredType: "image/png",
+			wantErr:      "after its end marker",
+		},
+		{
+			name:         "security: pdf with javascript action",
+			data:         []byte("%PDF-1.7\n1 0 obj << /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >> endobj\n%%EOF"),
+			declaredType: "application/pdf",
+			wantErr:      "scripts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := InspectUpload(bytes.NewReader(tt.data), tt.declaredType)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, got.MimeType)
+		})
+	}
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	tests := []struct {
+		name       string
+		svg        string
+		contains   []string
+		notContain []string
+		wantErr    bool
+	}{
+		{
+			name:     "success: safe svg is preserved",
+			svg:      `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><circle cx="5" cy="5" r="4" fill="red"/></svg>`,
+			contains: []string{`<circle cx="5" cy="5" r="4" fill="red"></circle>`, `viewBox="0 0 10 10"`},
+		},
+		{
+			name:       "security: script elements are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><rect width="1" height="1"/></svg>`,
+			contains:   []string{"<rect"},
+			notContain: []string{"script", "alert"},
+		},
+		{
+			name:       "security: event handlers and javascript links are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" onload="alert(1)"><a xlink:href="javascript:alert(1)"><text>x</text></a><use href="#icon"/></svg>`,
+			contains:   []string{`<use href="#icon">`, "<text>x</text>"},
+			notContain: []string{"onload", "javascript"},
+		},
+		{
+			name:       "security: foreignObject and external references are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><foreignObject><iframe src="https://evil.example"/></foreignObject><image href="https://evil.example/x.png"/></svg>`,
+			notContain: []string{"foreignObject", "iframe", "evil.example"},
+		},
+		{
+			name:     "success: plain stylesheets are kept",
+			svg:      `<svg xmlns="http://www.w3.org/2000/svg"><style>.a { fill: #f00; }</style><rect class="a"/></svg>`,
+			contains: []string{"<style>.a { fill: #f00; }</style>", `<rect class="a">`},
+		},
+		{
+			name:       "security: stylesheets that fetch resources are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><style>@import "https://evil.example/a.css";</style><style><![CDATA[rect { fill: URL( https://evil.example/x ) }]]></style><rect/></svg>`,
+			contains:   []string{"<rect>"},
+			notContain: []string{"<style", "evil.example"},
+		},
+		{
+			name:       "security: css escapes in stylesheets are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><style>rect { background: \75 rl(https://evil.example/x) }</style><rect/></svg>`,
+			notContain: []string{"<style", "evil.example"},
+		},
+		{
+			name:       "security: presentation attributes pointing off the document are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><rect fill="URL( 'https://evil.example/f' )" filter="url(https://evil.example/x)" marker-end="url(#arrow)" mask="url(//evil.example/m)"/></svg>`,
+			contains:   []string{`marker-end="url(#arrow)"`},
+			notContain: []string{"fill=", "filter=", "mask=", "evil.example"},
+		},
+		{
+			name:       "security: css escapes in style attributes are removed",
+			svg:        `<svg xmlns="http://www.w3.org/2000/svg"><rect style="fill: u\72l(https://evil.example/x)" clip-path="u\72l(https://evil.example/c)" width="1"/></svg>`,
+			contains:   []string{`<rect width="1">`},
+			notContain: []string{"style=", "clip-path=", "evil.example"},
+		},
+		{
+			name:    "failure: non-svg root is rejected",
+			svg:     `<html><svg></svg></html>`,
+			wantErr: true,
+		},
+		{
+			name:    "failure: malformed xml is rejected",
+			svg:     `<svg><rect></svg>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := SanitizeSVG([]byte(tt.svg))
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, string(got), want)
+			}
+			for _, unwanted := range tt.notContain {
+				assert.NotContains(t, strings.ToLower(string(got)), strings.ToLower(unwanted))
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// internal/services/media_visibility.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// privateURLExpiry is how long URLs rendered into pages for private media
+	// stay valid; pages are re-rendered, so it only needs to outlast a visit
+	privateURLExpiry = time.Hour
+	// MaxShareExpiry is the longest a share link can be valid for, the limit
+	// S3 places on presigned URLs
+	MaxShareExpiry = s3MaxPresignExpiry
+)
+
+// ErrMediaProcessing is returned when media cannot be changed while
+// background processing may still write renditions for it
+var ErrMediaProcessing = errors.New("media is still being processed")
+
+// SetMediaVisibility makes media private or public. Its objects are copied
+// to their new keys before the row is updated and the old copies removed
+// afterwards, so the media stays viewable throughout.
+func (s *MediaService) SetMediaVisibility(ctx context.Context, id pgtype.UUID, private bool) (*generated.Media, error) {
+	media, err := s.GetMediaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if media.IsPrivate == private {
+		return media, nil
+	}
+	// Renditions are written next to the original; moving it now would strand them
+	if media.ProcessingStatus == MediaStatusPending || media.ProcessingStatus == MediaStatusProcessing {
+		return nil, ErrMediaProcessing
+	}
+
+	keys, err := s.mediaObjectKeys(ctx, media)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make([]string, 0, len(keys))
+	discard := func() {
+		for _, key := range copied {
+			s.storage.Delete(ctx, key) // Best effort; the row still points at the old keys
+		}
+	}
+	for _, key := range keys {
+		dst := visibilityKey(key, private)
+		if err := s.storage.Copy(ctx, key, dst); err != nil {
+			discard()
+			return nil, fmt.Errorf("failed to copy %s: %w", key, err)
+		}
+		copied = append(copied, dst)
+	}
+
+	updated, err := s.queries.UpdateMediaVisibility(ctx, generated.UpdateMediaVisibilityParams{
+		IsPrivate:    private,
+		OriginalKey:  visibilityKeyText(media.OriginalKey, private),
+		LargeKey:     visibilityKeyText(media.LargeKey, private),
+		MediumKey:    visibilityKeyText(media.MediumKey, private),
+		ThumbnailKey: visibilityKeyText(media.ThumbnailKey, private),
+		WebKey:       visibilityKeyText(media.WebKey, private),
+		HlsKey:       visibilityKeyText(media.HlsKey, private),
+		HeroKey:      visibilityKeyText(media.HeroKey, private),
+		CardKey:      visibilityKeyText(media.CardKey, private),
+		OgKey:        visibilityKeyText(media.OgKey, private),
+		ID:           id,
+	})
+	if err != nil {
+		discard()
+		return nil, fmt.Errorf("failed to update media visibility: %w", err)
+	}
+
+	// Public copies left behind would defeat making media private, so failing
+	// to remove them is reported rather than ignored
+	var errs []error
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return &updated, fmt.Errorf("visibility changed but previous files could not be removed: %w", err)
+	}
+	return &updated, nil
+}
+
+// ShareMediaURL returns a link to the original file that works for expires,
+// whether or not the media is private
+func (s *MediaService) ShareMediaURL(media *generated.Media, expires time.Duration) (string, error) {
+	if !media.OriginalKey.Valid {
+		return "", fmt.Errorf("media has no stored file")
+	}
+	if expires <= 0 || expires > MaxShareExpiry {
+		return "", fmt.Errorf("share links must expire within %s", MaxShareExpiry)
+	}
+	return s.storage.GetSignedURL(media.OriginalKey.String, expires)
+}
+
+// mediaURL returns the URL for one of media's keys: a permanent public URL,
+// or a short-lived signed one for private media
+func (s *MediaService) mediaURL(media *generated.Media, key string) string {
+	if !media.IsPrivate {
+		return s.storage.GetURL(key)
+	}
+	url, err := s.storage.GetSignedURL(key, privateURLExpiry)
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// mediaObjectKeys lists every stored object belonging to media, including
+// the HLS playlists and segments that share the playlist's directory and
+// the images derived on request
+func (s *MediaService) mediaObjectKeys(ctx context.Context, media *generated.Media) ([]string, error) {
+	keys := storedKeys(media.OriginalKey, media.LargeKey, media.MediumKey, media.ThumbnailKey, media.WebKey,
+		media.HeroKey, media.CardKey, media.OgKey)
+
+	if media.HlsKey.Valid && media.HlsKey.String != "" {
+		objects, err := s.storage.List(ctx, path.Dir(media.HlsKey.String)+"/")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list HLS files: %w", err)
+		}
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+	}
+
+	if media.OriginalKey.Valid && media.OriginalKey.String != "" {
+		objects, err := s.storage.List(ctx, derivedImagePrefix(media.OriginalKey.String))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list derived images: %w", err)
+		}
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+	}
+	return keys, nil
+}
+
+// visibilityKey moves a key in or out of the private prefix
+func visibilityKey(key string, private bool) string {
+	if private {
+		return PrivateKeyPrefix + strings.TrimPrefix(key, PrivateKeyPrefix)
+	}
+	return strings.TrimPrefix(key, PrivateKeyPrefix)
+}
+
+func visibilityKeyText(key pgtype.Text, private bool) pgtype.Text {
+	if !key.Valid || key.String == "" {
+		return key
+	}
+	return pgtype.Text{String: visibilityKey(key.String, private), Valid: true}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVisibilityKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		private bool
+		want    string
+	}{
+		{name: "success: public key made private", key: "media/2024/01/02/a.jpg", private: true, want: "private/media/2024/01/02/a.jpg"},
+		{name: "success: private key made public", key: "private/media/2024/01/02/a.jpg", private: false, want: "media/2024/01/02/a.jpg"},
+		{name: "edge: already private", key: "private/media/a.jpg", private: true, want: "private/media/a.jpg"},
+		{name: "edge: already public", key: "media/a.jpg", private: false, want: "media/a.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := visibilityKey(tt.key, tt.private)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.private, IsPrivateKey(got))
+		})
+	}
+}
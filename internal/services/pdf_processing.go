@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// pdfPreviewSize is the longest side of a PDF's first-page preview in pixels
+const pdfPreviewSize = 1024
+
+// PDFMetadata holds the values we read from pdfinfo
+type PDFMetadata struct {
+	Pages int
+	Title string // Empty when the document properties have none
+}
+
+// IsPDF reports whether media is a PDF document
+func IsPDF(mimeType string) bool {
+	return mimeType == "application/pdf"
+}
+
+// ProbePDF runs pdfinfo against a local PDF file
+func ProbePDF(ctx context.Context, pdfPath string) (*PDFMetadata, error) {
+	cmd := exec.CommandContext(ctx, "pdfinfo", "-enc", "UTF-8", pdfPath)
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("pdfinfo failed: %w", err)
+	}
+
+	return parsePDFInfo(output)
+}
+
+// parsePDFInfo extracts metadata from pdfinfo's "Key: value" output
+func parsePDFInfo(data []byte) (*PDFMetadata, error) {
+	meta := &PDFMetadata{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Pages":
+			pages, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid page count %q", value)
+			}
+			meta.Pages = pages
+		case "Title":
+			meta.Title = value
+		}
+	}
+
+	if meta.Pages < 1 {
+		return nil, fmt.Errorf("no pages found")
+	}
+
+	return meta, nil
+}
+
+// ProcessPDFPreview renders the first page of a PDF to a JPEG and uploads it,
+// returning the storage key
+func (s *MediaService) ProcessPDFPreview(ctx context.Context, pdfPath string, originalKey string) (string, error) {
+	// pdftoppm adds the extension to the output prefix
+	previewPrefix := strings.TrimSuffix(pdfPath, filepath.Ext(pdfPath)) + "_preview"
+	previewPath := previewPrefix + ".jpg"
+	defer os.Remove(previewPath)
+
+	cmd := exec.CommandContext(ctx, "pdftoppm",
+		"-jpeg",
+		"-jpegopt", "quality=85",
+		"-f", "1",
+		"-l", "1",
+		"-singlefile",
+		"-scale-to", strconv.Itoa(pdfPreviewSize),
+		pdfPath,
+		previewPrefix,
+	)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("pdftoppm failed: %w\nOutput: %s", err, string(output))
+	}
+
+	previewKey := strings.TrimSuffix(originalKey, filepath.Ext(originalKey)) + "_thumb.jpg"
+
+	if err := s.uploadLocalFile(ctx, previewPath, previewKey, "image/jpeg"); err != nil {
+		return "", err
+	}
+
+	return previewKey, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePDFInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *PDFMetadata
+		wantErr bool
+	}{
+		{
+			name: "success: pages and title",
+			output: "Title:           Brand Refresh: Case Study\n" +
+				"Author:          Studio\n" +
+				"Creator:         Pages\n" +
+				"Pages:           12\n" +
+				"Page size:       595.276 x 841.89 pts (A4)\n",
+			want: &PDFMetadata{Pages: 12, Title: "Brand Refresh: Case Study"},
+		},
+		{
+			name:   "success: no title",
+			output: "Producer:        Ghostscript\nPages:           1\nEncrypted:       no\n",
+			want:   &PDFMetadata{Pages: 1},
+		},
+		{
+			name:    "failure: no page count",
+			output:  "Title:           Draft\n",
+			wantErr: true,
+		},
+		{
+			name:    "failure: invalid page count",
+			output:  "Pages:           many\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := parsePDFInfo([]byte(tt.output))
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/iankencruz/threefive/database/generated"
+	"github.com/iankencruz/threefive/pkg/markdown"
 	"github.com/iankencruz/threefive/pkg/validation"
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -32,7 +34,12 @@ type ProjectResponse struct {
 	Project       generated.Project
 	FeaturedImage *MediaResponse
 	GalleryMedia  []MediaResponse
+	Documents     []MediaResponse // Downloadable case-study PDFs
 	Tags          []TagResponse
+	BodyHTML      string // Sanitized HTML rendered from the markdown body (see RenderBody)
+	WordCount     int32
+	ReadingTime   int32  // Minutes; 0 when the project has no body
+	Description   string // The author's description, or one generated from the body
 }
 
 // TagResponse is the view model for a tag
@@ -46,6 +53,7 @@ type CreateProjectRequest struct {
 	Title           string
 	Slug            string
 	Description     string
+	Body            string // Markdown
 	ProjectDate     *time.Time
 	ClientName      string
 	ProjectYear     int32
@@ -86,6 +94,13 @@ func (r *CreateProjectRequest) Validate() (validation.FieldErrors, error) {
 				validation.MaxLength(1000, "Description must be at most 1000 characters"),
 			},
 		},
+		{
+			Name:  "body",
+			Value: r.Body,
+			Rules: []validation.ValidationRule{
+				validation.MaxLength(100000, "Body must be at most 100000 characters"),
+			},
+		},
 		{
 			Name:  "project_url",
 			Value: r.ProjectURL,
@@ -156,6 +171,8 @@ func (s *ProjectService) CreateProject(ctx context.Context, req *CreateProjectRe
 		}
 	}
 
+	metrics := computeContentMetrics(req.Body)
+
 	// Create project
 	_, err = s.queries.CreateProject(ctx, generated.CreateProjectParams{
 		ID: pgtype.UUID{
@@ -165,6 +182,9 @@ func (s *ProjectService) CreateProject(ctx context.Context, req *CreateProjectRe
 		Title:           req.Title,
 		Slug:            req.Slug,
 		Description:     pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Body:            pgtype.Text{String: req.Body, Valid: req.Body != ""},
+		WordCount:       metrics.WordCount,
+		ReadingTime:     metrics.ReadingTime,
 		ProjectDate:     projectDate,
 		Status:          pgtype.Text{String: req.Status, Valid: req.Status != ""},
 		ClientName:      pgtype.Text{String: req.ClientName, Valid: req.ClientName != ""},
@@ -201,18 +221,20 @@ func (s *ProjectService) CreateProject(ctx context.Context, req *CreateProjectRe
 
 // UpdateProjectRequest represents the data needed to update a project
 type UpdateProjectRequest struct {
-	Title           string
-	Slug            string
-	Description     string
-	ClientName      string
-	ProjectYear     string
-	ProjectDate     string
-	ProjectURL      string
-	Status          string
-	ProjectStatus   string
-	Tags            string
-	FeaturedImageID string
-	GalleryMediaIDs string
+	Title            string
+	Slug             string
+	Description      string
+	Body             string
+	ClientName       string
+	ProjectYear      string
+	ProjectDate      string
+	ProjectURL       string
+	Status           string
+	ProjectStatus    string
+	Tags             string
+	FeaturedImageID  string
+	GalleryMediaIDs  string
+	DocumentMediaIDs string
 }
 
 // Add Validate method to UpdateProjectRequest
@@ -243,6 +265,13 @@ func (r *UpdateProjectRequest) Validate() (validation.FieldErrors, error) {
 				validation.MaxLength(1000, "Description must be at most 1000 characters"),
 			},
 		},
+		{
+			Name:  "body",
+			Value: r.Body,
+			Rules: []validation.ValidationRule{
+				validation.MaxLength(100000, "Body must be at most 100000 characters"),
+			},
+		},
 		{
 			Name:  "client_name",
 			Value: r.ClientName,
@@ -309,6 +338,20 @@ func (r *UpdateProjectRequest) Validate() (validation.FieldErrors, error) {
 		}
 	}
 
+	// Validate document media IDs format if provided
+	if r.DocumentMediaIDs != "" {
+		ids := strings.Split(r.DocumentMediaIDs, ",")
+		for _, idStr := range ids {
+			idStr = strings.TrimSpace(idStr)
+			if idStr != "" {
+				if _, err := uuid.Parse(idStr); err != nil {
+					errors := validation.FieldErrors{"document_media_ids": "Invalid document media ID format"}
+					return errors, fmt.Errorf("validation failed")
+				}
+			}
+		}
+	}
+
 	errors := validation.ValidateFields(fields)
 	if errors.HasErrors() {
 		return errors, fmt.Errorf("validation failed")
@@ -317,6 +360,10 @@ func (r *UpdateProjectRequest) Validate() (validation.FieldErrors, error) {
 	return nil, nil
 }
 
+// ErrInvalidDocument is returned when a case-study document is not a public
+// PDF in the media library
+var ErrInvalidDocument = errors.New("case-study documents must be public PDFs in the media library")
+
 // UpdateProjectBySlug updates a project by slug
 func (s *ProjectService) UpdateProjectBySlug(ctx context.Context, slug string, req *UpdateProjectRequest) (*ProjectResponse, error) {
 	// Get existing project
@@ -338,6 +385,12 @@ func (s *ProjectService) UpdateProjectBySlug(ctx context.Context, slug string, r
 		}
 	}
 
+	// Documents are checked before anything is written
+	documentIDs, err := s.documentMediaIDs(ctx, req.DocumentMediaIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build update params
 	params := generated.UpdateProjectParams{
 		ID: existing.ID,
@@ -349,9 +402,15 @@ func (s *ProjectService) UpdateProjectBySlug(ctx context.Context, slug string, r
 	if req.Slug != "" {
 		params.Slug = pgtype.Text{String: req.Slug, Valid: true}
 	}
-	if req.Description != "" {
-		params.Description = pgtype.Text{String: req.Description, Valid: true}
-	}
+	// Body is always written so it can be cleared; metrics follow it
+	metrics := computeContentMetrics(req.Body)
+	params.Body = pgtype.Text{String: req.Body, Valid: true}
+	params.WordCount = pgtype.Int4{Int32: metrics.WordCount, Valid: true}
+	params.ReadingTime = metrics.ReadingTime
+
+	// Description is always written; a blank one is stored as NULL and
+	// generated from the body when read
+	params.Description = pgtype.Text{String: req.Description, Valid: req.Description != ""}
 	if req.ClientName != "" {
 		params.ClientName = pgtype.Text{String: req.ClientName, Valid: true}
 	}
@@ -400,6 +459,11 @@ func (s *ProjectService) UpdateProjectBySlug(ctx context.Context, slug string, r
 		return nil, fmt.Errorf("failed to sync gallery: %w", err)
 	}
 
+	// Sync case-study documents
+	if err := s.syncDocumentMedia(ctx, updated.ID, documentIDs); err != nil {
+		return nil, fmt.Errorf("failed to sync documents: %w", err)
+	}
+
 	return s.GetProjectBySlug(ctx, updated.Slug)
 }
 
@@ -497,11 +561,29 @@ func (s *ProjectService) CountProjects(ctx context.Context) (int64, error) {
 	return s.queries.CountProjects(ctx)
 }
 
+// RenderBody renders the project's markdown body into BodyHTML
+func (s *ProjectService) RenderBody(ctx context.Context, project *ProjectResponse) error {
+	if !project.Project.Body.Valid {
+		return nil
+	}
+
+	html, err := markdown.Render(project.Project.Body.String, s.mediaService.MarkdownResolver(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to render project body: %w", err)
+	}
+
+	project.BodyHTML = html
+	return nil
+}
+
 // Helper functions
 
 func (s *ProjectService) buildProjectResponse(ctx context.Context, project *generated.Project) (*ProjectResponse, error) {
 	response := &ProjectResponse{
-		Project: *project,
+		Project:     *project,
+		WordCount:   project.WordCount,
+		ReadingTime: project.ReadingTime.Int32,
+		Description: summaryOrExcerpt(project.Description, project.Body),
 	}
 
 	// Load featured image
@@ -519,6 +601,12 @@ func (s *ProjectService) buildProjectResponse(ctx context.Context, project *gene
 		response.GalleryMedia = s.mediaService.ToMediaResponses(galleryMedia)
 	}
 
+	// Load case-study documents
+	documents, err := s.queries.GetProjectDocuments(ctx, project.ID)
+	if err == nil && len(documents) > 0 {
+		response.Documents = s.mediaService.ToMediaResponses(documents)
+	}
+
 	// Load tags
 	tags, err := s.queries.GetProjectTags(ctx, project.ID)
 	if err == nil && len(tags) > 0 {
@@ -673,6 +761,71 @@ func (s *ProjectService) syncGalleryMedia(ctx context.Context, projectID pgtype.
 	return nil
 }
 
+// documentMediaIDs parses comma-separated case-study document IDs, checking
+// each is a public PDF that is not in the trash
+func (s *ProjectService) documentMediaIDs(ctx context.Context, documentMediaIDs string) ([]pgtype.UUID, error) {
+	var ids []pgtype.UUID
+	for _, idStr := range strings.Split(documentMediaIDs, ",") {
+		idStr = strings.TrimSpace(idStr)
+		if idStr == "" {
+			continue
+		}
+		mediaUUID, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid media ID %q", ErrInvalidDocument, idStr)
+		}
+		ids = append(ids, pgtype.UUID{Bytes: mediaUUID, Valid: true})
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	media, err := s.queries.GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents: %w", err)
+	}
+	found := make(map[pgtype.UUID]generated.Media, len(media))
+	for _, m := range media {
+		found[m.ID] = m
+	}
+	for _, id := range ids {
+		m, ok := found[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%w: %s is not in the library", ErrInvalidDocument, id.String())
+		case !IsPDF(m.MimeType):
+			return nil, fmt.Errorf("%w: %s is not a PDF", ErrInvalidDocument, m.OriginalFilename)
+		case m.IsPrivate:
+			return nil, fmt.Errorf("%w: %s is private", ErrInvalidDocument, m.OriginalFilename)
+		}
+	}
+	return ids, nil
+}
+
+// syncDocumentMedia replaces all case-study documents for a project
+func (s *ProjectService) syncDocumentMedia(ctx context.Context, projectID pgtype.UUID, documentIDs []pgtype.UUID) error {
+	// Clear existing document relations
+	if err := s.queries.DeleteDocumentMediaForEntity(ctx, generated.DeleteDocumentMediaForEntityParams{
+		EntityType: "project",
+		EntityID:   projectID,
+	}); err != nil {
+		return fmt.Errorf("failed to clear documents: %w", err)
+	}
+
+	for i, mediaID := range documentIDs {
+		if err := s.queries.AddDocumentMedia(ctx, generated.AddDocumentMediaParams{
+			MediaID:    mediaID,
+			EntityType: "project",
+			EntityID:   projectID,
+			SortOrder:  pgtype.Int4{Int32: int32(i), Valid: true},
+		}); err != nil {
+			return fmt.Errorf("failed to add document %s: %w", mediaID.String(), err)
+		}
+	}
+
+	return nil
+}
+
 // syncProjectTags replaces all tags for a project
 func (s *ProjectService) syncProjectTags(ctx context.Context, projectID pgtype.UUID, tagsCSV string) error {
 	// Clear existing tags
@@ -0,0 +1,107 @@
+// internal/services/projects_test.go
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProjectService_UpdateProjectDocuments tests which media can be attached
+// as case-study documents
+func TestProjectService_UpdateProjectDocuments(t *testing.T) {
+	pool, queries, _, cleanup := setupTestContainer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	mediaService := NewMediaService(pool, queries, nil, MediaConfig{})
+	projectService := NewProjectService(queries, mediaService)
+	author := createTestUser(t, ctx, queries, "author@example.com", "AuthorPass123!", "Project", "Author")
+
+	_, err := projectService.CreateProject(ctx, &CreateProjectRequest{
+		Title:    "Harbour House",
+		Slug:     "harbour-house",
+		Status:   "draft",
+		AuthorID: author.ID.Bytes,
+	})
+	require.NoError(t, err, "failed to create project")
+
+	brief := createTestMedia(t, ctx, queries, "brief.pdf", "application/pdf")
+	photo := createTestMedia(t, ctx, queries, "site.jpg", "image/jpeg")
+	trashed := createTestMedia(t, ctx, queries, "old-brief.pdf", "application/pdf")
+	require.NoError(t, mediaService.DeleteMedia(ctx, trashed.ID, false))
+	private := createTestMedia(t, ctx, queries, "contract.pdf", "application/pdf")
+	_, err = queries.UpdateMediaVisibility(ctx, generated.UpdateMediaVisibilityParams{
+		IsPrivate:   true,
+		OriginalKey: pgtype.Text{String: PrivateKeyPrefix + private.OriginalKey.String, Valid: true},
+		ID:          private.ID,
+	})
+	require.NoError(t, err, "failed to make media private")
+
+	tests := []struct {
+		name      string
+		documents []generated.Media
+		rawIDs    string
+		wantErr   string
+	}{
+		{
+			name:      "success: public PDF is attached",
+			documents: []generated.Media{brief},
+		},
+		{
+			name:      "failure: image is rejected",
+			documents: []generated.Media{brief, photo},
+			wantErr:   "site.jpg is not a PDF",
+		},
+		{
+			name:      "failure: private PDF is rejected",
+			documents: []generated.Media{private},
+			wantErr:   "contract.pdf is private",
+		},
+		{
+			name:      "failure: trashed PDF is rejected",
+			documents: []generated.Media{trashed},
+			wantErr:   "is not in the library",
+		},
+		{
+			name:    "failure: invalid ID is rejected",
+			rawIDs:  "not-a-uuid",
+			wantErr: "invalid media ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := tt.rawIDs
+			if ids == "" {
+				var parts []string
+				for _, m := range tt.documents {
+					parts = append(parts, m.ID.String())
+				}
+				ids = strings.Join(parts, ",")
+			}
+
+			// Act
+			project, err := projectService.UpdateProjectBySlug(ctx, "harbour-house", &UpdateProjectRequest{
+				Title:            "Harbour House",
+				Status:           "draft",
+				DocumentMediaIDs: ids,
+			})
+
+			// Assert
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ErrInvalidDocument)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, project.Documents, len(tt.documents))
+			assert.Equal(t, tt.documents[0].ID, project.Documents[0].ID)
+		})
+	}
+}
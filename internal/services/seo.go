@@ -189,7 +189,7 @@ func (s *SEOService) GetSEOResponse(ctx context.Context, entityType string, enti
 	if seo != nil && seo.OgImageID.Valid {
 		if media, err := mediaService.GetMediaByID(ctx, seo.OgImageID); err == nil {
 			mediaResp := mediaService.ToMediaResponse(media)
-			resp.OGImageURL = mediaResp.ThumbnailURL
+			resp.OGImageURL = mediaResp.OGURL
 		}
 	}
 
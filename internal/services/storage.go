@@ -3,79 +3,308 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"io/fs"
+	"mime"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 )
 
-// StorageProvider defines the interface for file storage
+// ErrObjectNotFound is returned when a storage key does not exist
+var ErrObjectNotFound = errors.New("storage object not found")
+
+// PrivateKeyPrefix is prepended to the keys of private media. Objects under it
+// are never publicly readable and are only reachable through signed URLs.
+const PrivateKeyPrefix = "private/"
+
+// IsPrivateKey reports whether key belongs to private media
+func IsPrivateKey(key string) bool {
+	return strings.HasPrefix(key, PrivateKeyPrefix)
+}
+
+// StorageProvider is the single abstraction over stored files. Uploads,
+// derived renditions, imports and migrations all go through it.
 type StorageProvider interface {
-	Upload(ctx context.Context, file *multipart.FileHeader, key string) (string, error)
+	// Upload stores r under key, replacing any existing object
+	Upload(ctx context.Context, key string, r io.Reader, opts ObjectOptions) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Copy duplicates an object, including its content type and metadata
+	Copy(ctx context.Context, srcKey, dstKey string) error
+	// List returns every object whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
 	Delete(ctx context.Context, key string) error
 	GetURL(key string) string
+	// GetSignedURL returns a URL for key that stops working after expires
+	GetSignedURL(key string, expires time.Duration) (string, error)
+	// Type is the value stored in media.storage_type ("local" or "s3")
+	Type() string
+}
+
+// ObjectOptions describes content being uploaded
+type ObjectOptions struct {
+	ContentType string
+	Size        int64             // Bytes; 0 when unknown
+	Metadata    map[string]string // Small user-defined values, e.g. checksums
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+	Metadata     map[string]string
 }
 
 // LocalStorage implements local file storage
 type LocalStorage struct {
-	uploadDir string
-	baseURL   string
+	uploadDir  string
+	baseURL    string
+	signingKey []byte // HMAC key for signed URLs
+}
+
+// localMetaDir holds sidecar files with each object's content type and
+// metadata, mirroring the key layout
+const localMetaDir = ".meta"
+
+// localMeta is the sidecar stored next to a local object
+type localMeta struct {
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
-func NewLocalStorage(uploadDir, baseURL string) *LocalStorage {
+// NewLocalStorage serves files under baseURL. signingKey signs URLs for
+// private media; without one, private media cannot be shared.
+func NewLocalStorage(uploadDir, baseURL string, signingKey []byte) *LocalStorage {
 	// Ensure upload directory exists
 	os.MkdirAll(uploadDir, 0o755)
 
 	return &LocalStorage{
-		uploadDir: uploadDir,
-		baseURL:   baseURL,
+		uploadDir:  uploadDir,
+		baseURL:    baseURL,
+		signingKey: signingKey,
+	}
+}
+
+func (s *LocalStorage) Type() string {
+	return "local"
+}
+
+// path resolves a key inside the upload directory, rejecting keys that would
+// escape it or collide with the metadata sidecars
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)[1:]
+	if key == "" || clean != key || clean == localMetaDir || strings.HasPrefix(clean, localMetaDir+"/") {
+		return "", fmt.Errorf("invalid storage key %q", key)
 	}
+	return filepath.Join(s.uploadDir, filepath.FromSlash(clean)), nil
+}
+
+func (s *LocalStorage) metaPath(key string) string {
+	return filepath.Join(s.uploadDir, localMetaDir, filepath.FromSlash(key)+".json")
 }
 
-func (s *LocalStorage) Upload(ctx context.Context, file *multipart.FileHeader, key string) (string, error) {
-	uploadPath := filepath.Join(s.uploadDir, key)
+// Upload writes to a temp file and renames it into place, so readers never
+// see a partial file
+func (s *LocalStorage) Upload(ctx context.Context, key string, r io.Reader, opts ObjectOptions) error {
+	uploadPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
 
-	// Ensure directory exists
 	dir := filepath.Dir(uploadPath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
+		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Open uploaded file
-	src, err := file.Open()
+	tmp, err := os.CreateTemp(dir, ".upload_*")
 	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+		return fmt.Errorf("failed to create destination file: %w", err)
 	}
-	defer src.Close()
+	defer os.Remove(tmp.Name()) // No-op once renamed
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+
+	if err := s.writeMeta(key, localMeta{ContentType: opts.ContentType, Metadata: opts.Metadata}); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), uploadPath); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStorage) writeMeta(key string, meta localMeta) error {
+	metaPath := s.metaPath(key)
+	if meta.ContentType == "" && len(meta.Metadata) == 0 {
+		if err := os.Remove(metaPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove metadata: %w", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(metaPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create metadata directory: %w", err)
+	}
+	if err := os.WriteFile(metaPath, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// readMeta returns the sidecar for key, falling back to a content type
+// guessed from the extension
+func (s *LocalStorage) readMeta(key string) localMeta {
+	var meta localMeta
+	if data, err := os.ReadFile(s.metaPath(key)); err == nil {
+		json.Unmarshal(data, &meta)
+	}
+	if meta.ContentType == "" {
+		meta.ContentType = mime.TypeByExtension(path.Ext(key))
+	}
+	return meta
+}
 
-	// Create destination file
-	dst, err := os.Create(uploadPath)
+func (s *LocalStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := s.path(key)
 	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %w", err)
+		return nil, err
 	}
-	defer dst.Close()
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to open %s: %w", key, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
 
-	// Copy file
-	if _, err = io.Copy(dst, src); err != nil {
-		return "", fmt.Errorf("failed to save file: %w", err)
+func (s *LocalStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to stat %s: %w", key, ErrObjectNotFound)
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+	if fi.IsDir() {
+		return nil, fmt.Errorf("failed to stat %s: %w", key, ErrObjectNotFound)
 	}
+	return s.objectInfo(key, fi), nil
+}
 
-	return key, nil
+func (s *LocalStorage) objectInfo(key string, fi fs.FileInfo) *ObjectInfo {
+	meta := s.readMeta(key)
+	return &ObjectInfo{
+		Key:          key,
+		Size:         fi.Size(),
+		ContentType:  meta.ContentType,
+		LastModified: fi.ModTime(),
+		Metadata:     meta.Metadata,
+	}
+}
+
+func (s *LocalStorage) Copy(ctx context.Context, srcKey, dstKey string) error {
+	src, err := s.Open(ctx, srcKey)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	meta := s.readMeta(srcKey)
+	return s.Upload(ctx, dstKey, src, ObjectOptions{ContentType: meta.ContentType, Metadata: meta.Metadata})
+}
+
+func (s *LocalStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.uploadDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(s.uploadDir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if key == localMetaDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Skip in-flight uploads and keys outside the prefix
+		if strings.HasPrefix(d.Name(), ".upload_") || !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, *s.objectInfo(key, fi))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	return objects, nil
 }
 
 func (s *LocalStorage) Delete(ctx context.Context, key string) error {
-	filePath := filepath.Join(s.uploadDir, key)
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
 	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
+	if err := os.Remove(s.metaPath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete metadata: %w", err)
+	}
 	return nil
 }
 
@@ -83,6 +312,50 @@ func (s *LocalStorage) GetURL(key string) string {
 	return fmt.Sprintf("%s/%s", s.baseURL, key)
 }
 
+// GetSignedURL appends an expiry and an HMAC of the key and expiry, which
+// the file handler checks with VerifySignature
+func (s *LocalStorage) GetSignedURL(key string, expires time.Duration) (string, error) {
+	if len(s.signingKey) == 0 {
+		return "", fmt.Errorf("no signing key configured for local storage")
+	}
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+	return fmt.Sprintf("%s/%s?expires=%s&signature=%s", s.baseURL, key, expiresAt, s.sign(key, expiresAt)), nil
+}
+
+// VerifySignature checks the expires and signature query values of a signed
+// URL for key, returning when the URL expires
+func (s *LocalStorage) VerifySignature(key, expires, signature string) (time.Time, bool) {
+	if len(s.signingKey) == 0 {
+		return time.Time{}, false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	expiresAt := time.Unix(unix, 0)
+	if !time.Now().Before(expiresAt) {
+		return time.Time{}, false
+	}
+
+	given, err := hex.DecodeString(signature)
+	if err != nil {
+		return time.Time{}, false
+	}
+	want, _ := hex.DecodeString(s.sign(key, expires))
+	return expiresAt, hmac.Equal(given, want)
+}
+
+func (s *LocalStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// BaseURL is the URL prefix files are served under (e.g. /uploads)
+func (s *LocalStorage) BaseURL() string {
+	return s.baseURL
+}
+
 // S3Storage implements S3-compatible storage (AWS S3, Vultr Object Storage, etc.)
 type S3Storage struct {
 	client   *s3.Client
@@ -168,33 +441,103 @@ func NewS3Storage(ctx context.Context, cfg S3Config) (*S3Storage, error) {
 	}, nil
 }
 
-func (s *S3Storage) Upload(ctx context.Context, file *multipart.FileHeader, key string) (string, error) {
-	// Open uploaded file
-	src, err := file.Open()
-	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %w", err)
-	}
-	defer src.Close()
+func (s *S3Storage) Type() string {
+	return "s3"
+}
 
-	// Determine content type
-	contentType := file.Header.Get("Content-Type")
+// Upload puts an object, publicly readable unless it is private media
+func (s *S3Storage) Upload(ctx context.Context, key string, r io.Reader, opts ObjectOptions) error {
+	contentType := opts.ContentType
 	if contentType == "" {
 		contentType = "application/octet-stream"
 	}
 
-	// Upload to S3/MinIO with public-read ACL
-	_, err = s.client.PutObject(ctx, &s3.PutObjectInput{
+	input := &s3.PutObjectInput{
 		Bucket:      aws.String(s.bucket),
 		Key:         aws.String(key),
-		Body:        src,
+		Body:        r,
 		ContentType: aws.String(contentType),
-		ACL:         "public-read", // Make file publicly accessible
+		Metadata:    opts.Metadata,
+		ACL:         objectACL(key),
+	}
+	if opts.Size > 0 {
+		input.ContentLength = aws.Int64(opts.Size)
+	}
+
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to upload to S3: %w", err)
+		return nil, fmt.Errorf("failed to get object from S3: %w", s3Error(err))
 	}
+	return out.Body, nil
+}
 
-	return key, nil
+func (s *S3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object in S3: %w", s3Error(err))
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         aws.ToInt64(out.ContentLength),
+		ContentType:  aws.ToString(out.ContentType),
+		LastModified: aws.ToTime(out.LastModified),
+		ETag:         strings.Trim(aws.ToString(out.ETag), `"`),
+		Metadata:     out.Metadata,
+	}, nil
+}
+
+// Copy copies server-side, so the object never passes through this process
+func (s *S3Storage) Copy(ctx context.Context, srcKey, dstKey string) error {
+	_, err := s.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:            aws.String(s.bucket),
+		Key:               aws.String(dstKey),
+		CopySource:        aws.String(s.bucket + "/" + url.PathEscape(srcKey)),
+		MetadataDirective: types.MetadataDirectiveCopy,
+		ACL:               objectACL(dstKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy object in S3: %w", s3Error(err))
+	}
+	return nil
+}
+
+// List pages through the bucket; content type and metadata are not part of
+// the listing, use Stat when they are needed
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	var objects []ObjectInfo
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects in S3: %w", err)
+		}
+		for _, obj := range page.Contents {
+			objects = append(objects, ObjectInfo{
+				Key:          aws.ToString(obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				LastModified: aws.ToTime(obj.LastModified),
+				ETag:         strings.Trim(aws.ToString(obj.ETag), `"`),
+			})
+		}
+	}
+	return objects, nil
 }
 
 func (s *S3Storage) Delete(ctx context.Context, key string) error {
@@ -213,6 +556,46 @@ func (s *S3Storage) GetURL(key string) string {
 	return fmt.Sprintf("%s/%s", s.baseURL, key)
 }
 
+// s3MaxPresignExpiry is the longest lifetime SigV4 allows a presigned URL
+const s3MaxPresignExpiry = 7 * 24 * time.Hour
+
+// GetSignedURL presigns a GET. The URL points at the S3 endpoint rather than
+// a CDN base URL, which could not check the signature.
+func (s *S3Storage) GetSignedURL(key string, expires time.Duration) (string, error) {
+	if expires > s3MaxPresignExpiry {
+		return "", fmt.Errorf("signed URLs cannot be valid for more than %s", s3MaxPresignExpiry)
+	}
+	req, err := s.presignClient(expires).PresignGetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign S3 download: %w", err)
+	}
+	return req.URL, nil
+}
+
+// objectACL keeps private media out of public reach; everything else is
+// served straight from the bucket
+func objectACL(key string) types.ObjectCannedACL {
+	if IsPrivateKey(key) {
+		return types.ObjectCannedACLPrivate
+	}
+	return types.ObjectCannedACLPublicRead
+}
+
+// s3Error maps missing-object (and missing multipart upload) errors to
+// ErrObjectNotFound
+func s3Error(err error) error {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	var noSuchUpload *types.NoSuchUpload
+	if errors.As(err, &noSuchKey) || errors.As(err, &notFound) || errors.As(err, &noSuchUpload) {
+		return fmt.Errorf("%w: %v", ErrObjectNotFound, err)
+	}
+	return err
+}
+
 // GenerateStorageKey generates a unique storage key for a file
 func GenerateStorageKey(filename string) string {
 	ext := filepath.Ext(filename)
@@ -221,18 +604,3 @@ func GenerateStorageKey(filename string) string {
 
 	return fmt.Sprintf("media/%s/%s%s", timestamp, uniqueID, ext)
 }
-
-// uploadFile uploads a file from an io.Reader (for thumbnail upload)
-func (s *S3Storage) uploadFile(ctx context.Context, file io.Reader, key string, contentType string) error {
-	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:      aws.String(s.bucket),
-		Key:         aws.String(key),
-		Body:        file,
-		ContentType: aws.String(contentType),
-		ACL:         "public-read",
-	})
-	if err != nil {
-		return fmt.Errorf("failed to upload to S3: %w", err)
-	}
-	return nil
-}
@@ -0,0 +1,161 @@
+// internal/services/storage_check.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// DefaultOrphanGracePeriod is how old an unreferenced object must be before
+// CheckStorage deletes it. Uploads and processing write objects before the
+// row that references them, so young orphans may still be claimed.
+const DefaultOrphanGracePeriod = 72 * time.Hour
+
+// StorageCheckOptions controls CheckStorage
+type StorageCheckOptions struct {
+	DeleteOrphans bool          // Remove unreferenced objects older than GracePeriod
+	GracePeriod   time.Duration // Defaults to DefaultOrphanGracePeriod
+}
+
+// MissingObject is a key recorded on a media row that is not in storage
+type MissingObject struct {
+	MediaID pgtype.UUID
+	Key     string
+}
+
+// StorageCheckReport is the result of reconciling storage with media rows
+type StorageCheckReport struct {
+	Objects int             // Objects in storage
+	Missing []MissingObject // Referenced by media stored here but absent
+	Orphans []ObjectInfo    // Stored but referenced by no media row or pending upload
+	Reaped  int             // Orphans deleted
+	Unused  []generated.Media
+}
+
+// CheckStorage lists every stored object and compares it with every key
+// column in media, reporting files rows point at but storage lacks and
+// objects nothing refers to. Keys of soft-deleted media and of direct uploads
+// still in progress count as referenced. Unused lists media that no content
+// refers to; it is informational and never deleted here.
+func (s *MediaService) CheckStorage(ctx context.Context, opts StorageCheckOptions) (*StorageCheckReport, error) {
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultOrphanGracePeriod
+	}
+
+	objects, err := s.storage.List(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.queries.ListMediaStorageKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list media keys: %w", err)
+	}
+	uploadKeys, err := s.queries.ListMediaUploadKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending uploads: %w", err)
+	}
+
+	refs := newStorageRefs(rows, uploadKeys)
+
+	report := &StorageCheckReport{Objects: len(objects)}
+	stored := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		stored[obj.Key] = true
+		if !refs.references(obj.Key) {
+			report.Orphans = append(report.Orphans, obj)
+		}
+	}
+
+	// Rows recorded elsewhere (e.g. before a storage migration) cannot be
+	// expected to have files here
+	here := LocationOf(s.storage)
+	for _, row := range rows {
+		if row.StorageType != here.Type || row.S3Bucket.String != here.Bucket {
+			continue
+		}
+		for _, key := range rowKeys(row) {
+			if !stored[key] {
+				report.Missing = append(report.Missing, MissingObject{MediaID: row.ID, Key: key})
+			}
+		}
+	}
+
+	if opts.DeleteOrphans {
+		cutoff := time.Now().Add(-opts.GracePeriod)
+		for _, obj := range report.Orphans {
+			if obj.LastModified.IsZero() || obj.LastModified.After(cutoff) {
+				continue
+			}
+			if err := s.storage.Delete(ctx, obj.Key); err != nil {
+				return report, fmt.Errorf("failed to delete orphan %s: %w", obj.Key, err)
+			}
+			report.Reaped++
+		}
+	}
+
+	report.Unused, err = s.queries.GetOrphanedMedia(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list unused media: %w", err)
+	}
+	return report, nil
+}
+
+// storageRefs is the set of keys media rows and pending uploads refer to
+type storageRefs struct {
+	keys map[string]bool
+	dirs []string // HLS segments share the playlist's directory; derived images the original's
+}
+
+func newStorageRefs(rows []generated.ListMediaStorageKeysRow, uploadKeys []string) *storageRefs {
+	refs := &storageRefs{keys: make(map[string]bool)}
+	for _, row := range rows {
+		for _, key := range rowKeys(row) {
+			refs.keys[key] = true
+		}
+		if row.HlsKey.Valid && row.HlsKey.String != "" {
+			refs.dirs = append(refs.dirs, path.Dir(row.HlsKey.String)+"/")
+		}
+		if row.OriginalKey.Valid && row.OriginalKey.String != "" {
+			refs.dirs = append(refs.dirs, derivedImagePrefix(row.OriginalKey.String))
+		}
+	}
+	for _, key := range uploadKeys {
+		refs.keys[key] = true
+	}
+	return refs
+}
+
+func (r *storageRefs) references(key string) bool {
+	if r.keys[key] {
+		return true
+	}
+	for _, dir := range r.dirs {
+		if strings.HasPrefix(key, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// rowKeys returns the keys a storage listing row refers to directly
+func rowKeys(row generated.ListMediaStorageKeysRow) []string {
+	return storedKeys(row.OriginalKey, row.LargeKey, row.MediumKey, row.ThumbnailKey, row.WebKey, row.HlsKey,
+		row.HeroKey, row.CardKey, row.OgKey)
+}
+
+// storedKeys returns the keys that are set
+func storedKeys(keys ...pgtype.Text) []string {
+	var out []string
+	for _, key := range keys {
+		if key.Valid && key.String != "" {
+			out = append(out, key.String)
+		}
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageRefs(t *testing.T) {
+	text := func(s string) pgtype.Text { return pgtype.Text{String: s, Valid: true} }
+
+	rows := []generated.ListMediaStorageKeysRow{
+		{
+			OriginalKey:  text("media/2024/01/02/a.jpg"),
+			LargeKey:     text("media/2024/01/02/a_large.jpg"),
+			ThumbnailKey: text("media/2024/01/02/a_thumb.jpg"),
+			HeroKey:      text("media/2024/01/02/a_hero_050030.jpg"),
+		},
+		{
+			OriginalKey: text("private/media/2024/01/02/b.mp4"),
+			WebKey:      text("private/media/2024/01/02/b_web.mp4"),
+			HlsKey:      text("private/media/2024/01/02/b_hls/master.m3u8"),
+		},
+	}
+	refs := newStorageRefs(rows, []string{"media/2024/01/03/pending.png"})
+
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{name: "success: original", key: "media/2024/01/02/a.jpg", want: true},
+		{name: "success: rendition", key: "media/2024/01/02/a_thumb.jpg", want: true},
+		{name: "success: focal point crop", key: "media/2024/01/02/a_hero_050030.jpg", want: true},
+		{name: "success: image derived on request", key: "media/2024/01/02/a_img/800x600_cover_q75_050050.webp", want: true},
+		{name: "success: HLS segment in the playlist directory", key: "private/media/2024/01/02/b_hls/720p_003.ts", want: true},
+		{name: "success: pending direct upload", key: "media/2024/01/03/pending.png", want: true},
+		{name: "edge: unset medium rendition", key: "media/2024/01/02/a_medium.jpg", want: false},
+		{name: "edge: crop around a previous focal point", key: "media/2024/01/02/a_hero_050050.jpg", want: false},
+		{name: "edge: public copy left behind by a visibility change", key: "media/2024/01/02/b.mp4", want: false},
+		{name: "edge: sibling of the HLS directory", key: "private/media/2024/01/02/b_hls_old/master.m3u8", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := refs.references(tt.key)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStoredKeys(t *testing.T) {
+	// Act
+	got := storedKeys(
+		pgtype.Text{String: "media/a.jpg", Valid: true},
+		pgtype.Text{},
+		pgtype.Text{String: "", Valid: true},
+		pgtype.Text{String: "media/a_thumb.jpg", Valid: true},
+	)
+
+	// Assert
+	assert.Equal(t, []string{"media/a.jpg", "media/a_thumb.jpg"}, got)
+}
@@ -0,0 +1,288 @@
+// internal/services/storage_migration.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// checksumMetadataKey holds the hex SHA-256 of an object's content in its
+// storage metadata
+const checksumMetadataKey = "sha256"
+
+// StorageLocation is where a provider stores objects, as recorded on media
+// rows in storage_type, s3_bucket and s3_region
+type StorageLocation struct {
+	Type   string
+	Bucket string
+	Region string
+}
+
+// LocationOf returns the location objects stored in p are recorded under
+func LocationOf(p StorageProvider) StorageLocation {
+	loc := StorageLocation{Type: p.Type()}
+	if s3Storage, ok := p.(*S3Storage); ok {
+		loc.Bucket = s3Storage.bucket
+		loc.Region = s3Storage.region
+	}
+	return loc
+}
+
+func (l StorageLocation) String() string {
+	if l.Bucket == "" {
+		return l.Type
+	}
+	return fmt.Sprintf("%s://%s (%s)", l.Type, l.Bucket, l.Region)
+}
+
+// holds reports whether media is recorded as stored at l. The region is not
+// compared; a bucket name identifies the bucket.
+func (l StorageLocation) holds(media *generated.Media) bool {
+	return media.StorageType == l.Type && media.S3Bucket.String == l.Bucket
+}
+
+func (l StorageLocation) bucketText() pgtype.Text {
+	return pgtype.Text{String: l.Bucket, Valid: l.Bucket != ""}
+}
+
+func (l StorageLocation) regionText() pgtype.Text {
+	return pgtype.Text{String: l.Region, Valid: l.Region != ""}
+}
+
+// StorageMigrationOptions controls MigrateStorage
+type StorageMigrationOptions struct {
+	BatchSize int32 // Media rows read per query; defaults to 100
+	DryRun    bool  // Report what would be migrated without copying
+}
+
+// StorageMigrationReport summarizes a MigrateStorage run
+type StorageMigrationReport struct {
+	Migrated int // Rows moved to the target in this run
+	Already  int // Rows already on the target, e.g. from an interrupted run
+	Skipped  int // Rows left alone: elsewhere, processing, or changed meanwhile
+	Failed   int
+	Objects  int   // Objects copied or verified
+	Bytes    int64 // Bytes copied
+}
+
+// MigrateStorage copies every media row's original and derived files from
+// the service's storage to target, verifying each copy's SHA-256, then points
+// the row at target in a transaction. Files on the current storage are left
+// in place. Rows already on target are skipped and copies verified by an
+// earlier run are not repeated, so an interrupted migration can simply be run
+// again.
+func (s *MediaService) MigrateStorage(ctx context.Context, target StorageProvider, opts StorageMigrationOptions, logger *slog.Logger) (*StorageMigrationReport, error) {
+	source, dest := LocationOf(s.storage), LocationOf(target)
+	if source == dest {
+		return nil, fmt.Errorf("source and target storage are both %s", source)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+
+	report := &StorageMigrationReport{}
+	after := pgtype.UUID{Valid: true} // The nil UUID sorts first
+
+	for {
+		batch, err := s.queries.ListMediaForStorageMigration(ctx, generated.ListMediaForStorageMigrationParams{
+			AfterID:  after,
+			LimitVal: opts.BatchSize,
+		})
+		if err != nil {
+			return report, fmt.Errorf("failed to list media: %w", err)
+		}
+
+		for _, media := range batch {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			after = media.ID
+			log := logger.With("media_id", media.ID.String(), "filename", media.OriginalFilename)
+
+			switch {
+			case dest.holds(&media):
+				report.Already++
+				continue
+			case !source.holds(&media):
+				log.Warn("skipping media stored elsewhere", "storage_type", media.StorageType, "bucket", media.S3Bucket.String)
+				report.Skipped++
+				continue
+			case media.ProcessingStatus == MediaStatusPending || media.ProcessingStatus == MediaStatusProcessing:
+				log.Warn("skipping media that is still being processed; run again once it is ready")
+				report.Skipped++
+				continue
+			}
+
+			if opts.DryRun {
+				log.Info("would migrate media")
+				report.Migrated++
+				continue
+			}
+
+			moved, err := s.migrateMedia(ctx, &media, target, dest, report)
+			switch {
+			case err != nil:
+				log.Error("failed to migrate media", "error", err)
+				report.Failed++
+			case !moved:
+				log.Warn("media changed during migration; run again to retry")
+				report.Skipped++
+			default:
+				log.Info("migrated media")
+				report.Migrated++
+			}
+		}
+
+		if len(batch) < int(opts.BatchSize) {
+			return report, nil
+		}
+	}
+}
+
+// migrateMedia copies one row's objects to target and records the new
+// location. It returns false without error if the row changed in the
+// meantime, leaving it for the next run.
+func (s *MediaService) migrateMedia(ctx context.Context, media *generated.Media, target StorageProvider, dest StorageLocation, report *StorageMigrationReport) (bool, error) {
+	keys, err := s.mediaObjectKeys(ctx, media)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		n, err := copyObjectVerified(ctx, s.storage, target, key)
+		if err != nil {
+			return false, err
+		}
+		report.Objects++
+		report.Bytes += n
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	// The copies are only complete if the row still names the same files
+	current, err := qtx.GetMediaForUpdate(ctx, media.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to lock media: %w", err)
+	}
+	if !sameObjectKeys(&current, media) || current.StorageType != media.StorageType || current.S3Bucket != media.S3Bucket {
+		return false, nil
+	}
+
+	if err := qtx.UpdateMediaStorageLocation(ctx, generated.UpdateMediaStorageLocationParams{
+		StorageType: dest.Type,
+		S3Bucket:    dest.bucketText(),
+		S3Region:    dest.regionText(),
+		ID:          media.ID,
+	}); err != nil {
+		return false, fmt.Errorf("failed to update storage location: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit storage location: %w", err)
+	}
+	return true, nil
+}
+
+func sameObjectKeys(a, b *generated.Media) bool {
+	return a.OriginalKey == b.OriginalKey &&
+		a.LargeKey == b.LargeKey &&
+		a.MediumKey == b.MediumKey &&
+		a.ThumbnailKey == b.ThumbnailKey &&
+		a.WebKey == b.WebKey &&
+		a.HlsKey == b.HlsKey &&
+		a.HeroKey == b.HeroKey &&
+		a.CardKey == b.CardKey &&
+		a.OgKey == b.OgKey
+}
+
+// copyObjectVerified copies key from src to dst and checks the stored copy
+// hashes the same as the source, returning the bytes copied. A copy already
+// on dst whose content matches the checksum recorded with it is kept.
+func copyObjectVerified(ctx context.Context, src, dst StorageProvider, key string) (int64, error) {
+	info, err := src.Stat(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if existing, err := dst.Stat(ctx, key); err == nil && existing.Size == info.Size && existing.Metadata[checksumMetadataKey] != "" {
+		if sum, err := objectChecksum(ctx, dst, key); err == nil && sum == existing.Metadata[checksumMetadataKey] {
+			return 0, nil
+		}
+	} else if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return 0, fmt.Errorf("failed to check %s on target: %w", key, err)
+	}
+
+	// Spool to disk so the checksum can travel with the upload
+	r, err := src.Open(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	hash := sha256.New()
+	tmpPath, err := saveTempFile(io.TeeReader(r, hash), "migrate_*")
+	r.Close()
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpPath)
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	tmp, err := os.Open(tmpPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open temp file: %w", err)
+	}
+	defer tmp.Close()
+	fi, err := tmp.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat temp file: %w", err)
+	}
+
+	metadata := maps.Clone(info.Metadata)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	metadata[checksumMetadataKey] = sum
+
+	if err := dst.Upload(ctx, key, tmp, ObjectOptions{ContentType: info.ContentType, Size: fi.Size(), Metadata: metadata}); err != nil {
+		return 0, fmt.Errorf("failed to copy %s: %w", key, err)
+	}
+
+	copied, err := objectChecksum(ctx, dst, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify %s: %w", key, err)
+	}
+	if copied != sum {
+		dst.Delete(ctx, key) // Best effort; the next run copies it again
+		return 0, fmt.Errorf("checksum mismatch for %s: source %s, target %s", key, sum, copied)
+	}
+	return fi.Size(), nil
+}
+
+// objectChecksum returns the hex SHA-256 of a stored object's content
+func objectChecksum(ctx context.Context, p StorageProvider, key string) (string, error) {
+	r, err := p.Open(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
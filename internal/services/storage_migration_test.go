@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/iankencruz/threefive/database/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyObjectVerified(t *testing.T) {
+	ctx := context.Background()
+	const key = "media/2024/01/02/a.jpg"
+	const content = "original image bytes"
+
+	setup := func(t *testing.T) (*LocalStorage, *LocalStorage) {
+		src := NewLocalStorage(t.TempDir(), "/uploads", nil)
+		dst := NewLocalStorage(t.TempDir(), "/uploads", nil)
+		require.NoError(t, src.Upload(ctx, key, strings.NewReader(content), ObjectOptions{
+			ContentType: "image/jpeg",
+			Metadata:    map[string]string{"owner": "admin"},
+		}))
+		return src, dst
+	}
+
+	readAll := func(t *testing.T, s StorageProvider) string {
+		t.Helper()
+		r, err := s.Open(ctx, key)
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	wantSum := hex.EncodeToString(sum[:])
+
+	t.Run("success: copies content, type and metadata with checksum", func(t *testing.T) {
+		src, dst := setup(t)
+
+		// Act
+		n, err := copyObjectVerified(ctx, src, dst, key)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, readAll(t, dst))
+		info, err := dst.Stat(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", info.ContentType)
+		assert.Equal(t, "admin", info.Metadata["owner"])
+		assert.Equal(t, wantSum, info.Metadata[checksumMetadataKey])
+	})
+
+	t.Run("success: verified copy from an earlier run is kept", func(t *testing.T) {
+		src, dst := setup(t)
+		_, err := copyObjectVerified(ctx, src, dst, key)
+		require.NoError(t, err)
+
+		// Act
+		n, err := copyObjectVerified(ctx, src, dst, key)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Zero(t, n, "nothing should be copied again")
+	})
+
+	t.Run("edge: corrupt copy is replaced", func(t *testing.T) {
+		src, dst := setup(t)
+		corrupt := strings.Repeat("x", len(content))
+		require.NoError(t, dst.Upload(ctx, key, strings.NewReader(corrupt), ObjectOptions{
+			Metadata: map[string]string{checksumMetadataKey: wantSum},
+		}))
+
+		// Act
+		n, err := copyObjectVerified(ctx, src, dst, key)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(content)), n)
+		assert.Equal(t, content, readAll(t, dst))
+	})
+
+	t.Run("error: missing source object", func(t *testing.T) {
+		src, dst := setup(t)
+
+		// Act
+		_, err := copyObjectVerified(ctx, src, dst, "media/missing.jpg")
+
+		// Assert
+		assert.ErrorIs(t, err, ErrObjectNotFound)
+	})
+}
+
+func TestStorageLocationHolds(t *testing.T) {
+	s3 := StorageLocation{Type: "s3", Bucket: "threefive-media", Region: "us-east-1"}
+	local := StorageLocation{Type: "local"}
+
+	tests := []struct {
+		name     string
+		location StorageLocation
+		media    generated.Media
+		want     bool
+	}{
+		{name: "success: local media", location: local, media: generated.Media{StorageType: "local"}, want: true},
+		{name: "success: same bucket", location: s3, media: generated.Media{StorageType: "s3", S3Bucket: pgtype.Text{String: "threefive-media", Valid: true}}, want: true},
+		{name: "edge: region is not compared", location: s3, media: generated.Media{StorageType: "s3", S3Bucket: pgtype.Text{String: "threefive-media", Valid: true}, S3Region: pgtype.Text{String: "ewr1", Valid: true}}, want: true},
+		{name: "edge: other bucket", location: s3, media: generated.Media{StorageType: "s3", S3Bucket: pgtype.Text{String: "old-media", Valid: true}}, want: false},
+		{name: "edge: local media on s3 location", location: s3, media: generated.Media{StorageType: "local"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.location.holds(&tt.media)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
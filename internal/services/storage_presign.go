@@ -0,0 +1,150 @@
+// internal/services/storage_presign.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DirectUploader is implemented by storage the browser can upload to without
+// the file passing through this server
+type DirectUploader interface {
+	// PresignPut returns a single PUT request for an object of exactly size bytes
+	PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error)
+	// CreateMultipartUpload starts a multipart upload and returns its ID
+	CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error)
+	PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int32, size int64, expires time.Duration) (*PresignedRequest, error)
+	CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error
+	AbortMultipartUpload(ctx context.Context, key, uploadID string) error
+}
+
+// PresignedRequest is a request the browser sends as-is, with Headers set
+type PresignedRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// CompletedPart is a part the browser uploaded, with the ETag S3 returned for it
+type CompletedPart struct {
+	PartNumber int32  `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+// presignClient signs requests without the SDK's default CRC32 checksum,
+// which the browser could not compute ahead of sending the body
+func (s *S3Storage) presignClient(expires time.Duration) *s3.PresignClient {
+	return s3.NewPresignClient(s.client,
+		s3.WithPresignExpires(expires),
+		s3.WithPresignClientFromClientOptions(func(o *s3.Options) {
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		}),
+	)
+}
+
+// PresignPut signs a PUT carrying the key's ACL. The size is part of the signature, so
+// S3 rejects a body of any other length.
+func (s *S3Storage) PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (*PresignedRequest, error) {
+	req, err := s.presignClient(expires).PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		ContentType:   aws.String(contentType),
+		ContentLength: aws.Int64(size),
+		ACL:           objectACL(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign S3 upload: %w", err)
+	}
+	return newPresignedRequest(req.Method, req.URL, req.SignedHeader), nil
+}
+
+func (s *S3Storage) CreateMultipartUpload(ctx context.Context, key, contentType string) (string, error) {
+	out, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		ContentType: aws.String(contentType),
+		ACL:         objectACL(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create S3 multipart upload: %w", err)
+	}
+	return aws.ToString(out.UploadId), nil
+}
+
+func (s *S3Storage) PresignUploadPart(ctx context.Context, key, uploadID string, partNumber int32, size int64, expires time.Duration) (*PresignedRequest, error) {
+	req, err := s.presignClient(expires).PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		UploadId:      aws.String(uploadID),
+		PartNumber:    aws.Int32(partNumber),
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to presign S3 upload part %d: %w", partNumber, err)
+	}
+	return newPresignedRequest(req.Method, req.URL, req.SignedHeader), nil
+}
+
+func (s *S3Storage) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []CompletedPart) error {
+	completed := make([]types.CompletedPart, len(parts))
+	for i, part := range parts {
+		completed[i] = types.CompletedPart{
+			PartNumber: aws.Int32(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		}
+	}
+	// S3 requires ascending part numbers
+	sort.Slice(completed, func(i, j int) bool {
+		return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+	})
+
+	_, err := s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(uploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete S3 multipart upload: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Storage) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(s.bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort S3 multipart upload: %w", s3Error(err))
+	}
+	return nil
+}
+
+// newPresignedRequest keeps the signed headers the browser has to send.
+// Host and Content-Length are set by the browser itself and cannot be.
+func newPresignedRequest(method, url string, signed http.Header) *PresignedRequest {
+	req := &PresignedRequest{Method: method, URL: url}
+	for name, values := range signed {
+		switch http.CanonicalHeaderKey(name) {
+		case "Host", "Content-Length":
+			continue
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if req.Headers == nil {
+			req.Headers = make(map[string]string)
+		}
+		req.Headers[http.CanonicalHeaderKey(name)] = values[0]
+	}
+	return req
+}
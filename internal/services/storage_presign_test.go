@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartSizes(t *testing.T) {
+	const mb = 1024 * 1024
+
+	tests := []struct {
+		name string
+		size int64
+		want []int64
+	}{
+		{name: "success: exact multiple", size: 32 * mb, want: []int64{16 * mb, 16 * mb}},
+		{name: "success: short last part", size: 40 * mb, want: []int64{16 * mb, 16 * mb, 8 * mb}},
+		{name: "edge: smaller than one part", size: 10, want: []int64{10}},
+		{name: "edge: empty", size: 0, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := partSizes(tt.size, directUploadPartSize)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewPresignedRequest(t *testing.T) {
+	signed := http.Header{
+		"Host":           {"localhost:9000"},
+		"Content-Length": {"5"},
+		"content-type":   {"image/jpeg"},
+		"X-Amz-Acl":      {"public-read"},
+	}
+
+	// Act
+	req := newPresignedRequest(http.MethodPut, "http://localhost:9000/bucket/key", signed)
+
+	// Assert
+	assert.Equal(t, http.MethodPut, req.Method)
+	assert.Equal(t, map[string]string{
+		"Content-Type": "image/jpeg",
+		"X-Amz-Acl":    "public-read",
+	}, req.Headers)
+}
+
+func TestS3StoragePresign(t *testing.T) {
+	ctx := context.Background()
+
+	// Presigning is local; no request reaches the endpoint
+	s, err := NewS3Storage(ctx, S3Config{
+		Bucket:          "threefive-media",
+		Region:          "us-east-1",
+		Endpoint:        "http://localhost:9000",
+		AccessKeyID:     "minioadmin",
+		SecretAccessKey: "minioadmin",
+	})
+	require.NoError(t, err)
+
+	t.Run("success: put is signed for the content type and size", func(t *testing.T) {
+		// Act
+		req, err := s.PresignPut(ctx, "media/2024/01/a.jpg", "image/jpeg", 1234, time.Hour)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPut, req.Method)
+
+		u, err := url.Parse(req.URL)
+		require.NoError(t, err)
+		assert.Equal(t, "localhost:9000", u.Host)
+		assert.Equal(t, "/threefive-media/media/2024/01/a.jpg", u.Path)
+		assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+		assert.Contains(t, u.Query().Get("X-Amz-SignedHeaders"), "content-length")
+		assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+		assert.Empty(t, u.Query().Get("x-amz-sdk-checksum-algorithm"))
+
+		assert.Equal(t, "image/jpeg", req.Headers["Content-Type"])
+		assert.Equal(t, "public-read", req.Headers["X-Amz-Acl"])
+		assert.NotContains(t, req.Headers, "Host")
+		assert.NotContains(t, req.Headers, "Content-Length")
+	})
+
+	t.Run("success: upload part carries the upload ID and part number", func(t *testing.T) {
+		// Act
+		req, err := s.PresignUploadPart(ctx, "media/2024/01/b.mp4", "upload-123", 2, 1234, time.Hour)
+
+		// Assert
+		require.NoError(t, err)
+		u, err := url.Parse(req.URL)
+		require.NoError(t, err)
+		assert.Equal(t, "upload-123", u.Query().Get("uploadId"))
+		assert.Equal(t, "2", u.Query().Get("partNumber"))
+	})
+
+	t.Run("success: private keys are signed with a private ACL", func(t *testing.T) {
+		// Act
+		req, err := s.PresignPut(ctx, "private/media/2024/01/c.jpg", "image/jpeg", 1234, time.Hour)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "private", req.Headers["X-Amz-Acl"])
+	})
+
+	t.Run("success: get is signed for the bucket endpoint", func(t *testing.T) {
+		// Act
+		raw, err := s.GetSignedURL("private/media/2024/01/c.jpg", 24*time.Hour)
+
+		// Assert
+		require.NoError(t, err)
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		assert.Equal(t, "/threefive-media/private/media/2024/01/c.jpg", u.Path)
+		assert.Equal(t, "86400", u.Query().Get("X-Amz-Expires"))
+		assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+	})
+
+	t.Run("failure: get cannot outlive the SigV4 limit", func(t *testing.T) {
+		// Act
+		_, err := s.GetSignedURL("private/media/2024/01/c.jpg", 8*24*time.Hour)
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
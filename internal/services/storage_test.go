@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+
+	newStorage := func(t *testing.T) (*LocalStorage, string) {
+		dir := t.TempDir()
+		return NewLocalStorage(dir, "/uploads", []byte("test-signing-key")), dir
+	}
+
+	readAll := func(t *testing.T, s *LocalStorage, key string) string {
+		t.Helper()
+		r, err := s.Open(ctx, key)
+		require.NoError(t, err)
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("success: upload, stat and open", func(t *testing.T) {
+		s, _ := newStorage(t)
+
+		// Act
+		err := s.Upload(ctx, "media/2024/01/a.jpg", strings.NewReader("hello"), ObjectOptions{
+			ContentType: "image/jpeg",
+			Metadata:    map[string]string{"sha256": "abc"},
+		})
+
+		// Assert
+		require.NoError(t, err)
+		info, err := s.Stat(ctx, "media/2024/01/a.jpg")
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), info.Size)
+		assert.Equal(t, "image/jpeg", info.ContentType)
+		assert.Equal(t, "abc", info.Metadata["sha256"])
+		assert.Equal(t, "hello", readAll(t, s, "media/2024/01/a.jpg"))
+	})
+
+	t.Run("success: content type guessed without sidecar", func(t *testing.T) {
+		s, _ := newStorage(t)
+		require.NoError(t, s.Upload(ctx, "media/b.png", strings.NewReader("x"), ObjectOptions{}))
+
+		// Act
+		info, err := s.Stat(ctx, "media/b.png")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", info.ContentType)
+		assert.Nil(t, info.Metadata)
+	})
+
+	t.Run("success: copy keeps content and metadata", func(t *testing.T) {
+		s, _ := newStorage(t)
+		require.NoError(t, s.Upload(ctx, "media/src.bin", strings.NewReader("data"), ObjectOptions{
+			ContentType: "application/pdf",
+			Metadata:    map[string]string{"k": "v"},
+		}))
+
+		// Act
+		err := s.Copy(ctx, "media/src.bin", "backup/dst.bin")
+
+		// Assert
+		require.NoError(t, err)
+		info, err := s.Stat(ctx, "backup/dst.bin")
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", info.ContentType)
+		assert.Equal(t, "v", info.Metadata["k"])
+		assert.Equal(t, "data", readAll(t, s, "backup/dst.bin"))
+	})
+
+	t.Run("success: list filters by prefix and skips sidecars", func(t *testing.T) {
+		s, _ := newStorage(t)
+		for _, key := range []string{"media/a.jpg", "media/sub/b.jpg", "other/c.jpg"} {
+			require.NoError(t, s.Upload(ctx, key, strings.NewReader("x"), ObjectOptions{ContentType: "image/jpeg"}))
+		}
+
+		// Act
+		objects, err := s.List(ctx, "media/")
+
+		// Assert
+		require.NoError(t, err)
+		var keys []string
+		for _, obj := range objects {
+			keys = append(keys, obj.Key)
+		}
+		assert.ElementsMatch(t, []string{"media/a.jpg", "media/sub/b.jpg"}, keys)
+	})
+
+	t.Run("success: delete removes file and sidecar", func(t *testing.T) {
+		s, dir := newStorage(t)
+		require.NoError(t, s.Upload(ctx, "media/a.jpg", strings.NewReader("x"), ObjectOptions{ContentType: "image/jpeg"}))
+
+		// Act
+		err := s.Delete(ctx, "media/a.jpg")
+
+		// Assert
+		require.NoError(t, err)
+		_, err = s.Stat(ctx, "media/a.jpg")
+		assert.ErrorIs(t, err, ErrObjectNotFound)
+		_, err = os.Stat(filepath.Join(dir, localMetaDir, "media", "a.jpg.json"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("failure: missing object", func(t *testing.T) {
+		s, _ := newStorage(t)
+
+		// Act
+		_, openErr := s.Open(ctx, "media/missing.jpg")
+		_, statErr := s.Stat(ctx, "media/missing.jpg")
+
+		// Assert
+		assert.ErrorIs(t, openErr, ErrObjectNotFound)
+		assert.ErrorIs(t, statErr, ErrObjectNotFound)
+	})
+
+	t.Run("security: keys cannot escape the upload directory", func(t *testing.T) {
+		s, _ := newStorage(t)
+
+		for _, key := range []string{"../escape.txt", "media/../../escape.txt", "/etc/passwd", ".meta/x.json", ""} {
+			// Act
+			err := s.Upload(ctx, key, strings.NewReader("x"), ObjectOptions{})
+
+			// Assert
+			assert.Error(t, err, key)
+		}
+	})
+}
+
+func TestLocalStorageSignedURL(t *testing.T) {
+	s := NewLocalStorage(t.TempDir(), "/uploads", []byte("test-signing-key"))
+	key := "private/media/2024/01/a.jpg"
+
+	signed := func(t *testing.T, expires time.Duration) url.Values {
+		t.Helper()
+		raw, err := s.GetSignedURL(key, expires)
+		require.NoError(t, err)
+		u, err := url.Parse(raw)
+		require.NoError(t, err)
+		assert.Equal(t, "/uploads/"+key, u.Path)
+		return u.Query()
+	}
+
+	t.Run("success: signature verifies until expiry", func(t *testing.T) {
+		query := signed(t, time.Hour)
+
+		// Act
+		expiresAt, ok := s.VerifySignature(key, query.Get("expires"), query.Get("signature"))
+
+		// Assert
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, 2*time.Second)
+	})
+
+	t.Run("failure: expired URL", func(t *testing.T) {
+		query := signed(t, -time.Minute)
+
+		// Act
+		_, ok := s.VerifySignature(key, query.Get("expires"), query.Get("signature"))
+
+		// Assert
+		assert.False(t, ok)
+	})
+
+	t.Run("security: signature is bound to key and expiry", func(t *testing.T) {
+		query := signed(t, time.Hour)
+		later := strconv.FormatInt(time.Now().Add(30*24*time.Hour).Unix(), 10)
+
+		// Act
+		_, otherKey := s.VerifySignature("private/media/2024/01/b.jpg", query.Get("expires"), query.Get("signature"))
+		_, extended := s.VerifySignature(key, later, query.Get("signature"))
+		_, missing := s.VerifySignature(key, query.Get("expires"), "")
+
+		// Assert
+		assert.False(t, otherKey)
+		assert.False(t, extended)
+		assert.False(t, missing)
+	})
+
+	t.Run("security: other signing keys are rejected", func(t *testing.T) {
+		other := NewLocalStorage(t.TempDir(), "/uploads", []byte("another-key"))
+		query := signed(t, time.Hour)
+
+		// Act
+		_, ok := other.VerifySignature(key, query.Get("expires"), query.Get("signature"))
+
+		// Assert
+		assert.False(t, ok)
+	})
+
+	t.Run("failure: no signing key configured", func(t *testing.T) {
+		unsigned := NewLocalStorage(t.TempDir(), "/uploads", nil)
+
+		// Act
+		_, err := unsigned.GetSignedURL(key, time.Hour)
+		_, ok := unsigned.VerifySignature(key, "0", "")
+
+		// Assert
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -283,7 +283,7 @@ func (s *TagService) DeleteTag(ctx context.Context, tagID uuid.UUID) error {
 	}
 
 	if usageCount > 0 {
-		return fmt.Errorf("cannot delete tag: it is used by %d projects", usageCount)
+		return fmt.Errorf("cannot delete tag: it is used by %d projects or posts", usageCount)
 	}
 
 	if err := s.queries.DeleteTag(ctx, pgtype.UUID{
@@ -311,7 +311,7 @@ func (s *TagService) DeleteTagBySlug(ctx context.Context, slug string) error {
 	}
 
 	if usageCount > 0 {
-		return fmt.Errorf("cannot delete tag '%s': it is used by %d project(s)", tag.Name, usageCount)
+		return fmt.Errorf("cannot delete tag '%s': it is used by %d project(s) or post(s)", tag.Name, usageCount)
 	}
 
 	// Delete the tag
@@ -391,7 +391,7 @@ func (s *TagService) GetMostUsedTags(ctx context.Context, limit int32) ([]TagRes
 	return responses, nil
 }
 
-// GetUnusedTags retrieves tags that aren't used by any projects
+// GetUnusedTags retrieves tags that aren't used by any projects, posts or media
 func (s *TagService) GetUnusedTags(ctx context.Context) ([]generated.Tag, error) {
 	tags, err := s.queries.GetUnusedTags(ctx)
 	if err != nil {
@@ -0,0 +1,253 @@
+// internal/services/video_transcode.go
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// HLSVariant is one rung of the adaptive bitrate ladder. Height is the short
+// side, so portrait videos get the same ladder as landscape ones.
+type HLSVariant struct {
+	Name         string
+	Height       int
+	VideoBitrate int // Bits per second
+}
+
+// hlsLadder is ordered from highest to lowest quality
+var hlsLadder = []HLSVariant{
+	{Name: "1080p", Height: 1080, VideoBitrate: 5_000_000},
+	{Name: "720p", Height: 720, VideoBitrate: 2_800_000},
+	{Name: "480p", Height: 480, VideoBitrate: 1_400_000},
+	{Name: "360p", Height: 360, VideoBitrate: 800_000},
+}
+
+const (
+	webVideoMaxWidth  = 1920
+	hlsAudioBitrate   = 128_000
+	hlsSegmentSeconds = 6
+)
+
+// hlsOutput is a variant resolved against the source dimensions
+type hlsOutput struct {
+	HLSVariant
+	OutWidth  int
+	OutHeight int
+}
+
+// TranscodeResult holds the storage keys produced by TranscodeVideo
+type TranscodeResult struct {
+	WebKey string // Normalized H.264/AAC MP4
+	HLSKey string // HLS master playlist
+}
+
+// TranscodeVideo produces a web-friendly MP4 and an HLS ladder from a local
+// video file and uploads them next to the original
+func (s *MediaService) TranscodeVideo(ctx context.Context, videoPath, originalKey string, meta *VideoMetadata) (*TranscodeResult, error) {
+	workDir, err := os.MkdirTemp("", "transcode_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create work directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	base := strings.TrimSuffix(originalKey, filepath.Ext(originalKey))
+
+	var uploaded []string
+	cleanup := func() {
+		for _, key := range uploaded {
+			s.storage.Delete(ctx, key)
+		}
+	}
+
+	// Normalized MP4 (H.264 + AAC, faststart so playback begins before download ends)
+	webPath := filepath.Join(workDir, "web.mp4")
+	if err := runFFmpeg(ctx,
+		"-i", videoPath,
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", webVideoMaxWidth),
+		"-c:v", "libx264",
+		"-preset", "medium",
+		"-crf", "23",
+		"-profile:v", "high",
+		"-pix_fmt", "yuv420p",
+		"-c:a", "aac",
+		"-b:a", fmt.Sprint(hlsAudioBitrate),
+		"-movflags", "+faststart",
+		"-y",
+		webPath,
+	); err != nil {
+		return nil, fmt.Errorf("failed to transcode mp4: %w", err)
+	}
+
+	webKey := base + "_web.mp4"
+	if err := s.uploadLocalFile(ctx, webPath, webKey, "video/mp4"); err != nil {
+		return nil, err
+	}
+	uploaded = append(uploaded, webKey)
+
+	// HLS ladder
+	hlsDir := filepath.Join(workDir, "hls")
+	if err := os.MkdirAll(hlsDir, 0o755); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to create hls directory: %w", err)
+	}
+
+	outputs := selectHLSVariants(meta.Width, meta.Height)
+	for _, out := range outputs {
+		if err := runFFmpeg(ctx,
+			"-i", videoPath,
+			"-vf", fmt.Sprintf("scale=%d:%d", out.OutWidth, out.OutHeight),
+			"-c:v", "libx264",
+			"-preset", "medium",
+			"-profile:v", "main",
+			"-pix_fmt", "yuv420p",
+			"-b:v", fmt.Sprint(out.VideoBitrate),
+			"-maxrate", fmt.Sprint(out.VideoBitrate*107/100),
+			"-bufsize", fmt.Sprint(out.VideoBitrate*3/2),
+			"-g", "48",
+			"-keyint_min", "48",
+			"-sc_threshold", "0",
+			"-c:a", "aac",
+			"-b:a", fmt.Sprint(hlsAudioBitrate),
+			"-ac", "2",
+			"-f", "hls",
+			"-hls_time", fmt.Sprint(hlsSegmentSeconds),
+			"-hls_playlist_type", "vod",
+			"-hls_segment_filename", filepath.Join(hlsDir, out.Name+"_%03d.ts"),
+			"-y",
+			filepath.Join(hlsDir, out.Name+".m3u8"),
+		); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to transcode hls %s: %w", out.Name, err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(hlsDir, "master.m3u8"), []byte(buildHLSMaster(outputs)), 0o644); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to write hls master playlist: %w", err)
+	}
+
+	// Upload playlists and segments; playlists reference segments relatively
+	entries, err := os.ReadDir(hlsDir)
+	if err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to read hls directory: %w", err)
+	}
+	hlsPrefix := base + "_hls/"
+	for _, entry := range entries {
+		key := hlsPrefix + entry.Name()
+		if err := s.uploadLocalFile(ctx, filepath.Join(hlsDir, entry.Name()), key, hlsContentType(entry.Name())); err != nil {
+			cleanup()
+			return nil, err
+		}
+		uploaded = append(uploaded, key)
+	}
+
+	return &TranscodeResult{
+		WebKey: webKey,
+		HLSKey: hlsPrefix + "master.m3u8",
+	}, nil
+}
+
+// selectHLSVariants picks the ladder rungs that do not upscale the source.
+// Sources smaller than the lowest rung get a single variant at their own size.
+func selectHLSVariants(width, height int) []hlsOutput {
+	if width <= 0 || height <= 0 {
+		// Unknown dimensions, assume 720p landscape
+		width, height = 1280, 720
+	}
+	portrait := height > width
+	short, long := height, width
+	if portrait {
+		short, long = width, height
+	}
+
+	var outputs []hlsOutput
+	for _, v := range hlsLadder {
+		if v.Height > short {
+			continue
+		}
+		outputs = append(outputs, resolveVariant(v, short, long, portrait))
+	}
+
+	if len(outputs) == 0 {
+		lowest := hlsLadder[len(hlsLadder)-1]
+		lowest.Height = evenFloor(short)
+		lowest.Name = fmt.Sprintf("%dp", lowest.Height)
+		outputs = append(outputs, resolveVariant(lowest, short, long, portrait))
+	}
+
+	return outputs
+}
+
+func resolveVariant(v HLSVariant, short, long int, portrait bool) hlsOutput {
+	scaledLong := evenFloor(int(math.Round(float64(long) * float64(v.Height) / float64(short))))
+	out := hlsOutput{HLSVariant: v, OutWidth: scaledLong, OutHeight: v.Height}
+	if portrait {
+		out.OutWidth, out.OutHeight = v.Height, scaledLong
+	}
+	return out
+}
+
+// buildHLSMaster writes the master playlist pointing at each variant playlist
+func buildHLSMaster(outputs []hlsOutput) string {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, out := range outputs {
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,RESOLUTION=%dx%d\n%s.m3u8\n",
+			out.VideoBitrate+hlsAudioBitrate, out.OutWidth, out.OutHeight, out.Name)
+	}
+	return b.String()
+}
+
+func hlsContentType(name string) string {
+	switch filepath.Ext(name) {
+	case ".m3u8":
+		return "application/vnd.apple.mpegurl"
+	case ".ts":
+		return "video/mp2t"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func evenFloor(n int) int {
+	return n - n%2
+}
+
+// uploadLocalFile uploads a file from the local work directory to storage
+func (s *MediaService) uploadLocalFile(ctx context.Context, path, key, contentType string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+
+	var size int64
+	if fi, err := f.Stat(); err == nil {
+		size = fi.Size()
+	}
+
+	if err := s.storage.Upload(ctx, key, f, ObjectOptions{ContentType: contentType, Size: size}); err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+// runFFmpeg runs ffmpeg quietly, including the tail of its output on failure
+func runFFmpeg(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", append([]string{"-hide_banner", "-loglevel", "error"}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		tail := string(output)
+		if len(tail) > 500 {
+			tail = tail[len(tail)-500:]
+		}
+		return fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, tail)
+	}
+	return nil
+}
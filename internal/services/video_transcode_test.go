@@ -0,0 +1,64 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectHLSVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		width  int
+		height int
+		want   []string // name@WxH
+	}{
+		{
+			name:   "success: 1080p landscape gets the full ladder",
+			width:  1920,
+			height: 1080,
+			want:   []string{"1080p@1920x1080", "720p@1280x720", "480p@852x480", "360p@640x360"},
+		},
+		{
+			name:   "success: 720p source is not upscaled",
+			width:  1280,
+			height: 720,
+			want:   []string{"720p@1280x720", "480p@852x480", "360p@640x360"},
+		},
+		{
+			name:   "success: portrait uses the short side",
+			width:  1080,
+			height: 1920,
+			want:   []string{"1080p@1080x1920", "720p@720x1280", "480p@480x852", "360p@360x640"},
+		},
+		{
+			name:   "edge: tiny source gets a single variant at its own size",
+			width:  320,
+			height: 241,
+			want:   []string{"240p@318x240"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			outputs := selectHLSVariants(tt.width, tt.height)
+
+			// Assert
+			var got []string
+			for _, out := range outputs {
+				got = append(got, fmt.Sprintf("%s@%dx%d", out.Name, out.OutWidth, out.OutHeight))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBuildHLSMaster(t *testing.T) {
+	// Act
+	got := buildHLSMaster(selectHLSVariants(1280, 720)[:1])
+
+	// Assert
+	assert.Equal(t, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720\n720p.m3u8\n", got)
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Long-form markdown content for case studies and blog posts
+ALTER TABLE projects ADD COLUMN body TEXT;
+ALTER TABLE blogs ADD COLUMN body TEXT;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE blogs DROP COLUMN IF EXISTS body;
+ALTER TABLE projects DROP COLUMN IF EXISTS body;
+
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Derived from the markdown body on save
+ALTER TABLE blogs ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE projects ADD COLUMN reading_time INTEGER;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE projects DROP COLUMN IF EXISTS reading_time;
+ALTER TABLE projects DROP COLUMN IF EXISTS word_count;
+ALTER TABLE blogs DROP COLUMN IF EXISTS word_count;
+
+-- +goose StatementEnd
@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Processing state surfaced in the admin UI
+ALTER TABLE media ADD COLUMN processing_status TEXT NOT NULL DEFAULT 'ready'
+    CHECK (processing_status IN ('pending', 'processing', 'ready', 'failed'));
+ALTER TABLE media ADD COLUMN processing_error TEXT;
+
+-- Durable queue for background media work (renditions, video thumbnails)
+CREATE TABLE media_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    media_id UUID NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    job_type TEXT NOT NULL,              -- e.g. 'process'
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),   -- Earliest time the job may be picked up (backoff)
+    locked_at TIMESTAMPTZ,               -- Set when a worker claims the job
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_jobs_runnable ON media_jobs(run_at) WHERE status = 'pending';
+CREATE INDEX idx_media_jobs_running ON media_jobs(locked_at) WHERE status = 'running';
+CREATE INDEX idx_media_jobs_media ON media_jobs(media_id);
+
+COMMENT ON TABLE media_jobs IS 'Background media processing jobs, claimed with FOR UPDATE SKIP LOCKED';
+COMMENT ON COLUMN media.processing_status IS 'pending, processing, ready or failed';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS media_jobs;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_error;
+ALTER TABLE media DROP COLUMN IF EXISTS processing_status;
+
+-- +goose StatementEnd
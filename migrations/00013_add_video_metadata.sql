@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Filled from ffprobe by the media job workers
+ALTER TABLE media ADD COLUMN video_codec TEXT;
+ALTER TABLE media ADD COLUMN bitrate BIGINT;
+
+COMMENT ON COLUMN media.video_codec IS 'Video stream codec reported by ffprobe (e.g. h264)';
+COMMENT ON COLUMN media.bitrate IS 'Overall bitrate in bits per second (videos)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE media DROP COLUMN IF EXISTS bitrate;
+ALTER TABLE media DROP COLUMN IF EXISTS video_codec;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Transcoded outputs produced by the media job workers
+ALTER TABLE media ADD COLUMN web_key TEXT;
+ALTER TABLE media ADD COLUMN hls_key TEXT;
+
+COMMENT ON COLUMN media.web_key IS 'Key for the normalized H.264/AAC MP4 (videos)';
+COMMENT ON COLUMN media.hls_key IS 'Key for the HLS master playlist (videos)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE media DROP COLUMN IF EXISTS hls_key;
+ALTER TABLE media DROP COLUMN IF EXISTS web_key;
+
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Credits read from a photo's EXIF before its metadata is stripped
+ALTER TABLE media ADD COLUMN copyright TEXT;
+ALTER TABLE media ADD COLUMN artist TEXT;
+
+COMMENT ON COLUMN media.copyright IS 'Copyright notice from the original EXIF (images)';
+COMMENT ON COLUMN media.artist IS 'Artist/photographer from the original EXIF (images)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE media DROP COLUMN IF EXISTS artist;
+ALTER TABLE media DROP COLUMN IF EXISTS copyright;
+
+-- +goose StatementEnd
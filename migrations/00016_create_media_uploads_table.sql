@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Direct-to-storage uploads the browser has been handed presigned URLs for,
+-- but which have not been finalized into a media row yet
+CREATE TABLE media_uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    storage_key TEXT NOT NULL UNIQUE,
+    multipart_upload_id TEXT,
+    original_filename TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    file_size BIGINT NOT NULL,
+    uploaded_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_media_uploads_expires_at ON media_uploads(expires_at);
+
+COMMENT ON TABLE media_uploads IS 'Pending presigned uploads; expired rows and their objects are purged';
+COMMENT ON COLUMN media_uploads.multipart_upload_id IS 'S3 multipart upload ID; NULL for a single presigned PUT';
+COMMENT ON COLUMN media_uploads.mime_type IS 'Type declared by the browser, verified against the content on finalize';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS media_uploads;
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Private media is stored under the private/ prefix and only served through
+-- signed, expiring URLs
+ALTER TABLE media ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN media.is_private IS 'Served only through signed, expiring URLs when true';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE media DROP COLUMN IF EXISTS is_private;
+
+-- +goose StatementEnd
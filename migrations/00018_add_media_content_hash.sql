@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- SHA-256 of the stored original, used to spot the same file being uploaded twice
+ALTER TABLE media ADD COLUMN content_hash TEXT;
+
+CREATE INDEX idx_media_content_hash ON media(content_hash) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN media.content_hash IS 'Hex SHA-256 of the stored original file';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP INDEX IF EXISTS idx_media_content_hash;
+ALTER TABLE media DROP COLUMN IF EXISTS content_hash;
+
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Folders for organizing the media library; collections nest through parent_id
+CREATE TABLE media_collections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id UUID REFERENCES media_collections(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT media_collection_name_not_empty CHECK (trim(name) <> ''),
+    CONSTRAINT media_collection_not_own_parent CHECK (parent_id <> id)
+);
+
+-- Sibling collections need distinct names
+CREATE UNIQUE INDEX idx_media_collections_unique_name
+    ON media_collections(parent_id, lower(name)) NULLS NOT DISTINCT;
+
+-- Media in a deleted collection moves back to the library root
+ALTER TABLE media ADD COLUMN collection_id UUID REFERENCES media_collections(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_media_collection ON media(collection_id) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN media.collection_id IS 'Collection (folder) holding the media; NULL at the library root';
+
+-- Junction table: media <-> tags (many-to-many)
+CREATE TABLE media_tags (
+    media_id UUID NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (media_id, tag_id)
+);
+
+CREATE INDEX idx_media_tags_tag ON media_tags(tag_id);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS media_tags;
+DROP INDEX IF EXISTS idx_media_collection;
+ALTER TABLE media DROP COLUMN IF EXISTS collection_id;
+DROP TABLE IF EXISTS media_collections;
+
+-- +goose StatementEnd
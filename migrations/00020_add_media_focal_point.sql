@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Where the subject of an image is, so crops keep it in frame. Stored as
+-- fractions of the width and height; NULL means the centre.
+ALTER TABLE media
+    ADD COLUMN focal_x REAL CHECK (focal_x BETWEEN 0 AND 1),
+    ADD COLUMN focal_y REAL CHECK (focal_y BETWEEN 0 AND 1),
+    ADD COLUMN hero_key TEXT,
+    ADD COLUMN card_key TEXT,
+    ADD COLUMN og_key TEXT;
+
+COMMENT ON COLUMN media.focal_x IS 'Horizontal focal point as a fraction of the width (0 = left); NULL for the centre';
+COMMENT ON COLUMN media.focal_y IS 'Vertical focal point as a fraction of the height (0 = top); NULL for the centre';
+COMMENT ON COLUMN media.hero_key IS 'Key for the 16:9 hero crop around the focal point (images)';
+COMMENT ON COLUMN media.card_key IS 'Key for the 1:1 card crop around the focal point (images)';
+COMMENT ON COLUMN media.og_key IS 'Key for the 1200x630 Open Graph crop around the focal point (images)';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE media
+    DROP COLUMN IF EXISTS og_key,
+    DROP COLUMN IF EXISTS card_key,
+    DROP COLUMN IF EXISTS hero_key,
+    DROP COLUMN IF EXISTS focal_y,
+    DROP COLUMN IF EXISTS focal_x;
+
+-- +goose StatementEnd
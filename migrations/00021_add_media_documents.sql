@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Metadata read from PDFs when they are processed
+ALTER TABLE media
+    ADD COLUMN page_count INTEGER CHECK (page_count > 0),
+    ADD COLUMN document_title TEXT;
+
+COMMENT ON COLUMN media.page_count IS 'Number of pages (PDFs)';
+COMMENT ON COLUMN media.document_title IS 'Title from the document properties, if it has one (PDFs)';
+
+-- Projects attach PDFs as downloadable case-study documents
+ALTER TABLE media_relations
+    DROP CONSTRAINT media_relations_relation_type_check,
+    ADD CONSTRAINT media_relations_relation_type_check
+        CHECK (relation_type IN ('gallery', 'featured', 'content', 'document'));
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DELETE FROM media_relations WHERE relation_type = 'document';
+
+ALTER TABLE media_relations
+    DROP CONSTRAINT media_relations_relation_type_check,
+    ADD CONSTRAINT media_relations_relation_type_check
+        CHECK (relation_type IN ('gallery', 'featured', 'content'));
+
+ALTER TABLE media
+    DROP COLUMN IF EXISTS document_title,
+    DROP COLUMN IF EXISTS page_count;
+
+-- +goose StatementEnd
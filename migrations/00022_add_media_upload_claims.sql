@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Set while a request finalizes or cancels a direct upload, so concurrent
+-- requests cannot both turn it into media
+ALTER TABLE media_uploads
+    ADD COLUMN claimed_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN media_uploads.claimed_at IS 'When a request claimed the upload to finalize or cancel it; NULL while waiting for the browser';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE media_uploads
+    DROP COLUMN IF EXISTS claimed_at;
+
+-- +goose StatementEnd
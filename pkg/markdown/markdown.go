@@ -0,0 +1,135 @@
+// pkg/markdown/markdown.go
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// MediaScheme is the URL prefix used to reference uploaded media by ID,
+// e.g. ![Site map](media:3f1c...)
+const MediaScheme = "media:"
+
+// MediaResolver looks up a media ID referenced from markdown and returns its
+// public URL and alt text. ok is false when the media does not exist.
+type MediaResolver func(id uuid.UUID) (url string, alt string, ok bool)
+
+// Renderer converts markdown to sanitized HTML
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+}
+
+var languageClass = regexp.MustCompile(`^language-[a-zA-Z0-9_+#-]+$`)
+
+// NewRenderer creates a renderer with GFM tables and an allow-list sanitizer
+func NewRenderer() *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.Table,
+			extension.Strikethrough,
+			extension.Linkify,
+		),
+	)
+
+	// UGCPolicy already covers headings, lists, tables, pre/code, images
+	// and links (http, https, mailto and relative URLs only).
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("class").Matching(languageClass).OnElements("code")
+	policy.AllowStyles("text-align").MatchingEnum("left", "right", "center").OnElements("th", "td")
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+
+	return &Renderer{md: md, policy: policy}
+}
+
+// Render converts markdown source to sanitized HTML. Images pointing at
+// media:<uuid> are rewritten to the media URL via resolve; unresolved
+// references are dropped by the sanitizer.
+func (r *Renderer) Render(source string, resolve MediaResolver) (string, error) {
+	if strings.TrimSpace(source) == "" {
+		return "", nil
+	}
+
+	src := []byte(source)
+	doc := r.md.Parser().Parse(text.NewReader(src))
+
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		img, ok := n.(*ast.Image)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		dest := string(img.Destination)
+		if !strings.HasPrefix(dest, MediaScheme) {
+			return ast.WalkContinue, nil
+		}
+
+		img.Destination = nil
+		id, err := uuid.Parse(strings.TrimPrefix(dest, MediaScheme))
+		if err != nil || resolve == nil {
+			return ast.WalkContinue, nil
+		}
+		url, alt, found := resolve(id)
+		if !found {
+			return ast.WalkContinue, nil
+		}
+		img.Destination = []byte(url)
+
+		// Fall back to the media library alt text when the author left it blank
+		if img.ChildCount() == 0 && alt != "" {
+			img.AppendChild(img, ast.NewString([]byte(alt)))
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve media references: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, src, doc); err != nil {
+		return "", fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	return r.policy.Sanitize(buf.String()), nil
+}
+
+// MediaIDs returns the distinct media IDs that images in source reference
+// with media:<uuid>, in order of first use
+func MediaIDs(source string) []uuid.UUID {
+	src := []byte(source)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(src))
+
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		img, ok := n.(*ast.Image)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		id, err := uuid.Parse(strings.TrimPrefix(string(img.Destination), MediaScheme))
+		if err == nil && strings.HasPrefix(string(img.Destination), MediaScheme) && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return ast.WalkContinue, nil
+	})
+	return ids
+}
+
+var defaultRenderer = NewRenderer()
+
+// Render converts markdown to sanitized HTML using the default renderer
+func Render(source string, resolve MediaResolver) (string, error) {
+	return defaultRenderer.Render(source, resolve)
+}
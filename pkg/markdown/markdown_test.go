@@ -0,0 +1,198 @@
+// pkg/markdown/markdown_test.go
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	mediaID := uuid.MustParse("3f1c2b7a-9d4e-4c1a-8b2f-6e5d4c3b2a10")
+	resolver := func(id uuid.UUID) (string, string, bool) {
+		if id == mediaID {
+			return "/uploads/media/2026/01/02/abcd1234.jpg", "Studio floor plan", true
+		}
+		return "", "", false
+	}
+
+	tests := []struct {
+		name        string
+		source      string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "success: headings are rendered",
+			source:   "# Overview\n\n## Approach",
+			contains: []string{"<h1>Overview</h1>", "<h2>Approach</h2>"},
+		},
+		{
+			name:     "success: fenced code keeps language class",
+			source:   "```go\nfmt.Println(\"hi\")\n```",
+			contains: []string{`<pre><code class="language-go">`},
+		},
+		{
+			name:     "success: tables are rendered with alignment",
+			source:   "| Name | Qty |\n|:-----|----:|\n| Chairs | 4 |",
+			contains: []string{"<table>", "<th style=\"text-align: left\">Name</th>", "<td style=\"text-align: right\">4</td>"},
+		},
+		{
+			name:     "success: media reference resolves to URL with library alt text",
+			source:   "![](media:" + mediaID.String() + ")",
+			contains: []string{`<img src="/uploads/media/2026/01/02/abcd1234.jpg" alt="Studio floor plan"`},
+		},
+		{
+			name:     "success: author alt text wins over library alt text",
+			source:   "![Ground floor](media:" + mediaID.String() + ")",
+			contains: []string{`alt="Ground floor"`},
+		},
+		{
+			name:        "sanitize: unknown media reference is dropped",
+			source:      "![missing](media:" + uuid.NewString() + ")",
+			notContains: []string{"media:", "src="},
+		},
+		{
+			name:        "sanitize: raw html and scripts are stripped",
+			source:      "<script>alert(1)</script>\n\n<img src=x onerror=alert(1)>",
+			notContains: []string{"<script", "onerror"},
+		},
+		{
+			name:        "sanitize: javascript links are stripped",
+			source:      "[click](javascript:alert(1))",
+			notContains: []string{"javascript:"},
+		},
+		{
+			name:        "sanitize: arbitrary code classes are stripped",
+			source:      "```\" onmouseover=\"alert(1)\nx\n```",
+			notContains: []string{"onmouseover"},
+		},
+		{
+			name:   "success: empty source renders nothing",
+			source: "   ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			html, err := Render(tt.source, resolver)
+
+			// Assert
+			require.NoError(t, err)
+			for _, want := range tt.contains {
+				assert.Contains(t, html, want)
+			}
+			for _, unwanted := range tt.notContains {
+				assert.NotContains(t, html, unwanted)
+			}
+			if len(tt.contains) == 0 && len(tt.notContains) == 0 {
+				assert.Empty(t, html)
+			}
+		})
+	}
+}
+
+func TestMediaIDs(t *testing.T) {
+	first := uuid.MustParse("3f1c2b7a-9d4e-4c1a-8b2f-6e5d4c3b2a10")
+	second := uuid.MustParse("8a7b6c5d-4e3f-4a1b-9c2d-1e0f9a8b7c6d")
+
+	tests := []struct {
+		name   string
+		source string
+		want   []uuid.UUID
+	}{
+		{
+			name:   "success: references in order of first use",
+			source: "![a](media:" + second.String() + ")\n\n![b](media:" + first.String() + ")\n\n![c](media:" + second.String() + ")",
+			want:   []uuid.UUID{second, first},
+		},
+		{
+			name:   "edge: links, external images and bad IDs are ignored",
+			source: "[doc](media:" + first.String() + ") ![x](https://example.com/x.png) ![y](media:not-a-uuid)",
+			want:   nil,
+		},
+		{
+			name:   "edge: code blocks are not references",
+			source: "```\n![a](media:" + first.String() + ")\n```",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := MediaIDs(tt.source)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name            string
+		source          string
+		excerptLen      int
+		wantWords       int
+		wantReadingTime int
+		wantExcerpt     string
+	}{
+		{
+			name:            "success: empty body has no metrics",
+			source:          "",
+			excerptLen:      100,
+			wantWords:       0,
+			wantReadingTime: 0,
+			wantExcerpt:     "",
+		},
+		{
+			name:            "success: short body rounds up to one minute",
+			source:          "# Hello\n\nA **short** post.",
+			excerptLen:      100,
+			wantWords:       4,
+			wantReadingTime: 1,
+			wantExcerpt:     "Hello A short post.",
+		},
+		{
+			name:            "success: code blocks and images are not counted",
+			source:          "Intro text\n\n```go\nfunc main() {}\n```\n\n![diagram](media:3f1c2b7a-9d4e-4c1a-8b2f-6e5d4c3b2a10)",
+			excerptLen:      100,
+			wantWords:       2,
+			wantReadingTime: 1,
+			wantExcerpt:     "Intro text",
+		},
+		{
+			name:            "success: reading time uses words per minute",
+			source:          strings.Repeat("word ", WordsPerMinute*3+1),
+			excerptLen:      0,
+			wantWords:       WordsPerMinute*3 + 1,
+			wantReadingTime: 4,
+			wantExcerpt:     strings.TrimSpace(strings.Repeat("word ", WordsPerMinute*3+1)),
+		},
+		{
+			name:            "success: excerpt is cut on a word boundary",
+			source:          "The quick brown fox jumps over the lazy dog.",
+			excerptLen:      18,
+			wantWords:       9,
+			wantReadingTime: 1,
+			wantExcerpt:     "The quick brown…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			summary := Summarize(tt.source, tt.excerptLen)
+
+			// Assert
+			assert.Equal(t, tt.wantWords, summary.WordCount)
+			assert.Equal(t, tt.wantReadingTime, summary.ReadingTime)
+			assert.Equal(t, tt.wantExcerpt, summary.Excerpt)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// pkg/markdown/summary.go
+package markdown
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// WordsPerMinute is the reading speed used for ReadingTime estimates
+const WordsPerMinute = 200
+
+// Summary holds metrics derived from a markdown body
+type Summary struct {
+	WordCount   int
+	ReadingTime int // minutes, rounded up; 0 for an empty body
+	Excerpt     string
+}
+
+// Summarize counts the words in a markdown body, estimates its reading time
+// and builds a plain-text excerpt of at most excerptLen characters. Code
+// blocks, images and raw HTML are ignored.
+func Summarize(source string, excerptLen int) Summary {
+	plain := PlainText(source)
+	words := strings.Fields(plain)
+
+	summary := Summary{WordCount: len(words)}
+	if summary.WordCount > 0 {
+		summary.ReadingTime = (summary.WordCount + WordsPerMinute - 1) / WordsPerMinute
+	}
+	summary.Excerpt = truncateWords(strings.Join(words, " "), excerptLen)
+
+	return summary
+}
+
+// PlainText extracts the readable prose from a markdown body
+func PlainText(source string) string {
+	if strings.TrimSpace(source) == "" {
+		return ""
+	}
+
+	src := []byte(source)
+	doc := defaultRenderer.md.Parser().Parse(text.NewReader(src))
+
+	var b strings.Builder
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		switch node := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML, *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering {
+				b.Write(node.Segment.Value(src))
+				if node.SoftLineBreak() || node.HardLineBreak() {
+					b.WriteByte(' ')
+				}
+			}
+		case *ast.String:
+			if entering {
+				b.Write(node.Value)
+			}
+		case *ast.AutoLink:
+			if entering {
+				b.Write(node.URL(src))
+			}
+		default:
+			// Separate block-level elements so words don't run together
+			if !entering && n.Type() == ast.TypeBlock {
+				b.WriteByte(' ')
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(b.String())
+}
+
+// truncateWords shortens s to at most max characters on a word boundary
+func truncateWords(s string, max int) string {
+	if max <= 0 || len([]rune(s)) <= max {
+		return s
+	}
+
+	runes := []rune(s)[:max]
+	cut := strings.LastIndexFunc(string(runes), unicode.IsSpace)
+	if cut <= 0 {
+		return strings.TrimRightFunc(string(runes), unicode.IsPunct) + "…"
+	}
+
+	return strings.TrimRightFunc(string(runes)[:cut], unicode.IsPunct) + "…"
+}
@@ -0,0 +1,146 @@
+-- Blogs CRUD Operations
+
+-- name: CreateBlog :one
+INSERT INTO blogs (
+    id,
+    title,
+    slug,
+    excerpt,
+    body,
+    status,
+    reading_time,
+    word_count,
+    is_featured,
+    featured_image_id,
+    author_id,
+    created_at,
+    updated_at
+) VALUES (
+    @id,
+    @title,
+    @slug,
+    @excerpt,
+    @body,
+    @status,
+    @reading_time,
+    @word_count,
+    @is_featured,
+    @featured_image_id,
+    @author_id,
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: GetBlogByID :one
+SELECT * FROM blogs
+WHERE id = @id AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetBlogBySlug :one
+SELECT * FROM blogs
+WHERE slug = @slug AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetBlogIDBySlug :one
+SELECT id FROM blogs
+WHERE slug = @slug AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: ListBlogs :many
+SELECT * FROM blogs
+WHERE deleted_at IS NULL
+ORDER BY created_at DESC
+LIMIT @limit_val
+OFFSET @offset_val;
+
+-- name: ListPublishedBlogs :many
+SELECT * FROM blogs
+WHERE status = 'published'
+  AND deleted_at IS NULL
+ORDER BY published_at DESC
+LIMIT @limit_val
+OFFSET @offset_val;
+
+-- name: UpdateBlog :one
+UPDATE blogs SET
+    title             = COALESCE(sqlc.narg('title'), title),
+    slug              = COALESCE(sqlc.narg('slug'), slug),
+    excerpt           = sqlc.narg('excerpt'),
+    body              = COALESCE(sqlc.narg('body'), body),
+    status            = COALESCE(sqlc.narg('status'), status),
+    reading_time      = COALESCE(sqlc.narg('reading_time'), reading_time),
+    word_count        = COALESCE(sqlc.narg('word_count'), word_count),
+    is_featured       = COALESCE(sqlc.narg('is_featured'), is_featured),
+    featured_image_id = sqlc.narg('featured_image_id'),
+    updated_at        = NOW()
+WHERE id = @id
+  AND deleted_at IS NULL
+RETURNING *;
+
+-- name: PublishBlog :one
+UPDATE blogs SET
+    status = 'published',
+    published_at = NOW(),
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: UnpublishBlog :one
+UPDATE blogs SET
+    status = 'draft',
+    published_at = NULL,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: SoftDeleteBlog :exec
+UPDATE blogs
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: CountBlogs :one
+SELECT COUNT(*) FROM blogs
+WHERE deleted_at IS NULL;
+
+-- name: CountPublishedBlogs :one
+SELECT COUNT(*) FROM blogs
+WHERE status = 'published'
+  AND deleted_at IS NULL
+  AND published_at IS NOT NULL;
+
+-- name: CheckBlogSlugExists :one
+SELECT EXISTS(
+    SELECT 1 FROM blogs
+    WHERE slug = @slug
+      AND id IS DISTINCT FROM @blog_id
+      AND deleted_at IS NULL
+);
+
+-- Blog Tags Operations
+
+-- name: AddBlogTag :one
+INSERT INTO blog_tags (blog_id, tag_id, created_at)
+VALUES (@blog_id, @tag_id, NOW())
+ON CONFLICT (blog_id, tag_id) DO NOTHING
+RETURNING *;
+
+-- name: GetBlogTags :many
+SELECT t.* FROM tags t
+JOIN blog_tags bt ON t.id = bt.tag_id
+WHERE bt.blog_id = @blog_id
+ORDER BY t.name ASC;
+
+-- name: ClearBlogTags :exec
+DELETE FROM blog_tags
+WHERE blog_id = @blog_id;
+
+-- Blog Featured Image Operations (via media_relations)
+
+-- name: DeleteFeaturedMediaForEntity :exec
+DELETE FROM media_relations
+WHERE entity_type   = @entity_type
+  AND entity_id     = @entity_id
+  AND relation_type = 'featured';
@@ -19,6 +19,10 @@ INSERT INTO media (
     thumbnail_key,
     alt_text,
     uploaded_by,
+    processing_status,
+    copyright,
+    artist,
+    content_hash,
     created_at,
     updated_at
 ) VALUES (
@@ -39,6 +43,10 @@ INSERT INTO media (
     @thumbnail_key,
     @alt_text,
     @uploaded_by,
+    @processing_status,
+    @copyright,
+    @artist,
+    @content_hash,
     NOW(),
     NOW()
 )
@@ -122,10 +130,15 @@ ORDER BY deleted_at DESC
 LIMIT @limit_val
 OFFSET @offset_val;
 
--- name: PurgeOldDeletedMedia :exec
-DELETE FROM media
-WHERE deleted_at IS NOT NULL
-  AND deleted_at < NOW() - INTERVAL '30 days';
+-- name: CountDeletedMedia :one
+SELECT COUNT(*) FROM media
+WHERE deleted_at IS NOT NULL;
+
+-- name: ListMediaDeletedBefore :many
+SELECT * FROM media
+WHERE deleted_at < @deleted_before
+ORDER BY deleted_at ASC
+LIMIT @limit_val;
 
 -- name: CountMedia :one
 SELECT COUNT(*) FROM media
@@ -214,11 +227,16 @@ FROM media
 WHERE deleted_at IS NULL;
 
 -- name: GetOrphanedMedia :many
+-- Media no content refers to, whether through media_relations, an image column
+-- or a media:<uuid> embedded in a markdown body
 SELECT m.* FROM media m
-LEFT JOIN media_relations mr ON m.id = mr.media_id
-WHERE mr.id IS NULL
-  AND m.deleted_at IS NULL
+WHERE m.deleted_at IS NULL
   AND m.created_at < NOW() - INTERVAL '1 day'
+  AND NOT EXISTS (SELECT 1 FROM media_relations mr WHERE mr.media_id = m.id)
+  AND NOT EXISTS (SELECT 1 FROM projects p WHERE p.featured_image_id = m.id OR p.body ~* ('media:' || m.id::TEXT))
+  AND NOT EXISTS (SELECT 1 FROM blogs b WHERE b.featured_image_id = m.id OR b.body ~* ('media:' || m.id::TEXT))
+  AND NOT EXISTS (SELECT 1 FROM pages pg WHERE pg.hero_media_id = m.id OR pg.content_image_id = m.id)
+  AND NOT EXISTS (SELECT 1 FROM seo WHERE seo.og_image_id = m.id)
 ORDER BY m.created_at DESC;
 
 -- name: GetMediaUsageByEntity :many
@@ -233,6 +251,149 @@ GROUP BY mr.entity_type, mr.entity_id
 ORDER BY media_count DESC
 LIMIT @limit_val;
 
+-- Media Usage
+
+-- name: ListMediaReferences :many
+-- Every live project, blog or page that uses the media, and in which field.
+-- Markdown bodies embed media as media:<uuid>.
+SELECT
+    r.entity_type,
+    r.entity_id,
+    r.field,
+    COALESCE(p.title, b.title, pg.title, '')::TEXT AS title,
+    COALESCE(p.slug, b.slug, pg.slug, '')::TEXT AS slug
+FROM (
+    SELECT mr.entity_type, mr.entity_id, mr.relation_type AS field
+    FROM media_relations mr WHERE mr.media_id = @media_id
+    UNION
+    SELECT 'project', id, 'featured' FROM projects WHERE featured_image_id = @media_id
+    UNION
+    SELECT 'blog', id, 'featured' FROM blogs WHERE featured_image_id = @media_id
+    UNION
+    SELECT 'page', id, 'hero' FROM pages WHERE hero_media_id = @media_id
+    UNION
+    SELECT 'page', id, 'content_image' FROM pages WHERE content_image_id = @media_id
+    UNION
+    SELECT seo.entity_type, seo.entity_id, 'og_image' FROM seo WHERE og_image_id = @media_id
+    UNION
+    SELECT 'project', id, 'body' FROM projects WHERE body ~* ('media:' || @media_id::uuid::TEXT)
+    UNION
+    SELECT 'blog', id, 'body' FROM blogs WHERE body ~* ('media:' || @media_id::uuid::TEXT)
+) r
+LEFT JOIN projects p ON r.entity_type = 'project' AND p.id = r.entity_id
+LEFT JOIN blogs b ON r.entity_type = 'blog' AND b.id = r.entity_id
+LEFT JOIN pages pg ON r.entity_type = 'page' AND pg.id = r.entity_id
+WHERE COALESCE(p.deleted_at, b.deleted_at, pg.deleted_at) IS NULL
+ORDER BY r.entity_type, title, r.field;
+
+-- name: ListPublishedImageReferences :many
+-- Images shown by published projects, blog posts and pages, including those
+-- embedded in markdown bodies, and as their social share images, optionally
+-- for one media item
+SELECT
+    r.media_id,
+    r.entity_type,
+    r.entity_id,
+    r.field,
+    r.title,
+    r.slug
+FROM (
+    SELECT p.featured_image_id AS media_id, 'project'::TEXT AS entity_type, p.id AS entity_id, 'featured'::TEXT AS field, p.title, p.slug
+    FROM projects p
+    WHERE p.status = 'published' AND p.deleted_at IS NULL
+    UNION
+    SELECT mr.media_id, 'project', p.id, 'gallery', p.title, p.slug
+    FROM media_relations mr
+    JOIN projects p ON mr.entity_type = 'project' AND p.id = mr.entity_id
+    WHERE mr.relation_type = 'gallery' AND p.status = 'published' AND p.deleted_at IS NULL
+    UNION
+    SELECT bm.id, 'project', p.id, 'body', p.title, p.slug
+    FROM projects p
+    JOIN media bm ON p.body ~* ('media:' || bm.id::TEXT)
+    WHERE p.status = 'published' AND p.deleted_at IS NULL
+    UNION
+    SELECT bm.id, 'blog', b.id, 'body', b.title, b.slug
+    FROM blogs b
+    JOIN media bm ON b.body ~* ('media:' || bm.id::TEXT)
+    WHERE b.status = 'published' AND b.deleted_at IS NULL
+    UNION
+    SELECT pg.hero_media_id, 'page', pg.id, 'hero', pg.title, pg.slug
+    FROM pages pg WHERE pg.deleted_at IS NULL
+    UNION
+    SELECT pg.content_image_id, 'page', pg.id, 'content_image', pg.title, pg.slug
+    FROM pages pg WHERE pg.deleted_at IS NULL
+    UNION
+    SELECT seo.og_image_id, seo.entity_type, seo.entity_id, 'og_image',
+        COALESCE(p.title, b.title, pg.title)::TEXT, COALESCE(p.slug, b.slug, pg.slug)::TEXT
+    FROM seo
+    LEFT JOIN projects p ON seo.entity_type = 'project' AND p.id = seo.entity_id
+        AND p.status = 'published' AND p.deleted_at IS NULL
+    LEFT JOIN blogs b ON seo.entity_type = 'blog' AND b.id = seo.entity_id
+        AND b.status = 'published' AND b.deleted_at IS NULL
+    LEFT JOIN pages pg ON seo.entity_type = 'page' AND pg.id = seo.entity_id
+        AND pg.deleted_at IS NULL
+    WHERE COALESCE(p.id, b.id, pg.id) IS NOT NULL
+) r
+JOIN media m ON m.id = r.media_id
+WHERE m.deleted_at IS NULL
+  AND m.mime_type LIKE 'image/%'
+  AND (sqlc.narg('media_id')::uuid IS NULL OR m.id = sqlc.narg('media_id')::uuid)
+ORDER BY r.entity_type, r.title, r.field;
+
+-- name: DeleteMediaRelationsSharedWith :exec
+-- Relations the replacement already has would violate the unique constraint
+DELETE FROM media_relations mr
+WHERE mr.media_id = @old_media_id
+  AND EXISTS (
+    SELECT 1 FROM media_relations x
+    WHERE x.media_id = @new_media_id
+      AND x.entity_type = mr.entity_type
+      AND x.entity_id = mr.entity_id
+      AND x.relation_type = mr.relation_type
+  );
+
+-- name: ReplaceMediaInRelations :exec
+UPDATE media_relations
+SET media_id = @new_media_id
+WHERE media_id = @old_media_id;
+
+-- name: ReplaceProjectFeaturedImage :exec
+UPDATE projects
+SET featured_image_id = @new_media_id, updated_at = NOW()
+WHERE featured_image_id = @old_media_id;
+
+-- name: ReplaceBlogFeaturedImage :exec
+UPDATE blogs
+SET featured_image_id = @new_media_id, updated_at = NOW()
+WHERE featured_image_id = @old_media_id;
+
+-- name: ReplacePageHeroMedia :exec
+UPDATE pages
+SET hero_media_id = @new_media_id, updated_at = NOW()
+WHERE hero_media_id = @old_media_id;
+
+-- name: ReplacePageContentImage :exec
+UPDATE pages
+SET content_image_id = @new_media_id, updated_at = NOW()
+WHERE content_image_id = @old_media_id;
+
+-- name: ReplaceProjectBodyMedia :exec
+-- Rewrites media:<uuid> embedded in markdown bodies
+UPDATE projects
+SET body = regexp_replace(body, 'media:' || @old_media_id::uuid::TEXT, 'media:' || @new_media_id::uuid::TEXT, 'gi'), updated_at = NOW()
+WHERE body ~* ('media:' || @old_media_id::uuid::TEXT);
+
+-- name: ReplaceBlogBodyMedia :exec
+-- Rewrites media:<uuid> embedded in markdown bodies
+UPDATE blogs
+SET body = regexp_replace(body, 'media:' || @old_media_id::uuid::TEXT, 'media:' || @new_media_id::uuid::TEXT, 'gi'), updated_at = NOW()
+WHERE body ~* ('media:' || @old_media_id::uuid::TEXT);
+
+-- name: ReplaceSeoOgImage :exec
+UPDATE seo
+SET og_image_id = @new_media_id, updated_at = NOW()
+WHERE og_image_id = @old_media_id;
+
 -- Batch operations
 
 -- name: BatchCreateMediaRelations :copyfrom
@@ -246,3 +407,291 @@ INSERT INTO media_relations (
 ) VALUES (
     $1, $2, $3, $4, $5, $6
 );
+
+-- Media Processing
+
+-- name: UpdateMediaProcessingStatus :exec
+UPDATE media
+SET
+    processing_status = @processing_status,
+    processing_error = @processing_error,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: UpdateMediaRenditions :one
+UPDATE media
+SET
+    width = COALESCE(sqlc.narg('width'), width),
+    height = COALESCE(sqlc.narg('height'), height),
+    large_key = COALESCE(sqlc.narg('large_key'), large_key),
+    medium_key = COALESCE(sqlc.narg('medium_key'), medium_key),
+    thumbnail_key = COALESCE(sqlc.narg('thumbnail_key'), thumbnail_key),
+    web_key = COALESCE(sqlc.narg('web_key'), web_key),
+    hls_key = COALESCE(sqlc.narg('hls_key'), hls_key),
+    hero_key = COALESCE(sqlc.narg('hero_key'), hero_key),
+    card_key = COALESCE(sqlc.narg('card_key'), card_key),
+    og_key = COALESCE(sqlc.narg('og_key'), og_key),
+    processing_status = 'ready',
+    processing_error = NULL,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: UpdateMediaMetadata :exec
+UPDATE media
+SET
+    width = COALESCE(sqlc.narg('width'), width),
+    height = COALESCE(sqlc.narg('height'), height),
+    duration = COALESCE(sqlc.narg('duration'), duration),
+    video_codec = COALESCE(sqlc.narg('video_codec'), video_codec),
+    bitrate = COALESCE(sqlc.narg('bitrate'), bitrate),
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: UpdateMediaDocumentMetadata :exec
+UPDATE media
+SET
+    page_count = @page_count,
+    document_title = @document_title,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: SetMediaFocalPoint :one
+-- The crops are regenerated around the new point by a background job
+UPDATE media
+SET
+    focal_x = sqlc.narg('focal_x'),
+    focal_y = sqlc.narg('focal_y'),
+    processing_status = 'pending',
+    processing_error = NULL,
+    updated_at = NOW()
+WHERE id = @id AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateMediaCrops :one
+UPDATE media
+SET
+    hero_key = @hero_key,
+    card_key = @card_key,
+    og_key = @og_key,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: UpdateMediaVisibility :one
+-- Keys move with visibility: private objects live under a separate prefix
+UPDATE media
+SET
+    is_private = @is_private,
+    original_key = @original_key,
+    large_key = @large_key,
+    medium_key = @medium_key,
+    thumbnail_key = @thumbnail_key,
+    web_key = @web_key,
+    hls_key = @hls_key,
+    hero_key = @hero_key,
+    card_key = @card_key,
+    og_key = @og_key,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- Storage Migration
+
+-- name: ListMediaForStorageMigration :many
+-- Includes soft-deleted media, whose files are kept until it is purged
+SELECT * FROM media
+WHERE id > @after_id
+ORDER BY id
+LIMIT @limit_val;
+
+-- name: GetMediaByIDIncludingDeleted :one
+SELECT * FROM media
+WHERE id = @id
+LIMIT 1;
+
+-- name: GetMediaForUpdate :one
+SELECT * FROM media
+WHERE id = @id
+FOR UPDATE;
+
+-- name: UpdateMediaStorageLocation :exec
+UPDATE media
+SET
+    storage_type = @storage_type,
+    s3_bucket = @s3_bucket,
+    s3_region = @s3_region,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- Storage Integrity
+
+-- name: ListMediaStorageKeys :many
+-- Every row, including soft-deleted media whose files are kept until purged
+SELECT
+    id,
+    storage_type,
+    s3_bucket,
+    original_key,
+    large_key,
+    medium_key,
+    thumbnail_key,
+    web_key,
+    hls_key,
+    hero_key,
+    card_key,
+    og_key
+FROM media
+ORDER BY id;
+
+-- Deduplication
+
+-- name: GetMediaByContentHash :one
+-- The oldest live copy of a file, offered instead of storing it again
+SELECT * FROM media
+WHERE content_hash = @content_hash
+  AND deleted_at IS NULL
+ORDER BY created_at ASC
+LIMIT 1;
+
+-- name: ListMediaWithoutContentHash :many
+-- Includes soft-deleted media so it can be matched once restored
+SELECT * FROM media
+WHERE content_hash IS NULL
+  AND original_key IS NOT NULL
+  AND id > @after_id
+ORDER BY id
+LIMIT @limit_val;
+
+-- name: UpdateMediaContentHash :exec
+UPDATE media
+SET content_hash = @content_hash
+WHERE id = @id;
+
+-- Organization
+
+-- name: FilterMedia :many
+-- Every filter is optional; a NULL argument matches all media. A collection
+-- matches its nested collections too.
+SELECT * FROM media
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('collection_id')::uuid IS NULL OR collection_id IN (
+        WITH RECURSIVE descendants AS (
+            SELECT mc.id FROM media_collections mc WHERE mc.id = sqlc.narg('collection_id')
+            UNION ALL
+            SELECT mc.id FROM media_collections mc
+            JOIN descendants d ON mc.parent_id = d.id
+        )
+        SELECT id FROM descendants
+      ))
+  AND (sqlc.narg('tag_id')::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM media_tags mt
+        WHERE mt.media_id = media.id AND mt.tag_id = sqlc.narg('tag_id')
+      ))
+  AND (sqlc.narg('mime_type_pattern')::text IS NULL OR mime_type LIKE sqlc.narg('mime_type_pattern'))
+  AND (sqlc.narg('uploaded_by')::uuid IS NULL OR uploaded_by = sqlc.narg('uploaded_by'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'))
+ORDER BY created_at DESC
+LIMIT @limit_val
+OFFSET @offset_val;
+
+-- name: CountFilteredMedia :one
+SELECT COUNT(*) FROM media
+WHERE deleted_at IS NULL
+  AND (sqlc.narg('collection_id')::uuid IS NULL OR collection_id IN (
+        WITH RECURSIVE descendants AS (
+            SELECT mc.id FROM media_collections mc WHERE mc.id = sqlc.narg('collection_id')
+            UNION ALL
+            SELECT mc.id FROM media_collections mc
+            JOIN descendants d ON mc.parent_id = d.id
+        )
+        SELECT id FROM descendants
+      ))
+  AND (sqlc.narg('tag_id')::uuid IS NULL OR EXISTS (
+        SELECT 1 FROM media_tags mt
+        WHERE mt.media_id = media.id AND mt.tag_id = sqlc.narg('tag_id')
+      ))
+  AND (sqlc.narg('mime_type_pattern')::text IS NULL OR mime_type LIKE sqlc.narg('mime_type_pattern'))
+  AND (sqlc.narg('uploaded_by')::uuid IS NULL OR uploaded_by = sqlc.narg('uploaded_by'))
+  AND (sqlc.narg('created_from')::timestamptz IS NULL OR created_at >= sqlc.narg('created_from'))
+  AND (sqlc.narg('created_before')::timestamptz IS NULL OR created_at < sqlc.narg('created_before'));
+
+-- name: SetMediaCollection :one
+UPDATE media
+SET
+    collection_id = @collection_id,
+    updated_at = NOW()
+WHERE id = @id
+  AND deleted_at IS NULL
+RETURNING *;
+
+-- name: ListMediaUploaders :many
+SELECT DISTINCT u.id, u.first_name, u.last_name
+FROM users u
+JOIN media m ON m.uploaded_by = u.id
+WHERE m.deleted_at IS NULL
+ORDER BY u.first_name, u.last_name;
+
+-- name: GetMediaTags :many
+SELECT t.* FROM tags t
+JOIN media_tags mt ON t.id = mt.tag_id
+WHERE mt.media_id = @media_id
+ORDER BY t.name ASC;
+
+-- name: AddMediaTag :exec
+INSERT INTO media_tags (media_id, tag_id, created_at)
+VALUES (@media_id, @tag_id, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: ClearMediaTags :exec
+DELETE FROM media_tags
+WHERE media_id = @media_id;
+
+-- name: ListMediaTagsInUse :many
+-- Tags on live media, offered as library filters
+SELECT DISTINCT t.* FROM tags t
+JOIN media_tags mt ON t.id = mt.tag_id
+JOIN media m ON m.id = mt.media_id
+WHERE m.deleted_at IS NULL
+ORDER BY t.name ASC;
+
+-- Bulk Operations
+
+-- name: GetMediaByIDs :many
+SELECT * FROM media
+WHERE id = ANY(@ids::uuid[])
+  AND deleted_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: SoftDeleteMediaBatch :many
+UPDATE media
+SET
+    deleted_at = NOW(),
+    updated_at = NOW()
+WHERE id = ANY(@ids::uuid[])
+  AND deleted_at IS NULL
+RETURNING id;
+
+-- name: RestoreMediaBatch :many
+UPDATE media
+SET
+    deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = ANY(@ids::uuid[])
+  AND deleted_at IS NOT NULL
+RETURNING id;
+
+-- name: MoveMediaToCollection :many
+UPDATE media
+SET
+    collection_id = @collection_id,
+    updated_at = NOW()
+WHERE id = ANY(@ids::uuid[])
+  AND deleted_at IS NULL
+RETURNING id;
+
+-- name: RemoveMediaTagBatch :exec
+DELETE FROM media_tags
+WHERE tag_id = @tag_id
+  AND media_id = ANY(@ids::uuid[]);
@@ -0,0 +1,42 @@
+-- Media Collections
+
+-- name: CreateMediaCollection :one
+INSERT INTO media_collections (
+    id,
+    parent_id,
+    name,
+    created_at,
+    updated_at
+) VALUES (
+    @id,
+    @parent_id,
+    @name,
+    NOW(),
+    NOW()
+)
+RETURNING *;
+
+-- name: GetMediaCollectionByID :one
+SELECT * FROM media_collections
+WHERE id = @id
+LIMIT 1;
+
+-- name: ListMediaCollections :many
+SELECT c.*, COUNT(m.id) AS media_count
+FROM media_collections c
+LEFT JOIN media m ON m.collection_id = c.id AND m.deleted_at IS NULL
+GROUP BY c.id
+ORDER BY lower(c.name) ASC;
+
+-- name: RenameMediaCollection :one
+UPDATE media_collections
+SET
+    name = @name,
+    updated_at = NOW()
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteMediaCollection :exec
+-- Nested collections go with it; their media moves to the library root
+DELETE FROM media_collections
+WHERE id = @id;
@@ -0,0 +1,78 @@
+-- sql/queries/media_jobs.sql
+
+-- name: CreateMediaJob :one
+INSERT INTO media_jobs (
+    id,
+    media_id,
+    job_type,
+    max_attempts
+) VALUES (
+    @id,
+    @media_id,
+    @job_type,
+    @max_attempts
+)
+RETURNING *;
+
+-- name: ClaimMediaJob :one
+-- Claims the next runnable job. Jobs stuck in 'running' past the lock
+-- timeout (e.g. the worker crashed) are picked up again.
+UPDATE media_jobs
+SET
+    status = 'running',
+    attempts = attempts + 1,
+    locked_at = NOW(),
+    updated_at = NOW()
+WHERE id = (
+    SELECT id FROM media_jobs
+    WHERE (status = 'pending' AND run_at <= NOW())
+       OR (status = 'running' AND locked_at < NOW() - INTERVAL '2 hours')
+    ORDER BY run_at ASC
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: CompleteMediaJob :exec
+UPDATE media_jobs
+SET
+    status = 'completed',
+    locked_at = NULL,
+    last_error = NULL,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: RetryMediaJob :exec
+UPDATE media_jobs
+SET
+    status = 'pending',
+    run_at = @run_at,
+    locked_at = NULL,
+    last_error = @last_error,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: ReleaseMediaJob :exec
+-- Returns a job interrupted by shutdown to the queue. The interrupted
+-- attempt is not counted.
+UPDATE media_jobs
+SET
+    status = 'pending',
+    attempts = GREATEST(attempts - 1, 0),
+    locked_at = NULL,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: FailMediaJob :exec
+UPDATE media_jobs
+SET
+    status = 'failed',
+    locked_at = NULL,
+    last_error = @last_error,
+    updated_at = NOW()
+WHERE id = @id;
+
+-- name: PurgeCompletedMediaJobs :exec
+DELETE FROM media_jobs
+WHERE status = 'completed'
+  AND updated_at < NOW() - INTERVAL '7 days';
@@ -0,0 +1,68 @@
+-- sql/queries/media_uploads.sql
+
+-- name: CreateMediaUpload :one
+INSERT INTO media_uploads (
+    id,
+    storage_key,
+    multipart_upload_id,
+    original_filename,
+    mime_type,
+    file_size,
+    uploaded_by,
+    expires_at
+) VALUES (
+    @id,
+    @storage_key,
+    @multipart_upload_id,
+    @original_filename,
+    @mime_type,
+    @file_size,
+    @uploaded_by,
+    @expires_at
+)
+RETURNING *;
+
+-- name: GetMediaUpload :one
+-- Only the uploader can finalize their upload, and only before it expires
+SELECT * FROM media_uploads
+WHERE id = @id
+  AND uploaded_by = @uploaded_by
+  AND expires_at > NOW();
+
+-- name: ClaimMediaUpload :one
+-- Claims the uploader's pending upload so only one request finalizes or
+-- cancels it. A claim older than an hour (the server stopped mid-finalize)
+-- can be taken again.
+UPDATE media_uploads
+SET claimed_at = NOW()
+WHERE id = @id
+  AND uploaded_by = @uploaded_by
+  AND expires_at > NOW()
+  AND (claimed_at IS NULL OR claimed_at < NOW() - INTERVAL '1 hour')
+RETURNING *;
+
+-- name: ReleaseMediaUpload :exec
+-- Lets the upload be finalized again, e.g. once it turned out to be a duplicate
+UPDATE media_uploads
+SET claimed_at = NULL
+WHERE id = @id;
+
+-- name: DeleteMediaUpload :exec
+DELETE FROM media_uploads
+WHERE id = @id;
+
+-- name: ListExpiredMediaUploads :many
+SELECT * FROM media_uploads
+WHERE expires_at <= NOW()
+  AND (claimed_at IS NULL OR claimed_at < NOW() - INTERVAL '1 hour')
+ORDER BY expires_at ASC
+LIMIT 500;
+
+-- name: ListMediaUploadKeys :many
+SELECT storage_key FROM media_uploads;
+
+-- name: ClearMediaUploadMultipart :exec
+-- The multipart upload was completed; there is nothing left to abort
+UPDATE media_uploads
+SET multipart_upload_id = NULL
+WHERE id = @id;
@@ -6,6 +6,9 @@ INSERT INTO projects (
     title,
     slug,
     description,
+    body,
+    word_count,
+    reading_time,
     project_date,
     status,
     client_name,
@@ -21,6 +24,9 @@ INSERT INTO projects (
     @title,
     @slug,
     @description,
+    @body,
+    @word_count,
+    @reading_time,
     @project_date,
     @status,
     @client_name,
@@ -68,7 +74,10 @@ OFFSET @offset_val;
 UPDATE projects SET
     title             = COALESCE(sqlc.narg('title'), title),
     slug              = COALESCE(sqlc.narg('slug'), slug),
-    description       = COALESCE(sqlc.narg('description'), description),
+    description       = sqlc.narg('description'),
+    body              = COALESCE(sqlc.narg('body'), body),
+    word_count        = COALESCE(sqlc.narg('word_count'), word_count),
+    reading_time      = COALESCE(sqlc.narg('reading_time'), reading_time),
     project_date      = COALESCE(sqlc.narg('project_date'), project_date),
     status            = COALESCE(sqlc.narg('status'), status),
     client_name       = COALESCE(sqlc.narg('client_name'), client_name),
@@ -129,7 +138,7 @@ WHERE status = 'published'
 SELECT EXISTS(
     SELECT 1 FROM projects 
     WHERE slug = @slug 
-      AND id != @project_id
+      AND id IS DISTINCT FROM @project_id
       AND deleted_at IS NULL
 );
 
@@ -194,6 +203,27 @@ VALUES (@media_id, @entity_type, @entity_id, 'gallery', @sort_order)
 ON CONFLICT (media_id, entity_type, entity_id, relation_type) DO UPDATE
     SET sort_order = EXCLUDED.sort_order;
 
+-- name: GetProjectDocuments :many
+SELECT m.* FROM media m
+JOIN media_relations mr ON m.id = mr.media_id
+WHERE mr.entity_type = 'project'
+  AND mr.entity_id = @project_id
+  AND mr.relation_type = 'document'
+  AND m.deleted_at IS NULL
+ORDER BY mr.sort_order ASC, m.created_at DESC;
+
+-- name: DeleteDocumentMediaForEntity :exec
+DELETE FROM media_relations
+WHERE entity_type   = @entity_type
+  AND entity_id     = @entity_id
+  AND relation_type = 'document';
+
+-- name: AddDocumentMedia :exec
+INSERT INTO media_relations (media_id, entity_type, entity_id, relation_type, sort_order)
+VALUES (@media_id, @entity_type, @entity_id, 'document', @sort_order)
+ON CONFLICT (media_id, entity_type, entity_id, relation_type) DO UPDATE
+    SET sort_order = EXCLUDED.sort_order;
+
 -- Search and Filter Operations
 
 -- name: SearchProjects :many
@@ -60,7 +60,7 @@ SELECT COUNT(*) FROM tags;
 SELECT EXISTS(
     SELECT 1 FROM tags 
     WHERE slug = @slug 
-      AND id != @tag_id
+      AND id IS DISTINCT FROM @tag_id
 );
 
 -- name: SearchTags :many
@@ -73,16 +73,18 @@ OFFSET @offset_val;
 -- Tag Usage Statistics
 
 -- name: GetTagUsageCount :one
-SELECT COUNT(*) FROM project_tags
-WHERE tag_id = @tag_id;
+SELECT
+    (SELECT COUNT(*) FROM project_tags pt WHERE pt.tag_id = @tag_id)
+    + (SELECT COUNT(*) FROM blog_tags bt WHERE bt.tag_id = @tag_id) AS usage_count;
 
 -- name: GetMostUsedTags :many
 SELECT 
     t.*,
-    COUNT(pt.project_id) as usage_count
+    (
+        (SELECT COUNT(*) FROM project_tags pt WHERE pt.tag_id = t.id)
+        + (SELECT COUNT(*) FROM blog_tags bt WHERE bt.tag_id = t.id)
+    )::BIGINT as usage_count
 FROM tags t
-LEFT JOIN project_tags pt ON t.id = pt.tag_id
-GROUP BY t.id
 ORDER BY usage_count DESC, t.name ASC
 LIMIT @limit_val;
 
@@ -90,6 +92,8 @@ LIMIT @limit_val;
 SELECT t.* FROM tags t
 LEFT JOIN project_tags pt ON t.id = pt.tag_id
 WHERE pt.tag_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM blog_tags bt WHERE bt.tag_id = t.id)
+  AND NOT EXISTS (SELECT 1 FROM media_tags mt WHERE mt.tag_id = t.id)
 ORDER BY t.name ASC;
 
 -- Batch Operations